@@ -14,8 +14,10 @@ import (
 	"github.com/hash-walker/giki-wallet/internal/config_management"
 	"github.com/hash-walker/giki-wallet/internal/feedback"
 	"github.com/hash-walker/giki-wallet/internal/mailer"
+	"github.com/hash-walker/giki-wallet/internal/merchant"
 	"github.com/hash-walker/giki-wallet/internal/payment"
 	"github.com/hash-walker/giki-wallet/internal/payment/gateway"
+	"github.com/hash-walker/giki-wallet/internal/promo"
 	"github.com/hash-walker/giki-wallet/internal/transport"
 	"github.com/hash-walker/giki-wallet/internal/user"
 	"github.com/hash-walker/giki-wallet/internal/wallet"
@@ -43,8 +45,28 @@ func main() {
 		cfg.Jazzcash.WalletPaymentURl,
 		cfg.Jazzcash.CardPaymentURL,
 		cfg.Jazzcash.StatusInquiryURL,
+		cfg.Jazzcash.WalletRefundURL,
+		cfg.Jazzcash.CardRefundURL,
 	)
 
+	// Easypaisa is optional; top-ups can only be routed to it once it is configured
+	gateways := []gateway.Gateway{jazzCashClient}
+	if cfg.Easypaisa.StoreID != "" {
+		gateways = append(gateways, gateway.NewEasypaisaClient(
+			cfg.Easypaisa.StoreID,
+			cfg.Easypaisa.AccountNum,
+			cfg.Easypaisa.Username,
+			cfg.Easypaisa.Password,
+			cfg.Easypaisa.HashKey,
+			cfg.Easypaisa.PostBackURL,
+			cfg.Easypaisa.BaseURL,
+			cfg.Easypaisa.MAInitiateURL,
+			cfg.Easypaisa.InquiryURL,
+			cfg.Easypaisa.CheckoutURL,
+			cfg.Easypaisa.ConfirmURL,
+		))
+	}
+
 	newMailer := mailer.NewGraphSender(
 		cfg.Mailer.ClientID,
 		cfg.Mailer.TenantID,
@@ -72,10 +94,8 @@ func main() {
 
 	inquiryRateLimiter := payment.NewRateLimiter(50)
 
-	// Initialize Worker first
+	// Initialize Worker first; job tickers start once every package has registered its jobs
 	newWorker := worker.NewWorker(pool, newMailer)
-	go newWorker.StartJobTicker(ctx, 10)
-	go newWorker.StartStatusTicker(ctx)
 
 	// Initialize Services with dependencies
 	auditService := audit.NewService(pool)
@@ -98,17 +118,44 @@ func main() {
 	authService := auth.NewService(pool, cfg.Secrets.JWTSecret, newWorker)
 	authHandler := auth.NewHandler(authService, auditService)
 	auditHandler := audit.NewHandler(auditService)
-	walletService := wallet.NewService(pool, cfg.Secrets.LedgerSecret)
-	walletHandler := wallet.NewHandler(walletService)
-	paymentService := payment.NewService(pool, jazzCashClient, walletService, inquiryRateLimiter, configService, cfg.Server.AppURL)
-	paymentHandler := payment.NewHandler(paymentService, walletService)
+	walletService := wallet.NewService(pool, cfg.Secrets.LedgerSecret, cfg.Storage.LedgerArchiveDir, configService, newWorker, loc)
+	walletHandler := wallet.NewHandler(walletService, auditService)
+	paymentService := payment.NewService(pool, gateways, walletService, inquiryRateLimiter, configService, newWorker, loc, cfg.Server.AppURL)
+	paymentHandler := payment.NewHandler(paymentService, walletService, auditService)
 	transportService := transport.NewService(pool, walletService, newWorker, loc)
 	transportHandler := transport.NewHandler(transportService, auditService)
+	promoService := promo.NewService(pool, walletService)
+	promoHandler := promo.NewHandler(promoService, auditService)
+	merchantService := merchant.NewService(pool, walletService, newWorker, cfg.Secrets.PaymentCodeSecret, loc)
+	merchantHandler := merchant.NewHandler(merchantService, auditService)
+
+	if err := walletService.RegisterStatementJobs(ctx); err != nil {
+		log.Printf("Warning: Failed to schedule monthly statements: %v", err)
+	}
+	if err := walletService.RegisterReportJobs(ctx); err != nil {
+		log.Printf("Warning: Failed to schedule trial balance report: %v", err)
+	}
+	if err := walletService.RegisterLedgerPartitionJobs(ctx); err != nil {
+		log.Printf("Warning: Failed to schedule ledger partition maintenance: %v", err)
+	}
+	if err := walletService.RegisterIntegrityJobs(ctx); err != nil {
+		log.Printf("Warning: Failed to schedule ledger integrity check: %v", err)
+	}
+	if err := merchantService.RegisterSettlementJobs(ctx); err != nil {
+		log.Printf("Warning: Failed to schedule merchant settlements: %v", err)
+	}
+	if err := paymentService.RegisterReconciliationJobs(ctx); err != nil {
+		log.Printf("Warning: Failed to schedule payment reconciliation: %v", err)
+	}
+
+	go newWorker.StartJobTicker(ctx, 10)
+	go newWorker.StartStatusTicker(ctx)
+	go paymentService.ListenForStatusUpdates(ctx)
 
 	feedbackService := feedback.NewService(pool)
 	feedbackHandler := feedback.NewHandler(feedbackService)
 
-	srv := api.NewServer(userHandler, authHandler, paymentHandler, transportHandler, walletHandler, newWorker, auditService, auditHandler, configHandler, feedbackHandler)
+	srv := api.NewServer(userHandler, authHandler, paymentHandler, transportHandler, walletHandler, newWorker, auditService, auditHandler, configHandler, feedbackHandler, promoHandler, merchantHandler)
 	srv.MountRoutes()
 
 	c := cors.New(cors.Options{
@@ -132,7 +179,8 @@ func main() {
 		Handler: handler,
 	}
 	// start the worker
-	transport.StartCleanupWorker(pool, 30*time.Second)
+	transport.StartCleanupWorker(pool, walletService, 30*time.Second)
+	wallet.StartBalanceCheckpointWorker(walletService, 6*time.Hour)
 
 	log.Printf("Server starting on port %s\n", port)
 	log.Fatal(server.ListenAndServe())
@@ -10,7 +10,9 @@ import (
 	"github.com/hash-walker/giki-wallet/internal/config_management"
 	"github.com/hash-walker/giki-wallet/internal/middleware"
 	"github.com/hash-walker/giki-wallet/internal/feedback"
+	"github.com/hash-walker/giki-wallet/internal/merchant"
 	"github.com/hash-walker/giki-wallet/internal/payment"
+	"github.com/hash-walker/giki-wallet/internal/promo"
 	"github.com/hash-walker/giki-wallet/internal/transport"
 	"github.com/hash-walker/giki-wallet/internal/user"
 	"github.com/hash-walker/giki-wallet/internal/wallet"
@@ -29,6 +31,8 @@ type Server struct {
 	AuditHandler *audit.Handler
 	Config       *config_management.Handler
 	Feedback     *feedback.Handler
+	Promo        *promo.Handler
+	Merchant     *merchant.Handler
 }
 
 func NewServer(
@@ -42,6 +46,8 @@ func NewServer(
 	auditHandler *audit.Handler,
 	configHandler *config_management.Handler,
 	feedbackHandler *feedback.Handler,
+	promoHandler *promo.Handler,
+	merchantHandler *merchant.Handler,
 ) *Server {
 	return &Server{
 		Router:       chi.NewRouter(),
@@ -55,6 +61,8 @@ func NewServer(
 		AuditHandler: auditHandler,
 		Config:       configHandler,
 		Feedback:     feedbackHandler,
+		Promo:        promoHandler,
+		Merchant:     merchantHandler,
 	}
 }
 
@@ -96,10 +104,18 @@ func (s *Server) MountRoutes() {
 			r.Use(s.Auth.Authenticate)
 			r.Post("/topup", s.Payment.TopUp)
 			r.Get("/status/{txnRefNo}", s.Payment.CheckStatus)
+			r.Get("/status/{txnRefNo}/stream", s.Payment.StreamStatus)
 			r.Get("/limit", s.Config.GetMaxTopUpLimit)
 		})
 		// Make this public so window.location.assign can access it without headers
 		r.Get("/page/{txnRefNo}", s.Payment.CardPaymentPage)
+
+		// Easypaisa redirects the browser here, by GET or POST depending on the step
+		r.Get("/easypaisa/callback", s.Payment.EasypaisaCallBack)
+		r.Post("/easypaisa/callback", s.Payment.EasypaisaCallBack)
+
+		// JazzCash posts MWallet results here server-to-server; the IPN is signed
+		r.Post("/jazzcash/mwallet/ipn", s.Payment.MWalletCallBack)
 	})
 
 	r.Post("/booking/payment/response", s.Payment.CardCallBack)
@@ -126,6 +142,22 @@ func (s *Server) MountRoutes() {
 		r.Use(s.Auth.Authenticate)
 		r.Get("/balance", s.Wallet.GetBalance)
 		r.Get("/history", s.Wallet.GetHistory)
+		r.Get("/statement", s.Wallet.GetStatement)
+		r.Get("/statement/subscription", s.Wallet.GetStatementSubscription)
+		r.Put("/statement/subscription", s.Wallet.UpdateStatementSubscription)
+		r.With(middleware.RateLimit(1, 5)).Post("/transfer", s.Wallet.Transfer)
+		r.With(middleware.RateLimit(1, 5)).Post("/vouchers/redeem", s.Promo.Redeem)
+		r.Post("/payment-code", s.Merchant.IssuePaymentCode)
+		r.Get("/payout", s.Wallet.GetPayout)
+		r.Post("/payout", s.Wallet.RequestPayout)
+		r.Delete("/payout", s.Wallet.CancelPayout)
+	})
+
+	// Merchant API, authenticated with merchant credentials instead of a user session
+	r.Route("/merchant", func(r chi.Router) {
+		r.Use(s.Merchant.Authenticate)
+		r.Post("/payments", s.Merchant.Charge)
+		r.Get("/settlements", s.Merchant.GetSettlement)
 	})
 
 	r.Route("/admin", func(r chi.Router) {
@@ -162,6 +194,12 @@ func (s *Server) MountRoutes() {
 			r.Patch("/{user_id}/status", s.User.HandlerUpdateUserStatus)
 			r.Post("/{user_id}/approve", s.User.HandlerApproveEmployee)
 			r.Post("/{user_id}/reject", s.User.HandlerRejectEmployee)
+
+			r.Get("/{user_id}/wallet", s.Wallet.AdminGetUserWallet)
+			r.With(auth.RequireRole(auth.RoleFinanceAdmin)).Patch("/{user_id}/wallet/status", s.Wallet.AdminUpdateWalletStatus)
+			r.With(auth.RequireRole(auth.RoleFinanceAdmin)).Get("/{user_id}/wallet/spend-limits", s.Wallet.AdminGetSpendLimits)
+			r.With(auth.RequireRole(auth.RoleFinanceAdmin)).Put("/{user_id}/wallet/spend-limits", s.Wallet.AdminSetSpendLimits)
+			r.With(auth.RequireRole(auth.RoleFinanceAdmin)).Delete("/{user_id}/wallet/spend-limits", s.Wallet.AdminClearSpendLimits)
 		})
 
 		r.Route("/transactions/gateway", func(r chi.Router) {
@@ -169,12 +207,87 @@ func (s *Server) MountRoutes() {
 			r.Get("/export", s.Payment.HandleExportGatewayTransactions)
 			r.Get("/{txnRefNo}/logs", s.Payment.GetTransactionAuditLogs)
 			r.Post("/{txnRefNo}/verify", s.Payment.VerifyGatewayTransaction)
+			r.With(auth.RequireRole(auth.RoleFinanceAdmin)).Post("/{txnRefNo}/force-resolve", s.Payment.AdminRequestForceResolution)
 		})
 
 		r.Route("/finance", func(r chi.Router) {
 			r.Get("/liability", s.Payment.GetLiabilityBalance)
 			r.Get("/revenue", s.Payment.GetRevenueBalance)
 			r.Get("/revenue/period", s.Payment.GetRevenuePeriodVolume)
+			r.With(auth.RequireRole(auth.RoleFinanceAdmin)).Get("/integrity", s.Wallet.AdminGetLedgerIntegrity)
+			r.With(auth.RequireRole(auth.RoleFinanceAdmin)).Post("/integrity", s.Wallet.AdminRequestLedgerIntegrityCheck)
+			r.With(auth.RequireRole(auth.RoleFinanceAdmin)).Get("/integrity/runs/{run_id}", s.Wallet.AdminGetLedgerIntegrityRun)
+			r.With(auth.RequireRole(auth.RoleFinanceAdmin)).Get("/trial-balance", s.Wallet.AdminGetTrialBalance)
+			r.With(auth.RequireRole(auth.RoleFinanceAdmin)).Post("/transactions/{transaction_id}/reverse", s.Wallet.AdminReverseTransaction)
+
+			r.Route("/adjustments", func(r chi.Router) {
+				r.Use(auth.RequireRole(auth.RoleFinanceAdmin))
+				r.Get("/", s.Wallet.AdminListAdjustments)
+				r.Post("/", s.Wallet.AdminProposeAdjustment)
+				r.Post("/{adjustment_id}/approve", s.Wallet.AdminApproveAdjustment)
+				r.Post("/{adjustment_id}/reject", s.Wallet.AdminRejectAdjustment)
+			})
+
+			r.Route("/payouts", func(r chi.Router) {
+				r.Use(auth.RequireRole(auth.RoleFinanceAdmin))
+				r.Get("/", s.Wallet.AdminListPayouts)
+				r.Post("/{payout_id}/approve", s.Wallet.AdminApprovePayout)
+				r.Post("/{payout_id}/reject", s.Wallet.AdminRejectPayout)
+				r.Get("/batches", s.Wallet.AdminListPayoutBatches)
+				r.Post("/batches", s.Wallet.AdminCreatePayoutBatch)
+				r.Get("/batches/{batch_id}/export", s.Wallet.AdminExportPayoutBatch)
+				r.Post("/batches/{batch_id}/complete", s.Wallet.AdminCompletePayoutBatch)
+			})
+
+			r.Route("/settlements", func(r chi.Router) {
+				r.Use(auth.RequireRole(auth.RoleFinanceAdmin))
+				r.Get("/", s.Payment.AdminListSettlements)
+				r.Post("/", s.Payment.AdminImportSettlement)
+				r.Get("/{settlement_id}", s.Payment.AdminGetSettlement)
+				r.Post("/items/{item_id}/resolve", s.Payment.AdminResolveSettlementItem)
+			})
+
+			r.Route("/refunds", func(r chi.Router) {
+				r.Use(auth.RequireRole(auth.RoleFinanceAdmin))
+				r.Get("/", s.Payment.AdminListRefunds)
+				r.Post("/", s.Payment.AdminRequestRefund)
+				r.Post("/{refund_id}/approve", s.Payment.AdminApproveRefund)
+				r.Post("/{refund_id}/reject", s.Payment.AdminRejectRefund)
+			})
+
+			r.Route("/force-resolutions", func(r chi.Router) {
+				r.Use(auth.RequireRole(auth.RoleFinanceAdmin))
+				r.Get("/", s.Payment.AdminListForceResolutions)
+				r.Post("/{resolution_id}/approve", s.Payment.AdminApproveForceResolution)
+				r.Post("/{resolution_id}/reject", s.Payment.AdminRejectForceResolution)
+			})
+
+			r.Route("/ledger", func(r chi.Router) {
+				r.Use(auth.RequireRole(auth.RoleFinanceAdmin))
+				r.Get("/partitions", s.Wallet.AdminListLedgerPartitions)
+				r.Post("/partitions/{month}/archive", s.Wallet.AdminArchiveLedgerPartition)
+				r.Post("/archives/{archive_id}/verify", s.Wallet.AdminVerifyLedgerArchive)
+				r.Get("/archives/{archive_id}/download", s.Wallet.AdminDownloadLedgerArchive)
+			})
+		})
+
+		r.Route("/promo/vouchers", func(r chi.Router) {
+			r.Use(auth.RequireRole(auth.RoleFinanceAdmin))
+			r.Get("/", s.Promo.AdminListVouchers)
+			r.Post("/", s.Promo.AdminCreateVoucher)
+			r.Get("/{voucher_id}", s.Promo.AdminGetVoucher)
+			r.Patch("/{voucher_id}", s.Promo.AdminUpdateVoucher)
+			r.Delete("/{voucher_id}", s.Promo.AdminDeleteVoucher)
+		})
+
+		r.Route("/merchants", func(r chi.Router) {
+			r.Use(auth.RequireRole(auth.RoleFinanceAdmin))
+			r.Get("/", s.Merchant.AdminListMerchants)
+			r.Post("/", s.Merchant.AdminCreateMerchant)
+			r.Get("/{merchant_id}", s.Merchant.AdminGetMerchant)
+			r.Patch("/{merchant_id}", s.Merchant.AdminUpdateMerchant)
+			r.Post("/{merchant_id}/credentials", s.Merchant.AdminRotateCredentials)
+			r.Get("/{merchant_id}/settlements", s.Merchant.AdminGetSettlement)
 		})
 		r.Get("/finance/transactions", s.Wallet.GetAdminTransactions)
 		r.Get("/audit-logs", s.AuditHandler.HandlerListSecurityEvents)
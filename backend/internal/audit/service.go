@@ -39,6 +39,37 @@ const (
 	ActionAdminUpdateTrip = "ADMIN_UPDATE_TRIP"
 	ActionAdminDeleteTrip = "ADMIN_DELETE_TRIP"
 	ActionAdminCancelTrip = "ADMIN_CANCEL_TRIP"
+
+	ActionAdminUpdateWalletStatus = "ADMIN_UPDATE_WALLET_STATUS"
+	ActionAdminReverseTransaction = "ADMIN_REVERSE_TRANSACTION"
+	ActionAdminProposeAdjustment  = "ADMIN_PROPOSE_ADJUSTMENT"
+	ActionAdminApproveAdjustment  = "ADMIN_APPROVE_ADJUSTMENT"
+	ActionAdminRejectAdjustment   = "ADMIN_REJECT_ADJUSTMENT"
+	ActionAdminSetSpendLimits     = "ADMIN_SET_SPEND_LIMITS"
+	ActionAdminClearSpendLimits   = "ADMIN_CLEAR_SPEND_LIMITS"
+	ActionAdminArchiveLedger      = "ADMIN_ARCHIVE_LEDGER"
+	ActionAdminVerifyLedger       = "ADMIN_VERIFY_LEDGER_ARCHIVE"
+
+	ActionAdminApprovePayout       = "ADMIN_APPROVE_PAYOUT"
+	ActionAdminRejectPayout        = "ADMIN_REJECT_PAYOUT"
+	ActionAdminCreatePayoutBatch   = "ADMIN_CREATE_PAYOUT_BATCH"
+	ActionAdminCompletePayoutBatch = "ADMIN_COMPLETE_PAYOUT_BATCH"
+
+	ActionAdminCreateVoucher = "ADMIN_CREATE_VOUCHER"
+	ActionAdminUpdateVoucher = "ADMIN_UPDATE_VOUCHER"
+	ActionAdminDeleteVoucher = "ADMIN_DELETE_VOUCHER"
+
+	ActionAdminCreateMerchant    = "ADMIN_CREATE_MERCHANT"
+	ActionAdminUpdateMerchant    = "ADMIN_UPDATE_MERCHANT"
+	ActionAdminRotateMerchantKey = "ADMIN_ROTATE_MERCHANT_KEY"
+
+	ActionAdminRequestForceResolution = "ADMIN_REQUEST_FORCE_RESOLUTION"
+	ActionAdminApproveForceResolution = "ADMIN_APPROVE_FORCE_RESOLUTION"
+	ActionAdminRejectForceResolution  = "ADMIN_REJECT_FORCE_RESOLUTION"
+
+	ActionAdminRequestRefund = "ADMIN_REQUEST_REFUND"
+	ActionAdminApproveRefund = "ADMIN_APPROVE_REFUND"
+	ActionAdminRejectRefund  = "ADMIN_REJECT_REFUND"
 )
 
 // Security Statuses
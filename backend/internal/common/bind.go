@@ -12,8 +12,12 @@ import (
 // ============================================================================
 
 type PaginationParams struct {
-	Page     int `json:"page"`
-	PageSize int `json:"page_size"`
+	Page     int    `json:"page"`
+	PageSize int    `json:"page_size"`
+	Cursor   string `json:"cursor"`
+
+	// After is the decoded Cursor; nil on the first page
+	After *Cursor `json:"-"`
 }
 
 func (p *PaginationParams) Bind(r *http.Request) error {
@@ -36,6 +40,15 @@ func (p *PaginationParams) Bind(r *http.Request) error {
 		}
 	}
 
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		after, err := DecodeCursor(cursor)
+		if err != nil {
+			return err
+		}
+		p.Cursor = cursor
+		p.After = after
+	}
+
 	return nil
 }
 
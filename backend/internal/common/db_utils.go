@@ -29,6 +29,14 @@ func GoogleUUIDtoPgUUID(id uuid.UUID, valid bool) pgtype.UUID {
 	}
 }
 
+func PgUUIDToUUIDPointer(id pgtype.UUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+	u := uuid.UUID(id.Bytes)
+	return &u
+}
+
 func TextToString(text pgtype.Text) string {
 	return text.String
 }
@@ -52,6 +60,13 @@ func TimeToPgTime(goTime time.Time) pgtype.Timestamp {
 	return pgtype.Timestamp{Time: goTime, Valid: !goTime.IsZero()}
 }
 
+func TimestamptzToTimePointer(t pgtype.Timestamptz) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
 func Float64ToNumeric(f float64) pgtype.Numeric {
 	var num pgtype.Numeric
 
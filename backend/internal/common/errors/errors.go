@@ -69,13 +69,19 @@ func (e *AppError) Unwrap() error {
 	return e.Err
 }
 
-// WithDetails adds contextual details to the error
+// WithDetails returns a copy of the error with a detail added. The receiver
+// is left alone, since it is usually a package-level error shared by every
+// request.
 func (e *AppError) WithDetails(key string, value interface{}) *AppError {
-	if e.Details == nil {
-		e.Details = make(map[string]interface{})
+	details := make(map[string]interface{}, len(e.Details)+1)
+	for k, v := range e.Details {
+		details[k] = v
 	}
-	e.Details[key] = value
-	return e
+	details[key] = value
+
+	copied := *e
+	copied.Details = details
+	return &copied
 }
 
 // New creates a new AppError
@@ -0,0 +1,91 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Money is an amount in paisa. It goes over the wire as
+// {"paisa": 12345, "formatted": "Rs 123.45"} so clients never have to do
+// floating point arithmetic or guess which unit a field is in.
+type Money int64
+
+type moneyJSON struct {
+	Paisa     int64  `json:"paisa"`
+	Formatted string `json:"formatted"`
+}
+
+func (m Money) Paisa() int64 {
+	return int64(m)
+}
+
+// Rupees is for display only, e.g. templates; never compute with it
+func (m Money) Rupees() float64 {
+	return float64(m) / 100.0
+}
+
+// Decimal renders the amount in Rupees with two decimals and no grouping,
+// e.g. -1234.50, for CSV exports and other machine-read documents
+func (m Money) Decimal() string {
+	sign := ""
+	paisa := int64(m)
+	if paisa < 0 {
+		sign = "-"
+		paisa = -paisa
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, paisa/100, paisa%100)
+}
+
+// String renders the amount for people, e.g. Rs 1,234.50 or -Rs 20.00
+func (m Money) String() string {
+	sign := ""
+	paisa := int64(m)
+	if paisa < 0 {
+		sign = "-"
+		paisa = -paisa
+	}
+
+	whole := strconv.FormatInt(paisa/100, 10)
+	grouped := make([]byte, 0, len(whole)+len(whole)/3)
+	for i := range len(whole) {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped = append(grouped, ',')
+		}
+		grouped = append(grouped, whole[i])
+	}
+
+	return fmt.Sprintf("%sRs %s.%02d", sign, grouped, paisa%100)
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Paisa: int64(m), Formatted: m.String()})
+}
+
+// UnmarshalJSON accepts the object MarshalJSON writes or a bare integer of paisa
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		var v moneyJSON
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		*m = Money(v.Paisa)
+		return nil
+	}
+
+	var paisa int64
+	if err := json.Unmarshal(data, &paisa); err != nil {
+		return fmt.Errorf("money must be an integer amount of paisa: %w", err)
+	}
+	*m = Money(paisa)
+	return nil
+}
+
+// MoneyPtr is for optional amounts, where a nil pointer serialises as null
+func MoneyPtr(paisa int64) *Money {
+	m := Money(paisa)
+	return &m
+}
@@ -0,0 +1,98 @@
+package common
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMoneyMarshalJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		money Money
+		want  string
+	}{
+		{"zero", 0, `{"paisa":0,"formatted":"Rs 0.00"}`},
+		{"paisa only", 5, `{"paisa":5,"formatted":"Rs 0.05"}`},
+		{"rupees and paisa", 12345, `{"paisa":12345,"formatted":"Rs 123.45"}`},
+		{"grouped thousands", 123456789, `{"paisa":123456789,"formatted":"Rs 1,234,567.89"}`},
+		{"exact thousand", 100000, `{"paisa":100000,"formatted":"Rs 1,000.00"}`},
+		{"negative", -2000, `{"paisa":-2000,"formatted":"-Rs 20.00"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.money)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Marshal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Money
+		wantErr bool
+	}{
+		{"object", `{"paisa":12345,"formatted":"Rs 123.45"}`, 12345, false},
+		{"object ignores formatted", `{"paisa":100,"formatted":"Rs 999.00"}`, 100, false},
+		{"bare paisa", `12345`, 12345, false},
+		{"padded", "  -500 ", -500, false},
+		{"rupee float", `123.45`, 0, true},
+		{"string", `"123"`, 0, true},
+		{"bad object", `{"paisa":"x"}`, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Money
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Unmarshal() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyRoundTrip(t *testing.T) {
+	for _, paisa := range []Money{0, 1, 99, 100, -1, 987654321} {
+		data, err := json.Marshal(paisa)
+		if err != nil {
+			t.Fatalf("Marshal(%d) error = %v", paisa, err)
+		}
+
+		var got Money
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("Unmarshal(%s) error = %v", data, err)
+		}
+		if got != paisa {
+			t.Errorf("round trip of %d gave %d", paisa, got)
+		}
+	}
+}
+
+func TestMoneyDecimal(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{0, "0.00"},
+		{7, "0.07"},
+		{123450, "1234.50"},
+		{-123450, "-1234.50"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.money.Decimal(); got != tt.want {
+			t.Errorf("Money(%d).Decimal() = %q, want %q", tt.money, got, tt.want)
+		}
+	}
+}
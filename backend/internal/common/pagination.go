@@ -0,0 +1,79 @@
+package common
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Cursor is a keyset position on (created_at, id). Lists ordered newest first
+// return the rows strictly after it, so new rows arriving at the head of the
+// list never shift or skip the pages behind it.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// EncodeCursor turns a row position into an opaque token for clients
+func EncodeCursor(createdAt time.Time, id uuid.UUID) string {
+	raw := strconv.FormatInt(createdAt.UnixMicro(), 10) + ":" + id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeCursor(token string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+
+	micros, idStr, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return nil, errInvalidCursor
+	}
+
+	usec, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+
+	return &Cursor{CreatedAt: time.UnixMicro(usec), ID: id}, nil
+}
+
+// CursorArgs returns the nullable keyset arguments for a cursor query.
+// Both are NULL on the first page.
+func (p PaginationParams) CursorArgs() (pgtype.Timestamptz, pgtype.UUID) {
+	if p.After == nil {
+		return pgtype.Timestamptz{}, pgtype.UUID{}
+	}
+	return pgtype.Timestamptz{Time: p.After.CreatedAt, Valid: true}, GoogleUUIDtoPgUUID(p.After.ID, true)
+}
+
+// FetchLimit is one more than the page size, so a full extra row tells the
+// caller another page exists without counting the whole result set.
+func (p PaginationParams) FetchLimit() int32 {
+	return int32(p.PageSize + 1)
+}
+
+// TrimPage cuts rows fetched with FetchLimit back to the page size and returns
+// the cursor of the last row kept, or "" when this is the last page.
+func TrimPage[T any](rows []T, pageSize int, position func(T) (time.Time, uuid.UUID)) ([]T, string) {
+	if len(rows) <= pageSize {
+		return rows, ""
+	}
+
+	rows = rows[:pageSize]
+	createdAt, id := position(rows[len(rows)-1])
+	return rows, EncodeCursor(createdAt, id)
+}
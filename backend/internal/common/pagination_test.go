@@ -0,0 +1,134 @@
+package common
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	id := uuid.MustParse("6f1c2a4e-8d1b-4c7e-9a3f-2b5d7e9c1a00")
+	pkt := time.FixedZone("PKT", 5*60*60)
+
+	tests := []struct {
+		name      string
+		createdAt time.Time
+	}{
+		{"utc", time.Date(2026, 3, 14, 9, 26, 53, 589793000, time.UTC)},
+		{"other zone", time.Date(2026, 3, 14, 14, 26, 53, 0, pkt)},
+		{"before epoch", time.Date(1969, 12, 31, 23, 59, 59, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := EncodeCursor(tt.createdAt, id)
+
+			got, err := DecodeCursor(token)
+			if err != nil {
+				t.Fatalf("DecodeCursor(%q) error = %v", token, err)
+			}
+			if !got.CreatedAt.Equal(tt.createdAt) {
+				t.Errorf("CreatedAt = %v, want %v", got.CreatedAt, tt.createdAt)
+			}
+			if got.ID != id {
+				t.Errorf("ID = %v, want %v", got.ID, id)
+			}
+		})
+	}
+}
+
+func TestCursorKeepsMicroseconds(t *testing.T) {
+	// Postgres stores microseconds, so anything finer is dropped on both sides
+	createdAt := time.Date(2026, 1, 2, 3, 4, 5, 123456789, time.UTC)
+
+	got, err := DecodeCursor(EncodeCursor(createdAt, uuid.New()))
+	if err != nil {
+		t.Fatalf("DecodeCursor() error = %v", err)
+	}
+
+	want := createdAt.Truncate(time.Microsecond)
+	if !got.CreatedAt.Equal(want) {
+		t.Errorf("CreatedAt = %v, want %v", got.CreatedAt, want)
+	}
+}
+
+func TestDecodeCursorRejectsBadTokens(t *testing.T) {
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"not base64", "!!!"},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte("1:" + uuid.NewString()))},
+		{"no separator", encode("1700000000000000")},
+		{"bad timestamp", encode("yesterday:" + uuid.NewString())},
+		{"bad id", encode("1700000000000000:not-a-uuid")},
+		{"empty", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeCursor(tt.token); !errors.Is(err, errInvalidCursor) {
+				t.Errorf("DecodeCursor(%q) error = %v, want errInvalidCursor", tt.token, err)
+			}
+		})
+	}
+}
+
+func TestTrimPage(t *testing.T) {
+	type row struct {
+		createdAt time.Time
+		id        uuid.UUID
+	}
+
+	base := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	rows := make([]row, 5)
+	for i := range rows {
+		rows[i] = row{createdAt: base.Add(-time.Duration(i) * time.Minute), id: uuid.New()}
+	}
+	position := func(r row) (time.Time, uuid.UUID) { return r.createdAt, r.id }
+
+	tests := []struct {
+		name       string
+		rows       []row
+		pageSize   int
+		wantLen    int
+		wantCursor bool
+	}{
+		{"fewer than a page", rows[:3], 4, 3, false},
+		{"exactly a page", rows[:4], 4, 4, false},
+		{"one extra row", rows, 4, 4, true},
+		{"empty", nil, 4, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, cursor := TrimPage(tt.rows, tt.pageSize, position)
+			if len(got) != tt.wantLen {
+				t.Fatalf("len = %d, want %d", len(got), tt.wantLen)
+			}
+			if (cursor != "") != tt.wantCursor {
+				t.Fatalf("cursor = %q, want one: %v", cursor, tt.wantCursor)
+			}
+			if !tt.wantCursor {
+				return
+			}
+
+			// the cursor points at the last row kept, not the extra one
+			after, err := DecodeCursor(cursor)
+			if err != nil {
+				t.Fatalf("DecodeCursor() error = %v", err)
+			}
+			last := got[len(got)-1]
+			if !after.CreatedAt.Equal(last.createdAt) || after.ID != last.id {
+				t.Errorf("cursor = %v/%v, want %v/%v", after.CreatedAt, after.ID, last.createdAt, last.id)
+			}
+		})
+	}
+}
@@ -7,11 +7,13 @@ import (
 )
 
 type Config struct {
-	Database DatabaseConfig
-	Server   ServerConfig
-	Jazzcash JazzcashConfig
-	Mailer   GraphSenderConfig
-	Secrets  SecretsConfig
+	Database  DatabaseConfig
+	Server    ServerConfig
+	Jazzcash  JazzcashConfig
+	Easypaisa EasypaisaConfig
+	Mailer    GraphSenderConfig
+	Secrets   SecretsConfig
+	Storage   StorageConfig
 }
 
 type DatabaseConfig struct {
@@ -39,6 +41,23 @@ type JazzcashConfig struct {
 	WalletPaymentURl string
 	CardPaymentURL   string
 	StatusInquiryURL string
+	WalletRefundURL  string
+	CardRefundURL    string
+}
+
+// EasypaisaConfig is optional; the gateway is only registered when a store ID is set
+type EasypaisaConfig struct {
+	StoreID       string
+	AccountNum    string
+	Username      string
+	Password      string
+	HashKey       string
+	PostBackURL   string
+	BaseURL       string
+	MAInitiateURL string
+	InquiryURL    string
+	CheckoutURL   string
+	ConfirmURL    string
 }
 
 type GraphSenderConfig struct {
@@ -49,8 +68,13 @@ type GraphSenderConfig struct {
 }
 
 type SecretsConfig struct {
-	JWTSecret    string
-	LedgerSecret string
+	JWTSecret         string
+	LedgerSecret      string
+	PaymentCodeSecret string
+}
+
+type StorageConfig struct {
+	LedgerArchiveDir string
 }
 
 func LoadConfig() *Config {
@@ -73,6 +97,21 @@ func LoadConfig() *Config {
 			WalletPaymentURl: getRequiredEnv("JAZZCASH_WALLET_PAYMENT_URL"),
 			CardPaymentURL:   getRequiredEnv("JAZZCASH_CARD_PAYMENT_URL"),
 			StatusInquiryURL: getRequiredEnv("JAZZCASH_STATUS_INQUIRY_URL"),
+			WalletRefundURL:  getEnvWithDefault("JAZZCASH_WALLET_REFUND_URL", "ApplicationAPI/API/Purchase/domwalletrefundtransaction"),
+			CardRefundURL:    getEnvWithDefault("JAZZCASH_CARD_REFUND_URL", "ApplicationAPI/API/authorize/Refund"),
+		},
+		Easypaisa: EasypaisaConfig{
+			StoreID:       getEnvWithDefault("EASYPAISA_STORE_ID", ""),
+			AccountNum:    getEnvWithDefault("EASYPAISA_ACCOUNT_NUM", ""),
+			Username:      getEnvWithDefault("EASYPAISA_USERNAME", ""),
+			Password:      getEnvWithDefault("EASYPAISA_PASSWORD", ""),
+			HashKey:       getEnvWithDefault("EASYPAISA_HASH_KEY", ""),
+			PostBackURL:   getEnvWithDefault("EASYPAISA_POSTBACK_URL", ""),
+			BaseURL:       getEnvWithDefault("EASYPAISA_BASE_URL", "https://easypay.easypaisa.com.pk"),
+			MAInitiateURL: getEnvWithDefault("EASYPAISA_MA_INITIATE_URL", "/easypay-service/rest/v4/initiate-ma-transaction"),
+			InquiryURL:    getEnvWithDefault("EASYPAISA_INQUIRY_URL", "/easypay-service/rest/v4/inquire-transaction"),
+			CheckoutURL:   getEnvWithDefault("EASYPAISA_CHECKOUT_URL", "/easypay/Index.jsf"),
+			ConfirmURL:    getEnvWithDefault("EASYPAISA_CONFIRM_URL", "/easypay/Confirm.jsf"),
 		},
 
 		Mailer: GraphSenderConfig{
@@ -82,8 +121,12 @@ func LoadConfig() *Config {
 			SenderEmail:  getRequiredEnv("MS_GRAPH_SENDER_EMAIL"),
 		},
 		Secrets: SecretsConfig{
-			JWTSecret:    getEnvWithDefault("TOKEN_SECRET", "super-secret-dev-token"),
-			LedgerSecret: getEnvWithDefault("LEDGER_HASH_SECRET", "super-secret-dev-ledger"),
+			JWTSecret:         getEnvWithDefault("TOKEN_SECRET", "super-secret-dev-token"),
+			LedgerSecret:      getEnvWithDefault("LEDGER_HASH_SECRET", "super-secret-dev-ledger"),
+			PaymentCodeSecret: getEnvWithDefault("PAYMENT_CODE_SECRET", "super-secret-dev-payment-code"),
+		},
+		Storage: StorageConfig{
+			LedgerArchiveDir: getEnvWithDefault("LEDGER_ARCHIVE_DIR", "./data/ledger-archives"),
 		},
 	}
 
@@ -3,6 +3,7 @@ package config_management
 import (
 	"context"
 	"strconv"
+	"strings"
 
 	config "github.com/hash-walker/giki-wallet/internal/config_management/config_db"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -11,8 +12,49 @@ import (
 
 const (
 	MaxTopUpAmountPaisaKey = "MAX_TOPUP_AMOUNT_PAISA"
+	P2PDailyLimitPaisaKey  = "P2P_DAILY_LIMIT_PAISA"
+	P2PDailyCountKey       = "P2P_DAILY_TRANSFER_COUNT"
+
+	// Spend limits are configured per user type by suffixing the type,
+	// e.g. SPEND_DAILY_LIMIT_PAISA_STUDENT. Zero or a missing key means no cap.
+	SpendPerTransactionLimitKeyPrefix = "SPEND_PER_TXN_LIMIT_PAISA_"
+	SpendDailyLimitKeyPrefix          = "SPEND_DAILY_LIMIT_PAISA_"
+	SpendWeeklyLimitKeyPrefix         = "SPEND_WEEKLY_LIMIT_PAISA_"
+
+	// The gateway new top-ups go through is picked per payment method,
+	// e.g. TOPUP_GATEWAY_MWALLET, so one provider's outage can be routed around.
+	TopUpGatewayKeyPrefix = "TOPUP_GATEWAY_"
+
+	defaultTopUpGateway = "JAZZCASH"
 )
 
+// SpendLimits caps wallet debits for a user type, in paisa; zero means no cap
+type SpendLimits struct {
+	PerTransaction int64
+	Daily          int64
+	Weekly         int64
+}
+
+type configDefault struct {
+	key         string
+	value       string
+	description string
+}
+
+// defaultConfigs are seeded once; admin changes to them survive restarts
+var defaultConfigs = []configDefault{
+	{P2PDailyLimitPaisaKey, "500000", "Maximum amount a user can transfer to other users in 24 hours (in Paisas)"},
+	{P2PDailyCountKey, "10", "Maximum number of transfers a user can send in 24 hours"},
+	{SpendPerTransactionLimitKeyPrefix + "STUDENT", "500000", "Largest single payment a student wallet can make (in Paisas, 0 for no cap)"},
+	{SpendDailyLimitKeyPrefix + "STUDENT", "1000000", "Maximum a student wallet can spend in 24 hours (in Paisas, 0 for no cap)"},
+	{SpendWeeklyLimitKeyPrefix + "STUDENT", "3000000", "Maximum a student wallet can spend in 7 days (in Paisas, 0 for no cap)"},
+	{SpendPerTransactionLimitKeyPrefix + "EMPLOYEE", "1000000", "Largest single payment an employee wallet can make (in Paisas, 0 for no cap)"},
+	{SpendDailyLimitKeyPrefix + "EMPLOYEE", "2000000", "Maximum an employee wallet can spend in 24 hours (in Paisas, 0 for no cap)"},
+	{SpendWeeklyLimitKeyPrefix + "EMPLOYEE", "6000000", "Maximum an employee wallet can spend in 7 days (in Paisas, 0 for no cap)"},
+	{TopUpGatewayKeyPrefix + "MWALLET", defaultTopUpGateway, "Gateway for mobile wallet top-ups (JAZZCASH or EASYPAISA)"},
+	{TopUpGatewayKeyPrefix + "CARD", defaultTopUpGateway, "Gateway for card top-ups (JAZZCASH or EASYPAISA)"},
+}
+
 type Service struct {
 	q *config.Queries
 }
@@ -34,7 +76,21 @@ func (s *Service) Initialize(ctx context.Context) error {
 			Valid:  true,
 		},
 	})
-	return err
+	if err != nil {
+		return err
+	}
+
+	for _, d := range defaultConfigs {
+		if err := s.q.InsertConfigIfMissing(ctx, config.InsertConfigIfMissingParams{
+			Key:         d.key,
+			Value:       d.value,
+			Description: pgtype.Text{String: d.description, Valid: true},
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func (s *Service) GetMaxTopUpAmount(ctx context.Context) (int64, error) {
@@ -51,6 +107,61 @@ func (s *Service) GetMaxTopUpAmount(ctx context.Context) (int64, error) {
 	return val, nil
 }
 
+func (s *Service) GetP2PDailyLimit(ctx context.Context) int64 {
+	return s.getInt64(ctx, P2PDailyLimitPaisaKey, 500000)
+}
+
+func (s *Service) GetP2PDailyCount(ctx context.Context) int64 {
+	return s.getInt64(ctx, P2PDailyCountKey, 10)
+}
+
+// GetSpendLimits returns the configured spend caps for a user type
+func (s *Service) GetSpendLimits(ctx context.Context, userType string) SpendLimits {
+	userType = strings.ToUpper(userType)
+
+	return SpendLimits{
+		PerTransaction: s.getInt64(ctx, SpendPerTransactionLimitKeyPrefix+userType, 0),
+		Daily:          s.getInt64(ctx, SpendDailyLimitKeyPrefix+userType, 0),
+		Weekly:         s.getInt64(ctx, SpendWeeklyLimitKeyPrefix+userType, 0),
+	}
+}
+
+// GetTopUpGateway returns the gateway new top-ups with the given payment
+// method should go through
+func (s *Service) GetTopUpGateway(ctx context.Context, method string) string {
+	return strings.ToUpper(s.getString(ctx, TopUpGatewayKeyPrefix+strings.ToUpper(method), defaultTopUpGateway))
+}
+
+// getString reads a text config, falling back when it is missing or blank
+func (s *Service) getString(ctx context.Context, key string, fallback string) string {
+	cfg, err := s.q.GetConfig(ctx, key)
+	if err != nil {
+		return fallback
+	}
+
+	val := strings.TrimSpace(cfg.Value)
+	if val == "" {
+		return fallback
+	}
+
+	return val
+}
+
+// getInt64 reads a numeric config, falling back when it is missing or malformed
+func (s *Service) getInt64(ctx context.Context, key string, fallback int64) int64 {
+	cfg, err := s.q.GetConfig(ctx, key)
+	if err != nil {
+		return fallback
+	}
+
+	val, err := strconv.ParseInt(cfg.Value, 10, 64)
+	if err != nil {
+		return fallback
+	}
+
+	return val
+}
+
 func (s *Service) ListConfigs(ctx context.Context) ([]config.GikiWalletSystemConfig, error) {
 	return s.q.ListConfigs(ctx)
 }
@@ -18,3 +18,8 @@ VALUES ($1, $2, $3)
 ON CONFLICT (key) DO UPDATE
 SET value = $2, updated_at = NOW()
 RETURNING *;
+
+-- name: InsertConfigIfMissing :exec
+INSERT INTO giki_wallet.system_configs (key, value, description)
+VALUES ($1, $2, $3)
+ON CONFLICT (key) DO NOTHING;
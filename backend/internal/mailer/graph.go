@@ -34,6 +34,11 @@ func NewGraphSender(clientID, tenantID, clientSecret, senderEmail string) *Graph
 }
 
 func (g *GraphSender) SendTemplate(to, subject, templateName string, data interface{}) error {
+	return g.SendTemplateWithAttachments(to, subject, templateName, data, nil)
+}
+
+// SendTemplateWithAttachments renders a template like SendTemplate and attaches the given files
+func (g *GraphSender) SendTemplateWithAttachments(to, subject, templateName string, data interface{}, attachments []Attachment) error {
 	log.Printf("[MAILER] Parsing templates for %s", templateName)
 
 	tmpl := template.New("mail")
@@ -56,11 +61,15 @@ func (g *GraphSender) SendTemplate(to, subject, templateName string, data interf
 		return fmt.Errorf("failed to render template %s: %w", templateName, err)
 	}
 
-	// Call the low-level Send method
-	return g.Send(to, subject, body.String())
+	// Call the low-level send method
+	return g.send(to, subject, body.String(), attachments)
 }
 
 func (g *GraphSender) Send(to, subject, htmlBody string) error {
+	return g.send(to, subject, htmlBody, nil)
+}
+
+func (g *GraphSender) send(to, subject, htmlBody string, attachments []Attachment) error {
 	// get Access Token
 	token, err := g.getAccessToken()
 
@@ -87,6 +96,15 @@ func (g *GraphSender) Send(to, subject, htmlBody string) error {
 		},
 	}
 
+	for _, a := range attachments {
+		payload.Message.Attachments = append(payload.Message.Attachments, graphAttachment{
+			ODataType:    "#microsoft.graph.fileAttachment",
+			Name:         a.Name,
+			ContentType:  a.ContentType,
+			ContentBytes: a.Content,
+		})
+	}
+
 	jsonBytes, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal payload: %w", err)
@@ -1,5 +1,12 @@
 package mailer
 
+// Attachment is a file sent along with an email
+type Attachment struct {
+	Name        string
+	ContentType string
+	Content     []byte
+}
+
 // ---------------------------------------------------------
 // Internal Structs for JSON Marshaling
 // ---------------------------------------------------------
@@ -10,9 +17,10 @@ type graphEmailPayload struct {
 }
 
 type graphMessage struct {
-	Subject      string           `json:"subject"`
-	Body         graphBody        `json:"body"`
-	ToRecipients []graphRecipient `json:"toRecipients"`
+	Subject      string            `json:"subject"`
+	Body         graphBody         `json:"body"`
+	ToRecipients []graphRecipient  `json:"toRecipients"`
+	Attachments  []graphAttachment `json:"attachments,omitempty"`
 }
 
 type graphBody struct {
@@ -27,3 +35,12 @@ type graphRecipient struct {
 type graphEmailAddress struct {
 	Address string `json:"address"`
 }
+
+// graphAttachment is a Graph fileAttachment. ContentBytes is base64 encoded
+// by encoding/json, which is the encoding Graph expects.
+type graphAttachment struct {
+	ODataType    string `json:"@odata.type"`
+	Name         string `json:"name"`
+	ContentType  string `json:"contentType"`
+	ContentBytes []byte `json:"contentBytes"`
+}
@@ -0,0 +1,118 @@
+package merchant
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const testCodeSecret = "test-payment-code-secret"
+
+func TestPaymentCodeRoundTrip(t *testing.T) {
+	codeID := uuid.New()
+	userID := uuid.New()
+	expiresAt := time.Date(2026, 10, 17, 9, 30, 15, 0, time.UTC)
+
+	code := signPaymentCode(testCodeSecret, codeID, userID, expiresAt)
+	if !strings.HasPrefix(code, paymentCodePrefix+".") {
+		t.Fatalf("code %q does not start with %s.", code, paymentCodePrefix)
+	}
+
+	gotCode, gotUser, gotExpiry, err := parsePaymentCode(testCodeSecret, "  "+code+"\n")
+	if err != nil {
+		t.Fatalf("parsePaymentCode() error = %v", err)
+	}
+	if gotCode != codeID || gotUser != userID || !gotExpiry.Equal(expiresAt) {
+		t.Errorf("parsePaymentCode() = %v, %v, %v, want %v, %v, %v", gotCode, gotUser, gotExpiry, codeID, userID, expiresAt)
+	}
+}
+
+func TestPaymentCodeIsDeterministic(t *testing.T) {
+	codeID, userID := uuid.New(), uuid.New()
+	expiresAt := time.Unix(1800000000, 0)
+
+	a := signPaymentCode(testCodeSecret, codeID, userID, expiresAt)
+	b := signPaymentCode(testCodeSecret, codeID, userID, expiresAt)
+	if a != b {
+		t.Errorf("signing the same code twice gave %q and %q", a, b)
+	}
+
+	if c := signPaymentCode("another-secret", codeID, userID, expiresAt); c == a {
+		t.Error("codes signed with different secrets are identical")
+	}
+}
+
+func TestParsePaymentCodeRejects(t *testing.T) {
+	enc := base64.RawURLEncoding
+	code := signPaymentCode(testCodeSecret, uuid.New(), uuid.New(), time.Now().Add(paymentCodeTTL))
+	parts := strings.Split(code, ".")
+
+	// another user's ID under the original signature
+	payload, _ := enc.DecodeString(parts[1])
+	forged := append([]byte(nil), payload...)
+	otherUser := uuid.New()
+	copy(forged[16:32], otherUser[:])
+
+	// a later expiry under the original signature
+	extended := append([]byte(nil), payload...)
+	extended[39]++
+
+	sig, _ := enc.DecodeString(parts[2])
+	flipped := append([]byte(nil), sig...)
+	flipped[0] ^= 0xff
+
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+	}{
+		{"wrong secret", "another-secret", code},
+		{"forged user", testCodeSecret, parts[0] + "." + enc.EncodeToString(forged) + "." + parts[2]},
+		{"extended expiry", testCodeSecret, parts[0] + "." + enc.EncodeToString(extended) + "." + parts[2]},
+		{"flipped signature", testCodeSecret, parts[0] + "." + parts[1] + "." + enc.EncodeToString(flipped)},
+		{"truncated signature", testCodeSecret, parts[0] + "." + parts[1] + "." + enc.EncodeToString(sig[:8])},
+		{"wrong prefix", testCodeSecret, "GBX2." + parts[1] + "." + parts[2]},
+		{"missing signature", testCodeSecret, parts[0] + "." + parts[1]},
+		{"extra part", testCodeSecret, code + ".x"},
+		{"short payload", testCodeSecret, parts[0] + "." + enc.EncodeToString(payload[:32]) + "." + parts[2]},
+		{"payload not base64", testCodeSecret, parts[0] + ".!!!." + parts[2]},
+		{"empty", testCodeSecret, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, _, err := parsePaymentCode(tt.secret, tt.code); !errors.Is(err, ErrInvalidPaymentCode) {
+				t.Errorf("parsePaymentCode() error = %v, want ErrInvalidPaymentCode", err)
+			}
+		})
+	}
+}
+
+func TestSecretMatches(t *testing.T) {
+	_, secret, err := newCredentials()
+	if err != nil {
+		t.Fatalf("newCredentials() error = %v", err)
+	}
+	stored := hashSecret(secret)
+
+	tests := []struct {
+		name   string
+		secret string
+		want   bool
+	}{
+		{"same secret", secret, true},
+		{"different secret", secret + "x", false},
+		{"empty", "", false},
+		{"the stored hash itself", stored, false},
+	}
+
+	for _, tt := range tests {
+		if got := secretMatches(tt.secret, stored); got != tt.want {
+			t.Errorf("%s: secretMatches() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package merchant
+
+import (
+	"net/http"
+
+	"github.com/hash-walker/giki-wallet/internal/common/errors"
+)
+
+var (
+	// Merchant Admin Errors
+	ErrInvalidMerchant   = errors.New("INVALID_MERCHANT", http.StatusBadRequest, "Merchant request is invalid")
+	ErrMerchantNotFound  = errors.New("MERCHANT_NOT_FOUND", http.StatusNotFound, "Merchant not found")
+	ErrDuplicateMerchant = errors.New("DUPLICATE_MERCHANT", http.StatusConflict, "A merchant with this name already exists")
+
+	// Merchant API Errors
+	ErrInvalidCredentials = errors.New("INVALID_MERCHANT_CREDENTIALS", http.StatusUnauthorized, "Merchant API credentials are invalid")
+	ErrMerchantInactive   = errors.New("MERCHANT_INACTIVE", http.StatusForbidden, "Merchant account is disabled")
+	ErrInvalidPayment     = errors.New("INVALID_MERCHANT_PAYMENT", http.StatusBadRequest, "Payment request is invalid")
+	ErrDuplicateReference = errors.New("DUPLICATE_MERCHANT_REFERENCE", http.StatusConflict, "This reference was already used for a different payment")
+
+	// Payment Code Errors
+	ErrInvalidPaymentCode = errors.New("INVALID_PAYMENT_CODE", http.StatusBadRequest, "Payment code is not valid")
+	ErrPaymentCodeExpired = errors.New("PAYMENT_CODE_EXPIRED", http.StatusGone, "Payment code has expired; ask the customer to show a new one")
+	ErrPaymentCodeUsed    = errors.New("PAYMENT_CODE_USED", http.StatusConflict, "Payment code has already been used")
+
+	// Settlement Errors
+	ErrInvalidSettlementDate = errors.New("INVALID_SETTLEMENT_DATE", http.StatusBadRequest, "Settlement date must be YYYY-MM-DD and not in the future")
+
+	// Database Errors
+	ErrDatabase = errors.New("MERCHANT_DATABASE_ERROR", http.StatusInternalServerError, "Merchant database operation failed")
+)
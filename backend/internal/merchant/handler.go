@@ -0,0 +1,309 @@
+package merchant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/hash-walker/giki-wallet/internal/audit"
+	"github.com/hash-walker/giki-wallet/internal/auth"
+	"github.com/hash-walker/giki-wallet/internal/common"
+	commonerrors "github.com/hash-walker/giki-wallet/internal/common/errors"
+	merchantdb "github.com/hash-walker/giki-wallet/internal/merchant/merchant_db"
+	"github.com/hash-walker/giki-wallet/internal/middleware"
+)
+
+type contextKey string
+
+const merchantKey contextKey = "merchant"
+
+type Handler struct {
+	service *Service
+	audit   *audit.Service
+}
+
+func NewHandler(service *Service, audit *audit.Service) *Handler {
+	return &Handler{
+		service: service,
+		audit:   audit,
+	}
+}
+
+// Authenticate is a middleware that resolves the merchant from the
+// X-Merchant-Key and X-Merchant-Secret headers
+func (h *Handler) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := middleware.GetRequestID(r.Context())
+
+		m, err := h.service.Authenticate(r.Context(), r.Header.Get("X-Merchant-Key"), r.Header.Get("X-Merchant-Secret"))
+		if err != nil {
+			middleware.HandleError(w, err, requestID)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), merchantKey, m)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func getMerchantFromContext(ctx context.Context) (*merchantdb.GikiWalletMerchant, bool) {
+	m, ok := ctx.Value(merchantKey).(*merchantdb.GikiWalletMerchant)
+	return m, ok
+}
+
+// =============================================================================
+// STUDENT HANDLERS
+// =============================================================================
+
+func (h *Handler) IssuePaymentCode(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		middleware.HandleError(w, commonerrors.ErrUnauthorized, requestID)
+		return
+	}
+
+	res, err := h.service.IssuePaymentCode(r.Context(), userID)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	common.ResponseWithJSON(w, http.StatusCreated, res, requestID)
+}
+
+// =============================================================================
+// MERCHANT API HANDLERS
+// =============================================================================
+
+func (h *Handler) Charge(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	m, ok := getMerchantFromContext(r.Context())
+	if !ok {
+		middleware.HandleError(w, ErrInvalidCredentials, requestID)
+		return
+	}
+
+	var params ChargeRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidJSON, err), requestID)
+		return
+	}
+
+	res, err := h.service.Charge(r.Context(), m, params)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	common.ResponseWithJSON(w, http.StatusOK, res, requestID)
+}
+
+func (h *Handler) GetSettlement(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	m, ok := getMerchantFromContext(r.Context())
+	if !ok {
+		middleware.HandleError(w, ErrInvalidCredentials, requestID)
+		return
+	}
+
+	h.writeSettlement(w, r, m.ID, requestID)
+}
+
+// =============================================================================
+// ADMIN HANDLERS
+// =============================================================================
+
+func (h *Handler) AdminListMerchants(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	var params common.PaginationParams
+	if err := params.Bind(r); err != nil {
+		appErr := commonerrors.New("INVALID_REQUEST", http.StatusBadRequest, err.Error())
+		middleware.HandleError(w, appErr, requestID)
+		return
+	}
+
+	merchants, total, err := h.service.ListMerchants(r.Context(), params.Page, params.PageSize)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	response := map[string]interface{}{
+		"data": merchants,
+		"meta": map[string]interface{}{
+			"page":          params.Page,
+			"page_size":     params.PageSize,
+			"total_records": total,
+		},
+	}
+
+	common.ResponseWithJSON(w, http.StatusOK, response, requestID)
+}
+
+func (h *Handler) AdminCreateMerchant(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	actorID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		middleware.HandleError(w, commonerrors.ErrUnauthorized, requestID)
+		return
+	}
+
+	var params CreateMerchantRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidJSON, err), requestID)
+		return
+	}
+
+	res, err := h.service.CreateMerchant(r.Context(), actorID, params)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	h.logAdminAction(r.Context(), r, audit.ActionAdminCreateMerchant, &res.ID, map[string]interface{}{
+		"name":       res.Name,
+		"category":   res.Category,
+		"wallet_id":  res.WalletID,
+		"api_key_id": res.APIKeyID,
+	})
+
+	common.ResponseWithJSON(w, http.StatusCreated, res, requestID)
+}
+
+func (h *Handler) AdminGetMerchant(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	merchantID, err := uuid.Parse(chi.URLParam(r, "merchant_id"))
+	if err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidUUID, err), requestID)
+		return
+	}
+
+	res, err := h.service.GetMerchant(r.Context(), merchantID)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	common.ResponseWithJSON(w, http.StatusOK, res, requestID)
+}
+
+func (h *Handler) AdminUpdateMerchant(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	merchantID, err := uuid.Parse(chi.URLParam(r, "merchant_id"))
+	if err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidUUID, err), requestID)
+		return
+	}
+
+	var params UpdateMerchantRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidJSON, err), requestID)
+		return
+	}
+
+	res, err := h.service.UpdateMerchant(r.Context(), merchantID, params)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	h.logAdminAction(r.Context(), r, audit.ActionAdminUpdateMerchant, &merchantID, map[string]interface{}{
+		"category":         res.Category,
+		"settlement_email": res.SettlementEmail,
+		"is_active":        res.IsActive,
+	})
+
+	common.ResponseWithJSON(w, http.StatusOK, res, requestID)
+}
+
+func (h *Handler) AdminRotateCredentials(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	merchantID, err := uuid.Parse(chi.URLParam(r, "merchant_id"))
+	if err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidUUID, err), requestID)
+		return
+	}
+
+	res, err := h.service.RotateCredentials(r.Context(), merchantID)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	h.logAdminAction(r.Context(), r, audit.ActionAdminRotateMerchantKey, &merchantID, map[string]interface{}{
+		"api_key_id": res.APIKeyID,
+	})
+
+	common.ResponseWithJSON(w, http.StatusOK, res, requestID)
+}
+
+func (h *Handler) AdminGetSettlement(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	merchantID, err := uuid.Parse(chi.URLParam(r, "merchant_id"))
+	if err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidUUID, err), requestID)
+		return
+	}
+
+	h.writeSettlement(w, r, merchantID, requestID)
+}
+
+// writeSettlement serves a settlement report for ?date=YYYY-MM-DD (yesterday by
+// default) as JSON, or as a CSV download with ?format=csv.
+func (h *Handler) writeSettlement(w http.ResponseWriter, r *http.Request, merchantID uuid.UUID, requestID string) {
+	date := r.URL.Query().Get("date")
+	if date == "" {
+		date = time.Now().In(h.service.loc).AddDate(0, 0, -1).Format("2006-01-02")
+	}
+
+	report, err := h.service.GetSettlementReport(r.Context(), merchantID, date)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	if r.URL.Query().Get("format") != "csv" {
+		common.ResponseWithJSON(w, http.StatusOK, report, requestID)
+		return
+	}
+
+	document, err := RenderSettlementCSV(report)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", SettlementFileName(report)))
+	w.Write(document)
+}
+
+func (h *Handler) logAdminAction(ctx context.Context, r *http.Request, action string, targetID *uuid.UUID, details map[string]interface{}) {
+	ip := common.GetClientIP(r)
+	userAgent := r.UserAgent()
+	actorID, _ := auth.GetUserIDFromContext(ctx)
+
+	_ = h.audit.LogSecurityEvent(ctx, audit.Event{
+		ActorID:   &actorID,
+		Action:    action,
+		TargetID:  targetID,
+		IPAddress: ip,
+		UserAgent: userAgent,
+		Status:    audit.StatusSuccess,
+		Details:   details,
+	})
+}
@@ -0,0 +1,105 @@
+package merchant
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hash-walker/giki-wallet/internal/common"
+	merchantdb "github.com/hash-walker/giki-wallet/internal/merchant/merchant_db"
+)
+
+// TransactionTypeMerchantPayment is the ledger type of a payment at a campus merchant
+const TransactionTypeMerchantPayment = "MERCHANT_PAYMENT"
+
+type CreateMerchantRequest struct {
+	Name            string `json:"name"`
+	Category        string `json:"category"`
+	SettlementEmail string `json:"settlement_email"`
+}
+
+type UpdateMerchantRequest struct {
+	Category        string `json:"category"`
+	SettlementEmail string `json:"settlement_email"`
+	IsActive        bool   `json:"is_active"`
+}
+
+type Merchant struct {
+	ID                   uuid.UUID    `json:"id"`
+	Name                 string       `json:"name"`
+	Category             string       `json:"category"`
+	WalletID             uuid.UUID    `json:"wallet_id"`
+	APIKeyID             string       `json:"api_key_id"`
+	SettlementEmail      string       `json:"settlement_email"`
+	IsActive             bool         `json:"is_active"`
+	Balance              common.Money `json:"balance"`
+	CredentialsRotatedAt time.Time    `json:"credentials_rotated_at"`
+	CreatedAt            time.Time    `json:"created_at"`
+}
+
+// MerchantCredentials is returned once, when a merchant is created or its
+// credentials are rotated. Only a hash of the secret is kept.
+type MerchantCredentials struct {
+	Merchant
+	APISecret string `json:"api_secret"`
+}
+
+// PaymentCode is what the student's app renders as a QR code
+type PaymentCode struct {
+	Code      string    `json:"code"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type ChargeRequest struct {
+	PaymentCode string  `json:"payment_code"`
+	Amount      float64 `json:"amount"`    // in Rupees
+	Reference   string  `json:"reference"` // merchant's order or receipt number
+}
+
+type ChargeResult struct {
+	PaymentID uuid.UUID    `json:"payment_id"`
+	Reference string       `json:"reference"`
+	Amount    common.Money `json:"amount"`
+	PayerName string       `json:"payer_name"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type SettlementLine struct {
+	PaymentID uuid.UUID    `json:"payment_id"`
+	Reference string       `json:"reference"`
+	PayerName string       `json:"payer_name"`
+	Amount    common.Money `json:"amount"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// SettlementReport covers one merchant's business day. GrossAmount is the sum of
+// payments taken; NetAmount is the wallet's ledger movement and also reflects
+// reversals posted that day.
+type SettlementReport struct {
+	MerchantID   uuid.UUID        `json:"merchant_id"`
+	MerchantName string           `json:"merchant_name"`
+	Date         string           `json:"date"`
+	PaymentCount int              `json:"payment_count"`
+	GrossAmount  common.Money     `json:"gross_amount"`
+	NetAmount    common.Money     `json:"net_amount"`
+	Payments     []SettlementLine `json:"payments"`
+	GeneratedAt  time.Time        `json:"generated_at"`
+}
+
+type DailySettlementJob struct {
+	MerchantID uuid.UUID `json:"merchant_id"`
+	Date       string    `json:"date"`
+}
+
+func mapDBMerchant(m merchantdb.GikiWalletMerchant) *Merchant {
+	return &Merchant{
+		ID:                   m.ID,
+		Name:                 m.Name,
+		Category:             m.Category,
+		WalletID:             m.WalletID,
+		APIKeyID:             m.ApiKeyID,
+		SettlementEmail:      common.TextToString(m.SettlementEmail),
+		IsActive:             m.IsActive,
+		CredentialsRotatedAt: m.CredentialsRotatedAt,
+		CreatedAt:            m.CreatedAt,
+	}
+}
@@ -0,0 +1,380 @@
+package merchant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hash-walker/giki-wallet/internal/common"
+	commonerrors "github.com/hash-walker/giki-wallet/internal/common/errors"
+	merchantdb "github.com/hash-walker/giki-wallet/internal/merchant/merchant_db"
+	"github.com/hash-walker/giki-wallet/internal/wallet"
+	"github.com/hash-walker/giki-wallet/internal/worker"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const maxReferenceLength = 64
+
+type Service struct {
+	q          *merchantdb.Queries
+	dbPool     *pgxpool.Pool
+	walletS    *wallet.Service
+	worker     *worker.JobWorker
+	codeSecret string
+	loc        *time.Location
+}
+
+func NewService(dbPool *pgxpool.Pool, walletS *wallet.Service, worker *worker.JobWorker, codeSecret string, loc *time.Location) *Service {
+	return &Service{
+		q:          merchantdb.New(dbPool),
+		dbPool:     dbPool,
+		walletS:    walletS,
+		worker:     worker,
+		codeSecret: codeSecret,
+		loc:        loc,
+	}
+}
+
+// =============================================================================
+// STUDENT SIDE
+// =============================================================================
+
+// IssuePaymentCode creates a single-use payment code for the user to show at a
+// merchant's counter. The code is signed and expires after paymentCodeTTL.
+func (s *Service) IssuePaymentCode(ctx context.Context, userID uuid.UUID) (*PaymentCode, error) {
+	expiresAt := time.Now().Add(paymentCodeTTL).Truncate(time.Second)
+
+	row, err := s.q.CreatePaymentCode(ctx, merchantdb.CreatePaymentCodeParams{
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	return &PaymentCode{
+		Code:      signPaymentCode(s.codeSecret, row.ID, userID, expiresAt),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// =============================================================================
+// MERCHANT API
+// =============================================================================
+
+// Authenticate resolves a merchant from its API key ID and secret
+func (s *Service) Authenticate(ctx context.Context, keyID, secret string) (*merchantdb.GikiWalletMerchant, error) {
+	if keyID == "" || secret == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	m, err := s.q.GetMerchantByKeyID(ctx, keyID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	if !secretMatches(secret, m.ApiSecretHash) {
+		return nil, ErrInvalidCredentials
+	}
+
+	if !m.IsActive {
+		return nil, ErrMerchantInactive
+	}
+
+	return &m, nil
+}
+
+// Charge debits the student who presented the payment code and credits the
+// merchant's wallet. The merchant's reference makes retries safe: charging the
+// same reference with the same code returns the original payment.
+func (s *Service) Charge(ctx context.Context, m *merchantdb.GikiWalletMerchant, req ChargeRequest) (*ChargeResult, error) {
+	amount := int64(common.AmountToLowestUnit(req.Amount))
+	if amount <= 0 {
+		return nil, ErrInvalidPayment.WithDetails("amount", "must be greater than zero")
+	}
+
+	reference := strings.TrimSpace(req.Reference)
+	if reference == "" || len(reference) > maxReferenceLength {
+		return nil, ErrInvalidPayment.WithDetails("reference", fmt.Sprintf("required, at most %d characters", maxReferenceLength))
+	}
+
+	codeID, userID, expiresAt, err := parsePaymentCode(s.codeSecret, req.PaymentCode)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.q.GetMerchantPaymentByReference(ctx, merchantdb.GetMerchantPaymentByReferenceParams{
+		MerchantID:        m.ID,
+		MerchantReference: reference,
+	})
+	if err == nil {
+		if existing.PaymentCodeID != codeID || existing.Amount != amount {
+			return nil, ErrDuplicateReference
+		}
+		return s.chargeResult(ctx, existing)
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	if !time.Now().Before(expiresAt) {
+		return nil, ErrPaymentCodeExpired
+	}
+
+	var payment merchantdb.GikiWalletMerchantPayment
+
+	err = common.WithTransaction(ctx, s.dbPool, func(tx pgx.Tx) error {
+		merchantQ := s.q.WithTx(tx)
+
+		consumed, err := merchantQ.ConsumePaymentCode(ctx, merchantdb.ConsumePaymentCodeParams{
+			ID:     codeID,
+			UserID: userID,
+		})
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabase, err)
+		}
+		if consumed == 0 {
+			return ErrPaymentCodeUsed
+		}
+
+		userWallet, err := s.walletS.GetOrCreateWallet(ctx, tx, userID)
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabase, err)
+		}
+
+		payment, err = merchantQ.CreateMerchantPayment(ctx, merchantdb.CreateMerchantPaymentParams{
+			MerchantID:        m.ID,
+			UserID:            userID,
+			PaymentCodeID:     codeID,
+			Amount:            amount,
+			MerchantReference: reference,
+		})
+		if err != nil {
+			if common.IsUniqueConstraintViolation(err) {
+				return ErrDuplicateReference
+			}
+			return commonerrors.Wrap(ErrDatabase, err)
+		}
+
+		return s.walletS.ExecuteTransaction(
+			ctx,
+			tx,
+			userWallet.ID,
+			m.WalletID,
+			amount,
+			TransactionTypeMerchantPayment,
+			payment.ID.String(),
+			fmt.Sprintf("Payment to %s (ref %s)", m.Name, reference),
+		)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.chargeResult(ctx, payment)
+}
+
+func (s *Service) chargeResult(ctx context.Context, p merchantdb.GikiWalletMerchantPayment) (*ChargeResult, error) {
+	payerName, err := s.q.GetPayerName(ctx, p.UserID)
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	return &ChargeResult{
+		PaymentID: p.ID,
+		Reference: p.MerchantReference,
+		Amount:    common.Money(p.Amount),
+		PayerName: payerName,
+		CreatedAt: p.CreatedAt,
+	}, nil
+}
+
+// =============================================================================
+// ADMIN
+// =============================================================================
+
+// CreateMerchant opens a merchant together with its wallet and API credentials.
+// The secret is only returned here and by RotateCredentials.
+func (s *Service) CreateMerchant(ctx context.Context, createdBy uuid.UUID, req CreateMerchantRequest) (*MerchantCredentials, error) {
+	name := strings.TrimSpace(req.Name)
+	if len(name) < 2 || len(name) > 100 {
+		return nil, ErrInvalidMerchant.WithDetails("name", "must be 2-100 characters")
+	}
+
+	category := normalizeCategory(req.Category)
+
+	email, err := normalizeSettlementEmail(req.SettlementEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	keyID, secret, err := newCredentials()
+	if err != nil {
+		return nil, commonerrors.Wrap(commonerrors.ErrInternal, err)
+	}
+
+	var created merchantdb.GikiWalletMerchant
+
+	err = common.WithTransaction(ctx, s.dbPool, func(tx pgx.Tx) error {
+		walletID, err := s.walletS.CreateMerchantWallet(ctx, tx, name)
+		if err != nil {
+			return err
+		}
+
+		created, err = s.q.WithTx(tx).CreateMerchant(ctx, merchantdb.CreateMerchantParams{
+			Name:            name,
+			Category:        category,
+			WalletID:        walletID,
+			ApiKeyID:        keyID,
+			ApiSecretHash:   hashSecret(secret),
+			SettlementEmail: email,
+			CreatedBy:       createdBy,
+		})
+		if err != nil {
+			if common.IsUniqueConstraintViolation(err) {
+				return ErrDuplicateMerchant
+			}
+			return commonerrors.Wrap(ErrDatabase, err)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &MerchantCredentials{
+		Merchant:  *mapDBMerchant(created),
+		APISecret: secret,
+	}, nil
+}
+
+func (s *Service) ListMerchants(ctx context.Context, page, pageSize int) ([]Merchant, int64, error) {
+	rows, err := s.q.ListMerchants(ctx, merchantdb.ListMerchantsParams{
+		Limit:  int32(pageSize),
+		Offset: int32((page - 1) * pageSize),
+	})
+	if err != nil {
+		return nil, 0, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	var totalCount int64
+	merchants := make([]Merchant, 0, len(rows))
+	for _, r := range rows {
+		totalCount = r.TotalCount
+		merchants = append(merchants, Merchant{
+			ID:                   r.ID,
+			Name:                 r.Name,
+			Category:             r.Category,
+			WalletID:             r.WalletID,
+			APIKeyID:             r.ApiKeyID,
+			SettlementEmail:      common.TextToString(r.SettlementEmail),
+			IsActive:             r.IsActive,
+			Balance:              common.Money(r.Balance),
+			CredentialsRotatedAt: r.CredentialsRotatedAt,
+			CreatedAt:            r.CreatedAt,
+		})
+	}
+
+	return merchants, totalCount, nil
+}
+
+func (s *Service) GetMerchant(ctx context.Context, merchantID uuid.UUID) (*Merchant, error) {
+	m, err := s.q.GetMerchantByID(ctx, merchantID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrMerchantNotFound
+		}
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	balance, err := s.walletS.GetWalletBalance(ctx, m.WalletID)
+	if err != nil {
+		return nil, err
+	}
+
+	merchant := mapDBMerchant(m)
+	merchant.Balance = balance
+	return merchant, nil
+}
+
+// UpdateMerchant changes a merchant's category, settlement contact and status.
+// A disabled merchant's credentials stop working immediately.
+func (s *Service) UpdateMerchant(ctx context.Context, merchantID uuid.UUID, req UpdateMerchantRequest) (*Merchant, error) {
+	email, err := normalizeSettlementEmail(req.SettlementEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := s.q.UpdateMerchant(ctx, merchantdb.UpdateMerchantParams{
+		ID:              merchantID,
+		Category:        normalizeCategory(req.Category),
+		SettlementEmail: email,
+		IsActive:        req.IsActive,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrMerchantNotFound
+		}
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	return mapDBMerchant(m), nil
+}
+
+// RotateCredentials replaces a merchant's API key and secret; the old pair stops
+// working as soon as this returns.
+func (s *Service) RotateCredentials(ctx context.Context, merchantID uuid.UUID) (*MerchantCredentials, error) {
+	keyID, secret, err := newCredentials()
+	if err != nil {
+		return nil, commonerrors.Wrap(commonerrors.ErrInternal, err)
+	}
+
+	m, err := s.q.RotateMerchantCredentials(ctx, merchantdb.RotateMerchantCredentialsParams{
+		ID:            merchantID,
+		ApiKeyID:      keyID,
+		ApiSecretHash: hashSecret(secret),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrMerchantNotFound
+		}
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	return &MerchantCredentials{
+		Merchant:  *mapDBMerchant(m),
+		APISecret: secret,
+	}, nil
+}
+
+func normalizeCategory(category string) string {
+	category = strings.ToUpper(strings.TrimSpace(category))
+	if category == "" {
+		return "GENERAL"
+	}
+	return category
+}
+
+func normalizeSettlementEmail(email string) (pgtype.Text, error) {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return pgtype.Text{}, nil
+	}
+
+	if _, err := mail.ParseAddress(email); err != nil {
+		return pgtype.Text{}, ErrInvalidMerchant.WithDetails("settlement_email", "must be a valid email address")
+	}
+
+	return common.StringToText(email), nil
+}
@@ -0,0 +1,201 @@
+package merchant
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hash-walker/giki-wallet/internal/common"
+	commonerrors "github.com/hash-walker/giki-wallet/internal/common/errors"
+	merchantdb "github.com/hash-walker/giki-wallet/internal/merchant/merchant_db"
+	"github.com/hash-walker/giki-wallet/internal/worker"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	JobScheduleDailySettlements = "SCHEDULE_MERCHANT_SETTLEMENTS"
+	JobSendDailySettlement      = "SEND_MERCHANT_SETTLEMENT"
+)
+
+// GetSettlementReport lists a merchant's payments for one business day
+// (YYYY-MM-DD in the app timezone) with the day's totals.
+func (s *Service) GetSettlementReport(ctx context.Context, merchantID uuid.UUID, date string) (*SettlementReport, error) {
+	start, err := time.ParseInLocation("2006-01-02", date, s.loc)
+	if err != nil || start.After(time.Now()) {
+		return nil, ErrInvalidSettlementDate
+	}
+	end := start.AddDate(0, 0, 1)
+
+	m, err := s.q.GetMerchantByID(ctx, merchantID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrMerchantNotFound
+		}
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	rows, err := s.q.ListMerchantPaymentsBetween(ctx, merchantdb.ListMerchantPaymentsBetweenParams{
+		MerchantID: merchantID,
+		StartDate:  start,
+		EndDate:    end,
+	})
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	net, err := s.q.GetWalletNetMovement(ctx, merchantdb.GetWalletNetMovementParams{
+		WalletID:  m.WalletID,
+		StartDate: start,
+		EndDate:   end,
+	})
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	var gross int64
+	lines := make([]SettlementLine, 0, len(rows))
+	for _, r := range rows {
+		gross += r.Amount
+		lines = append(lines, SettlementLine{
+			PaymentID: r.ID,
+			Reference: r.MerchantReference,
+			PayerName: r.PayerName,
+			Amount:    common.Money(r.Amount),
+			CreatedAt: r.CreatedAt,
+		})
+	}
+
+	return &SettlementReport{
+		MerchantID:   m.ID,
+		MerchantName: m.Name,
+		Date:         start.Format("2006-01-02"),
+		PaymentCount: len(lines),
+		GrossAmount:  common.Money(gross),
+		NetAmount:    common.Money(net),
+		Payments:     lines,
+		GeneratedAt:  time.Now(),
+	}, nil
+}
+
+// RenderSettlementCSV writes the report as CSV with the totals as trailing rows
+func RenderSettlementCSV(report *SettlementReport) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	w := csv.NewWriter(buf)
+
+	rows := [][]string{{"Time", "Payment ID", "Reference", "Payer", "Amount"}}
+	for _, p := range report.Payments {
+		rows = append(rows, []string{
+			p.CreatedAt.Format(time.RFC3339),
+			p.PaymentID.String(),
+			p.Reference,
+			p.PayerName,
+			p.Amount.Decimal(),
+		})
+	}
+
+	rows = append(rows,
+		[]string{"", "", "", "Gross", report.GrossAmount.Decimal()},
+		[]string{"", "", "", "Net (after reversals)", report.NetAmount.Decimal()},
+	)
+
+	if err := w.WriteAll(rows); err != nil {
+		return nil, commonerrors.Wrap(commonerrors.ErrInternal, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// SettlementFileName is the download name of a settlement report
+func SettlementFileName(report *SettlementReport) string {
+	return fmt.Sprintf("settlement_%s.csv", report.Date)
+}
+
+// RegisterSettlementJobs hands the settlement jobs to the worker and schedules
+// the first fan-out for the coming midnight.
+func (s *Service) RegisterSettlementJobs(ctx context.Context) error {
+	s.worker.RegisterHandler(JobScheduleDailySettlements, s.handleScheduleDailySettlements)
+	s.worker.RegisterHandler(JobSendDailySettlement, s.handleSendDailySettlement)
+
+	return s.worker.EnsureScheduled(ctx, JobScheduleDailySettlements, struct{}{}, s.nextMidnight(time.Now()))
+}
+
+// handleScheduleDailySettlements queues a settlement for every active merchant
+// with a settlement email for the day that just ended, then schedules tomorrow's run.
+func (s *Service) handleScheduleDailySettlements(ctx context.Context, _ json.RawMessage) error {
+	now := time.Now()
+	day := s.nextMidnight(now).AddDate(0, 0, -2).Format("2006-01-02")
+
+	if err := s.worker.EnsureScheduled(ctx, JobScheduleDailySettlements, struct{}{}, s.nextMidnight(now)); err != nil {
+		return err
+	}
+
+	merchants, err := s.q.ListActiveMerchants(ctx)
+	if err != nil {
+		return commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	queued := 0
+	for _, m := range merchants {
+		if !m.SettlementEmail.Valid {
+			continue
+		}
+		if err := s.worker.Enqueue(ctx, JobSendDailySettlement, DailySettlementJob{
+			MerchantID: m.ID,
+			Date:       day,
+		}); err != nil {
+			return err
+		}
+		queued++
+	}
+
+	log.Printf("[Settlements] queued %d merchant settlements for %s", queued, day)
+	return nil
+}
+
+func (s *Service) handleSendDailySettlement(ctx context.Context, payload json.RawMessage) error {
+	var job DailySettlementJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return err
+	}
+
+	m, err := s.q.GetMerchantByID(ctx, job.MerchantID)
+	if err != nil {
+		return commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	if !m.SettlementEmail.Valid {
+		return nil
+	}
+
+	report, err := s.GetSettlementReport(ctx, job.MerchantID, job.Date)
+	if err != nil {
+		return err
+	}
+
+	document, err := RenderSettlementCSV(report)
+	if err != nil {
+		return err
+	}
+
+	return s.worker.Enqueue(ctx, "SEND_MERCHANT_SETTLEMENT_EMAIL", worker.MerchantSettlementEmailPayload{
+		Email:        m.SettlementEmail.String,
+		MerchantName: report.MerchantName,
+		Date:         report.Date,
+		PaymentCount: report.PaymentCount,
+		GrossAmount:  report.GrossAmount.Rupees(),
+		NetAmount:    report.NetAmount.Rupees(),
+		FileName:     SettlementFileName(report),
+		Document:     document,
+	})
+}
+
+func (s *Service) nextMidnight(t time.Time) time.Time {
+	t = t.In(s.loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, s.loc).AddDate(0, 0, 1)
+}
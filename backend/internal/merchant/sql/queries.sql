@@ -0,0 +1,99 @@
+-- name: CreateMerchant :one
+INSERT INTO giki_wallet.merchants (
+    name, category, wallet_id, api_key_id, api_secret_hash, settlement_email, created_by
+)
+VALUES (
+    sqlc.arg('name'), sqlc.arg('category'), sqlc.arg('wallet_id'), sqlc.arg('api_key_id'),
+    sqlc.arg('api_secret_hash'), sqlc.narg('settlement_email'), sqlc.arg('created_by')
+)
+RETURNING *;
+
+-- name: GetMerchantByID :one
+SELECT * FROM giki_wallet.merchants
+WHERE id = $1;
+
+-- name: GetMerchantByKeyID :one
+SELECT * FROM giki_wallet.merchants
+WHERE api_key_id = $1;
+
+-- name: ListMerchants :many
+SELECT
+    m.*,
+    COALESCE(wb.balance, 0)::BIGINT AS balance,
+    COUNT(*) OVER() AS total_count
+FROM giki_wallet.merchants m
+         LEFT JOIN giki_wallet.wallet_balances wb ON wb.wallet_id = m.wallet_id
+ORDER BY m.name
+LIMIT $1 OFFSET $2;
+
+-- name: ListActiveMerchants :many
+SELECT * FROM giki_wallet.merchants
+WHERE is_active
+ORDER BY name;
+
+-- name: UpdateMerchant :one
+UPDATE giki_wallet.merchants
+SET category = sqlc.arg('category'),
+    settlement_email = sqlc.narg('settlement_email'),
+    is_active = sqlc.arg('is_active'),
+    updated_at = NOW()
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: RotateMerchantCredentials :one
+UPDATE giki_wallet.merchants
+SET api_key_id = sqlc.arg('api_key_id'),
+    api_secret_hash = sqlc.arg('api_secret_hash'),
+    credentials_rotated_at = NOW(),
+    updated_at = NOW()
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: CreatePaymentCode :one
+INSERT INTO giki_wallet.payment_codes (user_id, expires_at)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: ConsumePaymentCode :execrows
+-- Marks a code used exactly once; zero rows means it was used, expired or forged.
+UPDATE giki_wallet.payment_codes
+SET used_at = NOW()
+WHERE id = $1
+  AND user_id = $2
+  AND used_at IS NULL
+  AND expires_at > NOW();
+
+-- name: CreateMerchantPayment :one
+INSERT INTO giki_wallet.merchant_payments (
+    merchant_id, user_id, payment_code_id, amount, merchant_reference
+)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetMerchantPaymentByReference :one
+SELECT * FROM giki_wallet.merchant_payments
+WHERE merchant_id = $1 AND merchant_reference = $2;
+
+-- name: GetPayerName :one
+SELECT name FROM giki_wallet.users
+WHERE id = $1;
+
+-- name: ListMerchantPaymentsBetween :many
+SELECT
+    p.id, p.user_id, p.amount, p.merchant_reference, p.created_at,
+    u.name AS payer_name
+FROM giki_wallet.merchant_payments p
+         JOIN giki_wallet.users u ON p.user_id = u.id
+WHERE p.merchant_id = sqlc.arg('merchant_id')
+  AND p.created_at >= sqlc.arg('start_date')
+  AND p.created_at < sqlc.arg('end_date')
+ORDER BY p.created_at ASC;
+
+-- name: GetWalletNetMovement :one
+-- Net ledger movement on a wallet in [start_date, end_date); this includes
+-- reversals of earlier payments, unlike the payments list.
+SELECT COALESCE(SUM(amount), 0)::BIGINT AS net_amount
+FROM giki_wallet.ledger
+WHERE wallet_id = sqlc.arg('wallet_id')
+  AND created_at >= sqlc.arg('start_date')
+  AND created_at < sqlc.arg('end_date');
@@ -21,6 +21,34 @@ var (
 
 	// Gateway Errors
 	ErrGatewayUnavailable = errors.New("GATEWAY_UNAVAILABLE", http.StatusBadGateway, "Payment gateway is currently unavailable")
+	ErrCallbackRejected   = errors.New("CALLBACK_REJECTED", http.StatusBadRequest, "Payment callback does not match the transaction")
+
+	// Settlement Errors
+	ErrInvalidSettlementFile       = errors.New("INVALID_SETTLEMENT_FILE", http.StatusBadRequest, "Settlement file could not be read")
+	ErrInvalidSettlementDate       = errors.New("INVALID_SETTLEMENT_DATE", http.StatusBadRequest, "Settlement date must be a past day as YYYY-MM-DD")
+	ErrSettlementAlreadyImported   = errors.New("SETTLEMENT_ALREADY_IMPORTED", http.StatusConflict, "This settlement file has already been imported")
+	ErrSettlementNotFound          = errors.New("SETTLEMENT_NOT_FOUND", http.StatusNotFound, "Settlement not found")
+	ErrSettlementItemNotFound      = errors.New("SETTLEMENT_ITEM_NOT_FOUND", http.StatusNotFound, "Settlement item not found")
+	ErrSettlementItemResolved      = errors.New("SETTLEMENT_ITEM_RESOLVED", http.StatusConflict, "Settlement item is matched or already resolved")
+	ErrInvalidSettlementResolution = errors.New("INVALID_SETTLEMENT_RESOLUTION", http.StatusBadRequest, "Resolution must be CORRECTED, GATEWAY_ERROR or ACCEPTED, with a note")
+
+	// Refund Errors
+	ErrInvalidRefund        = errors.New("INVALID_REFUND", http.StatusBadRequest, "Refund needs a positive amount and a reason")
+	ErrRefundNotAllowed     = errors.New("REFUND_NOT_ALLOWED", http.StatusConflict, "Only successful top-ups can be refunded")
+	ErrRefundExceedsPayment = errors.New("REFUND_EXCEEDS_PAYMENT", http.StatusConflict, "Refund is more than is left to refund on this top-up")
+	ErrRefundInProgress     = errors.New("REFUND_IN_PROGRESS", http.StatusConflict, "This top-up already has a refund awaiting approval or in progress")
+	ErrRefundNotFound       = errors.New("REFUND_NOT_FOUND", http.StatusNotFound, "Refund request not found")
+	ErrRefundNotPending     = errors.New("REFUND_NOT_PENDING", http.StatusConflict, "Refund request has already been reviewed")
+	ErrRefundTransaction    = errors.New("REFUND_TRANSACTION", http.StatusConflict, "Refund transactions are settled through their refund request")
+	ErrSelfApproval         = errors.New("SELF_APPROVAL", http.StatusForbidden, "You cannot review a request you proposed")
+	ErrReasonRequired       = errors.New("REASON_REQUIRED", http.StatusBadRequest, "A reason is required")
+
+	// Force Resolution Errors
+	ErrInvalidForceResolution    = errors.New("INVALID_FORCE_RESOLUTION", http.StatusBadRequest, "Resolution must be SUCCESS or FAILED, with a reason and evidence")
+	ErrForceResolutionNotAllowed = errors.New("FORCE_RESOLUTION_NOT_ALLOWED", http.StatusConflict, "This transaction cannot be resolved to that status")
+	ErrForceResolutionPending    = errors.New("FORCE_RESOLUTION_PENDING", http.StatusConflict, "This transaction already has a resolution awaiting approval")
+	ErrForceResolutionNotFound   = errors.New("FORCE_RESOLUTION_NOT_FOUND", http.StatusNotFound, "Force resolution not found")
+	ErrForceResolutionReviewed   = errors.New("FORCE_RESOLUTION_REVIEWED", http.StatusConflict, "Force resolution has already been reviewed")
 
 	// Internal Errors
 	ErrUserIDNotFound      = errors.New("USER_ID_NOT_FOUND", http.StatusUnauthorized, "User ID not found in context")
@@ -0,0 +1,343 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/hash-walker/giki-wallet/internal/common"
+	commonerrors "github.com/hash-walker/giki-wallet/internal/common/errors"
+	"github.com/hash-walker/giki-wallet/internal/payment/gateway"
+	payment "github.com/hash-walker/giki-wallet/internal/payment/payment_db"
+	"github.com/jackc/pgx/v5"
+)
+
+// RequestForceResolution records that support confirmed a stuck gateway
+// transaction's outcome with the bank. Nothing changes until a different
+// admin approves it.
+func (s *Service) RequestForceResolution(ctx context.Context, txnRefNo string, requestedBy uuid.UUID, req RequestForceResolutionRequest) (*GatewayForceResolution, error) {
+	reason := strings.TrimSpace(req.Reason)
+	evidence := strings.TrimSpace(req.Evidence)
+	target := PaymentStatus(strings.ToUpper(string(req.Status)))
+
+	if (target != PaymentStatusSuccess && target != PaymentStatusFailed) || reason == "" || evidence == "" {
+		return nil, ErrInvalidForceResolution
+	}
+
+	txn, err := s.q.GetTransactionByTxnRefNo(ctx, strings.TrimSpace(txnRefNo))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, commonerrors.Wrap(ErrDatabaseQuery, err)
+	}
+
+	if err := checkForceResolvable(txn, target); err != nil {
+		return nil, err
+	}
+
+	resolution, err := s.q.CreateGatewayForceResolution(ctx, payment.CreateGatewayForceResolutionParams{
+		GatewayTxnID:  txn.ID,
+		FromStatus:    string(txn.Status),
+		TargetStatus:  string(target),
+		Reason:        reason,
+		Evidence:      evidence,
+		BankReference: common.StringToText(strings.TrimSpace(req.BankReference)),
+		RequestedBy:   requestedBy,
+	})
+	if err != nil {
+		if CheckUniqueConstraintViolation(err) {
+			return nil, ErrForceResolutionPending
+		}
+		return nil, commonerrors.Wrap(ErrDatabaseQuery, err)
+	}
+
+	return forceResolutionResult(resolution, txn), nil
+}
+
+// ApproveForceResolution applies a requested resolution. A top-up moved to
+// SUCCESS is credited through the same idempotent path as a gateway
+// confirmation, so approving a transaction that was credited meanwhile does
+// not credit it twice. A refund in flight is completed or released through
+// its refund request. The change is recorded as ADMIN_FORCE_RESOLUTION.
+func (s *Service) ApproveForceResolution(ctx context.Context, resolutionID, approverID uuid.UUID, note string) (*GatewayForceResolution, error) {
+	note = strings.TrimSpace(note)
+
+	var result *GatewayForceResolution
+
+	err := common.WithTransaction(ctx, s.dbPool, func(tx pgx.Tx) error {
+		paymentQ := s.q.WithTx(tx)
+
+		resolution, err := s.lockForceResolution(ctx, paymentQ, resolutionID)
+		if err != nil {
+			return err
+		}
+
+		if resolution.RequestedBy == approverID {
+			return ErrSelfApproval
+		}
+
+		// A refund is locked before its gateway transaction, in the same order
+		// as ApproveRefund, so the two never wait on each other. The kind never
+		// changes, so reading it before taking either lock is safe.
+		current, err := paymentQ.GetGatewayTransactionByID(ctx, resolution.GatewayTxnID)
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabaseQuery, err)
+		}
+
+		var refund payment.GikiWalletGatewayRefund
+		if current.Kind == GatewayTxnKindRefund {
+			refund, err = paymentQ.GetGatewayRefundByGatewayTxnForUpdate(ctx, common.GoogleUUIDtoPgUUID(current.ID, true))
+			if err != nil {
+				return commonerrors.Wrap(ErrDatabaseQuery, err)
+			}
+		}
+
+		txn, err := paymentQ.GetGatewayTransactionForUpdate(ctx, resolution.GatewayTxnID)
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabaseQuery, err)
+		}
+
+		// the gateway may have settled it since the request
+		target := PaymentStatus(resolution.TargetStatus)
+		if err := checkForceResolvable(txn, target); err != nil {
+			return err
+		}
+
+		message := fmt.Sprintf("Marked %s after confirmation with the bank", target)
+
+		if _, err := paymentQ.ForceUpdateGatewayTransactionStatus(ctx, payment.ForceUpdateGatewayTransactionStatusParams{
+			Status:         payment.CurrentStatus(target),
+			GatewayMessage: common.StringToText(message),
+			TxnRefNo:       txn.TxnRefNo,
+		}); err != nil {
+			return commonerrors.Wrap(ErrTransactionUpdate, err)
+		}
+
+		if txn.Kind == GatewayTxnKindRefund {
+			if refund.Status != RefundStatusProcessing {
+				return commonerrors.Wrap(ErrForceResolutionNotAllowed, fmt.Errorf("refund %s is %s", refund.ID, refund.Status))
+			}
+
+			if _, err := s.settleRefund(ctx, tx, refund, txn.TxnRefNo, target, message, "FORCED"); err != nil {
+				return err
+			}
+		} else {
+			if target == PaymentStatusSuccess {
+				txnType := depositTxnType(gateway.Provider(txn.GatewayProvider))
+				if err := s.creditWalletFromPayment(ctx, tx, txn.UserID, txn.Amount, txn.TxnRefNo, txnType); err != nil {
+					return err
+				}
+			}
+
+			if err := s.notifyStatus(ctx, paymentQ, txn.TxnRefNo, target, message); err != nil {
+				return commonerrors.Wrap(ErrTransactionUpdate, err)
+			}
+		}
+
+		// callbacks still waiting on this transaction are settled by the resolution
+		if err := paymentQ.MarkAuditsProcessedByTxn(ctx, common.StringToText(txn.TxnRefNo)); err != nil {
+			return commonerrors.Wrap(ErrDatabaseQuery, err)
+		}
+
+		rawPayload, err := json.Marshal(map[string]interface{}{
+			"resolution_id":  resolution.ID,
+			"kind":           txn.Kind,
+			"amount":         txn.Amount,
+			"from_status":    txn.Status,
+			"target_status":  resolution.TargetStatus,
+			"reason":         resolution.Reason,
+			"evidence":       resolution.Evidence,
+			"bank_reference": resolution.BankReference.String,
+			"requested_by":   resolution.RequestedBy,
+			"approved_by":    approverID,
+			"note":           note,
+		})
+		if err != nil {
+			return commonerrors.Wrap(ErrInternal, err)
+		}
+
+		auditLog, err := paymentQ.CreateAuditLog(ctx, payment.CreateAuditLogParams{
+			EventType:  payment.GikiWalletAuditEventTypeADMINFORCERESOLUTION,
+			RawPayload: rawPayload,
+			TxnRefNo:   common.StringToText(txn.TxnRefNo),
+			GatewayRef: common.StringToText(resolution.BankReference.String),
+			UserID:     common.GoogleUUIDtoPgUUID(txn.UserID, true),
+		})
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabaseQuery, err)
+		}
+
+		// the entry records a finished action; nothing is left to process
+		if err := paymentQ.MarkAuditProcessed(ctx, auditLog.ID); err != nil {
+			return commonerrors.Wrap(ErrDatabaseQuery, err)
+		}
+
+		approved, err := paymentQ.ReviewGatewayForceResolution(ctx, payment.ReviewGatewayForceResolutionParams{
+			ID:         resolution.ID,
+			Status:     ForceResolutionStatusApproved,
+			ReviewedBy: common.GoogleUUIDtoPgUUID(approverID, true),
+			ReviewNote: common.StringToText(note),
+			AuditID:    common.GoogleUUIDtoPgUUID(auditLog.ID, true),
+		})
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabaseQuery, err)
+		}
+
+		result = forceResolutionResult(approved, txn)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// RejectForceResolution closes a requested resolution without touching the transaction
+func (s *Service) RejectForceResolution(ctx context.Context, resolutionID, reviewerID uuid.UUID, note string) (*GatewayForceResolution, error) {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return nil, ErrReasonRequired
+	}
+
+	var result *GatewayForceResolution
+
+	err := common.WithTransaction(ctx, s.dbPool, func(tx pgx.Tx) error {
+		paymentQ := s.q.WithTx(tx)
+
+		resolution, err := s.lockForceResolution(ctx, paymentQ, resolutionID)
+		if err != nil {
+			return err
+		}
+
+		if resolution.RequestedBy == reviewerID {
+			return ErrSelfApproval
+		}
+
+		txn, err := paymentQ.GetGatewayTransactionByID(ctx, resolution.GatewayTxnID)
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabaseQuery, err)
+		}
+
+		rejected, err := paymentQ.ReviewGatewayForceResolution(ctx, payment.ReviewGatewayForceResolutionParams{
+			ID:         resolution.ID,
+			Status:     ForceResolutionStatusRejected,
+			ReviewedBy: common.GoogleUUIDtoPgUUID(reviewerID, true),
+			ReviewNote: common.StringToText(note),
+		})
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabaseQuery, err)
+		}
+
+		result = forceResolutionResult(rejected, txn)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *Service) ListForceResolutions(ctx context.Context, status string, page, pageSize int) ([]GatewayForceResolution, int64, error) {
+	rows, err := s.q.ListGatewayForceResolutions(ctx, payment.ListGatewayForceResolutionsParams{
+		Status: strings.ToUpper(status),
+		Limit:  int32(pageSize),
+		Offset: int32((page - 1) * pageSize),
+	})
+	if err != nil {
+		return nil, 0, commonerrors.Wrap(ErrDatabaseQuery, err)
+	}
+
+	var totalCount int64
+	items := make([]GatewayForceResolution, 0, len(rows))
+	for _, r := range rows {
+		totalCount = r.TotalCount
+		items = append(items, GatewayForceResolution{
+			ID:              r.ID,
+			GatewayTxnID:    r.GatewayTxnID,
+			TxnRefNo:        r.TxnRefNo,
+			Kind:            r.Kind,
+			UserID:          r.UserID,
+			UserName:        r.UserName,
+			Amount:          common.Money(r.Amount),
+			FromStatus:      r.FromStatus,
+			TargetStatus:    r.TargetStatus,
+			Reason:          r.Reason,
+			Evidence:        r.Evidence,
+			BankReference:   common.TextToString(r.BankReference),
+			Status:          r.Status,
+			RequestedBy:     r.RequestedBy,
+			RequestedByName: r.RequestedByName,
+			ReviewedBy:      common.PgUUIDToUUIDPointer(r.ReviewedBy),
+			ReviewedByName:  common.TextToStringPointer(r.ReviewedByName),
+			ReviewNote:      common.TextToString(r.ReviewNote),
+			AuditID:         common.PgUUIDToUUIDPointer(r.AuditID),
+			CreatedAt:       r.CreatedAt,
+			ReviewedAt:      common.TimestamptzToTimePointer(r.ReviewedAt),
+		})
+	}
+
+	return items, totalCount, nil
+}
+
+// checkForceResolvable allows a resolution only where the gateway has not
+// given a final answer, or where the bank shows a failed top-up was in fact
+// paid. A SUCCESS is never undone here; money goes back through a refund.
+func checkForceResolvable(txn payment.GikiWalletGatewayTransaction, target PaymentStatus) error {
+	switch txn.Status {
+	case payment.CurrentStatusPENDING, payment.CurrentStatusUNKNOWN:
+		return nil
+	case payment.CurrentStatusFAILED:
+		if txn.Kind == GatewayTxnKindTopUp && target == PaymentStatusSuccess {
+			return nil
+		}
+	}
+
+	return commonerrors.Wrap(ErrForceResolutionNotAllowed, fmt.Errorf("%s %s is %s", txn.Kind, txn.TxnRefNo, txn.Status))
+}
+
+func (s *Service) lockForceResolution(ctx context.Context, paymentQ *payment.Queries, resolutionID uuid.UUID) (payment.GikiWalletGatewayForceResolution, error) {
+	resolution, err := paymentQ.GetGatewayForceResolutionForUpdate(ctx, resolutionID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return resolution, ErrForceResolutionNotFound
+		}
+		return resolution, commonerrors.Wrap(ErrDatabaseQuery, err)
+	}
+
+	if resolution.Status != ForceResolutionStatusPending {
+		return resolution, commonerrors.Wrap(ErrForceResolutionReviewed, fmt.Errorf("resolution %s is %s", resolution.ID, resolution.Status))
+	}
+
+	return resolution, nil
+}
+
+func forceResolutionResult(r payment.GikiWalletGatewayForceResolution, txn payment.GikiWalletGatewayTransaction) *GatewayForceResolution {
+	return &GatewayForceResolution{
+		ID:            r.ID,
+		GatewayTxnID:  r.GatewayTxnID,
+		TxnRefNo:      txn.TxnRefNo,
+		Kind:          txn.Kind,
+		UserID:        txn.UserID,
+		Amount:        common.Money(txn.Amount),
+		FromStatus:    r.FromStatus,
+		TargetStatus:  r.TargetStatus,
+		Reason:        r.Reason,
+		Evidence:      r.Evidence,
+		BankReference: common.TextToString(r.BankReference),
+		Status:        r.Status,
+		RequestedBy:   r.RequestedBy,
+		ReviewedBy:    common.PgUUIDToUUIDPointer(r.ReviewedBy),
+		ReviewNote:    common.TextToString(r.ReviewNote),
+		AuditID:       common.PgUUIDToUUIDPointer(r.AuditID),
+		CreatedAt:     r.CreatedAt,
+		ReviewedAt:    common.TimestamptzToTimePointer(r.ReviewedAt),
+	}
+}
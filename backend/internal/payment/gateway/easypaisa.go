@@ -0,0 +1,499 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	commonerrors "github.com/hash-walker/giki-wallet/internal/common/errors"
+)
+
+// =============================================================================
+// CONSTANTS - Field name constants
+// =============================================================================
+
+const (
+	// Mobile account and inquiry API (JSON)
+	EasypaisaFieldOrderID           = "orderId"
+	EasypaisaFieldStoreID           = "storeId"
+	EasypaisaFieldTransactionAmount = "transactionAmount"
+	EasypaisaFieldTransactionType   = "transactionType"
+	EasypaisaFieldMobileAccountNo   = "mobileAccountNo"
+	EasypaisaFieldAccountNum        = "accountNum"
+	EasypaisaFieldResponseCode      = "responseCode"
+	EasypaisaFieldResponseDesc      = "responseDesc"
+	EasypaisaFieldTransactionID     = "transactionId"
+	EasypaisaFieldTransactionStatus = "transactionStatus"
+
+	// Hosted checkout form
+	EasypaisaFieldAmount            = "amount"
+	EasypaisaFieldPostBackURL       = "postBackURL"
+	EasypaisaFieldOrderRefNum       = "orderRefNum"
+	EasypaisaFieldExpiryDate        = "expiryDate"
+	EasypaisaFieldAutoRedirect      = "autoRedirect"
+	EasypaisaFieldPaymentMethod     = "paymentMethod"
+	EasypaisaFieldMerchantHashedReq = "merchantHashedReq"
+	EasypaisaFieldAuthToken         = "auth_token"
+
+	// Hosted checkout postback
+	EasypaisaFieldOrderRefNumber = "orderRefNumber"
+	EasypaisaFieldStatus         = "status"
+	EasypaisaFieldDesc           = "desc"
+)
+
+const (
+	easypaisaCodeSuccess       = "0000"
+	easypaisaTxnTypeMA         = "MA"
+	easypaisaCardPaymentMethod = "CC_PAYMENT_METHOD"
+	easypaisaExpiryLayout      = "20060102 150405"
+)
+
+// =============================================================================
+// TYPES
+// =============================================================================
+
+type EasypaisaClient struct {
+	storeID       string
+	accountNum    string
+	username      string
+	password      string
+	hashKey       string
+	postBackURL   string
+	baseURL       string
+	maInitiateURL string
+	inquiryURL    string
+	checkoutURL   string
+	confirmURL    string
+	httpClient    *http.Client
+}
+
+// =============================================================================
+// CONSTRUCTOR
+// =============================================================================
+
+func NewEasypaisaClient(
+	storeID string,
+	accountNum string,
+	username string,
+	password string,
+	hashKey string,
+	postBackURL string,
+	baseURL string,
+	maInitiateURL string,
+	inquiryURL string,
+	checkoutURL string,
+	confirmURL string,
+) *EasypaisaClient {
+	return &EasypaisaClient{
+		storeID:       storeID,
+		accountNum:    accountNum,
+		username:      username,
+		password:      password,
+		hashKey:       hashKey,
+		postBackURL:   postBackURL,
+		baseURL:       baseURL,
+		maInitiateURL: maInitiateURL,
+		inquiryURL:    inquiryURL,
+		checkoutURL:   checkoutURL,
+		confirmURL:    confirmURL,
+		httpClient: &http.Client{
+			Timeout: 45 * time.Second, // HTTP timeout
+		},
+	}
+}
+
+// =============================================================================
+// PUBLIC API METHODS - Gateway interface implementation
+// =============================================================================
+
+func (c *EasypaisaClient) Provider() Provider {
+	return ProviderEasypaisa
+}
+
+// SubmitMWallet charges an Easypaisa mobile account. The customer approves the
+// payment in their app, so the call returns once they have or the request expires.
+func (c *EasypaisaClient) SubmitMWallet(ctx context.Context, req MWalletInitiateRequest) (*MWalletInitiateResponse, error) {
+	amount, err := easypaisaAmount(req.AmountPaisa)
+	if err != nil {
+		return nil, commonerrors.Wrap(commonerrors.ErrInvalidInput, err)
+	}
+
+	body := map[string]string{
+		EasypaisaFieldOrderID:           req.TxnRefNo,
+		EasypaisaFieldStoreID:           c.storeID,
+		EasypaisaFieldTransactionAmount: amount,
+		EasypaisaFieldTransactionType:   easypaisaTxnTypeMA,
+		EasypaisaFieldMobileAccountNo:   req.MobileNumber,
+	}
+
+	responseMap, err := c.postJSON(ctx, c.maInitiateURL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	responseCode, _ := responseMap[EasypaisaFieldResponseCode].(string)
+	responseDesc, _ := responseMap[EasypaisaFieldResponseDesc].(string)
+
+	resp := &MWalletInitiateResponse{
+		Status:       mapEasypaisaResponseCode(responseCode),
+		ResponseCode: responseCode,
+		Message:      getEasypaisaMessage(responseCode, responseDesc),
+		Raw:          responseMap,
+	}
+
+	if txnID, ok := responseMap[EasypaisaFieldTransactionID].(string); ok {
+		resp.RRN = txnID
+	}
+
+	return resp, nil
+}
+
+// InitiateCard builds the hosted checkout form. Easypaisa first redirects back
+// with an auth_token that has to be posted to the confirm page, see
+// ConfirmCheckout; only the second redirect carries the payment result.
+func (c *EasypaisaClient) InitiateCard(ctx context.Context, req CardInitiateRequest) (*CardInitiateResponse, error) {
+	amount, err := easypaisaAmount(req.AmountPaisa)
+	if err != nil {
+		return nil, commonerrors.Wrap(commonerrors.ErrInvalidInput, err)
+	}
+
+	expiry, err := time.Parse("20060102150405", req.TxnExpiryDateTime)
+	if err != nil {
+		return nil, commonerrors.Wrap(commonerrors.ErrInvalidInput, fmt.Errorf("invalid expiry date time: %w", err))
+	}
+
+	postBackURL := req.ReturnURL
+	if postBackURL == "" {
+		postBackURL = c.postBackURL
+	}
+
+	fields := map[string]string{
+		EasypaisaFieldStoreID:       c.storeID,
+		EasypaisaFieldAmount:        amount,
+		EasypaisaFieldPostBackURL:   postBackURL,
+		EasypaisaFieldOrderRefNum:   req.TxnRefNo,
+		EasypaisaFieldExpiryDate:    expiry.Format(easypaisaExpiryLayout),
+		EasypaisaFieldAutoRedirect:  "1",
+		EasypaisaFieldPaymentMethod: easypaisaCardPaymentMethod,
+	}
+
+	hashedReq, err := c.EasypaisaHashedRequest(fields)
+	if err != nil {
+		return nil, commonerrors.Wrap(commonerrors.ErrInternal, fmt.Errorf("failed to compute merchant hash: %w", err))
+	}
+
+	fields[EasypaisaFieldMerchantHashedReq] = hashedReq
+
+	return &CardInitiateResponse{
+		PostURL: joinURL(c.baseURL, c.checkoutURL),
+		Fields:  fields,
+	}, nil
+}
+
+// ConfirmCheckout is the second leg of the hosted checkout: the auth_token
+// from the first postback is posted back to Easypaisa, which then shows the
+// payment page and finally redirects to the postback URL with the result.
+func (c *EasypaisaClient) ConfirmCheckout(authToken string) *CardInitiateResponse {
+	return &CardInitiateResponse{
+		PostURL: joinURL(c.baseURL, c.confirmURL),
+		Fields: map[string]string{
+			EasypaisaFieldAuthToken:   authToken,
+			EasypaisaFieldPostBackURL: c.postBackURL,
+		},
+	}
+}
+
+// ParseAndVerifyCardCallback reads the final checkout postback. Easypaisa does
+// not sign it, so the outcome is taken from an inquiry for the order rather
+// than from the status the browser brought back.
+func (c *EasypaisaClient) ParseAndVerifyCardCallback(ctx context.Context, form map[string]string) (*CardCallback, error) {
+	responseMap := make(map[string]any, len(form))
+	for key, val := range form {
+		responseMap[key] = val
+	}
+
+	orderRef := form[EasypaisaFieldOrderRefNumber]
+	if orderRef == "" {
+		return nil, commonerrors.Wrap(commonerrors.ErrInvalidInput, fmt.Errorf("missing %s in postback", EasypaisaFieldOrderRefNumber))
+	}
+
+	inquiry, err := c.Inquiry(ctx, InquiryRequest{TxnRefNo: orderRef})
+	if err != nil {
+		return nil, err
+	}
+
+	callbackStatus := mapEasypaisaResponseCode(form[EasypaisaFieldStatus])
+	if callbackStatus == StatusSuccess && inquiry.Status != StatusSuccess {
+		responseMap["verification"] = fmt.Sprintf("postback claimed success, inquiry returned %s", inquiry.Status)
+	}
+
+	return &CardCallback{
+		TxnRefNo:        orderRef,
+		AmountPaisa:     easypaisaAmountPaisa(inquiry.Raw[EasypaisaFieldTransactionAmount]),
+		ResponseCode:    inquiry.ResponseCode,
+		Status:          inquiry.Status,
+		Message:         inquiry.Message,
+		ResponseMessage: form[EasypaisaFieldDesc],
+		RRN:             inquiry.RRN,
+		Raw:             responseMap,
+	}, nil
+}
+
+func (c *EasypaisaClient) Inquiry(ctx context.Context, req InquiryRequest) (*InquiryResponse, error) {
+	body := map[string]string{
+		EasypaisaFieldOrderID:    req.TxnRefNo,
+		EasypaisaFieldStoreID:    c.storeID,
+		EasypaisaFieldAccountNum: c.accountNum,
+	}
+
+	responseMap, err := c.postJSON(ctx, c.inquiryURL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	res := c.mapInquiryResponse(responseMap)
+	return &res, nil
+}
+
+// Refund is not offered through Easypaisa's merchant API; refunds are raised
+// with Easypaisa directly.
+func (c *EasypaisaClient) Refund(ctx context.Context, req RefundRequest) (*RefundResponse, error) {
+	return nil, commonerrors.Wrap(commonerrors.ErrExternalService, fmt.Errorf("easypaisa does not support refunds through the API"))
+}
+
+// =============================================================================
+// HELPERS - Request signing
+// =============================================================================
+
+// EasypaisaHashedRequest signs checkout fields the way Easypaisa expects: the
+// fields as key=value pairs sorted by key and joined with &, encrypted with
+// AES-ECB (PKCS5 padding) under the store's hash key and base64 encoded.
+func (c *EasypaisaClient) EasypaisaHashedRequest(fields map[string]string) (string, error) {
+	keys := make([]string, 0, len(fields))
+	for k, v := range fields {
+		if k != EasypaisaFieldMerchantHashedReq && v != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var message strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			message.WriteString("&")
+		}
+		message.WriteString(k)
+		message.WriteString("=")
+		message.WriteString(fields[k])
+	}
+
+	block, err := aes.NewCipher([]byte(c.hashKey))
+	if err != nil {
+		return "", err
+	}
+
+	size := block.BlockSize()
+	plain := []byte(message.String())
+	padding := size - len(plain)%size
+	plain = append(plain, bytes.Repeat([]byte{byte(padding)}, padding)...)
+
+	encrypted := make([]byte, len(plain))
+	for i := 0; i < len(plain); i += size {
+		block.Encrypt(encrypted[i:i+size], plain[i:i+size])
+	}
+
+	return base64.StdEncoding.EncodeToString(encrypted), nil
+}
+
+// =============================================================================
+// HELPERS - HTTP
+// =============================================================================
+
+func (c *EasypaisaClient) postJSON(ctx context.Context, path string, body map[string]string) (map[string]any, error) {
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, commonerrors.Wrap(commonerrors.ErrInternal, fmt.Errorf("failed to marshal payload: %w", err))
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", joinURL(c.baseURL, path), bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, commonerrors.Wrap(commonerrors.ErrInternal, fmt.Errorf("failed to create http request: %w", err))
+	}
+
+	credentials := base64.StdEncoding.EncodeToString([]byte(c.username + ":" + c.password))
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Credentials", credentials)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, commonerrors.Wrap(commonerrors.ErrExternalService, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, commonerrors.Wrap(commonerrors.ErrExternalService, fmt.Errorf("easypaisa API returned status %d", resp.StatusCode))
+	}
+
+	var responseMap map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&responseMap); err != nil {
+		return nil, commonerrors.Wrap(commonerrors.ErrInternal, fmt.Errorf("failed to decode response: %w", err))
+	}
+
+	return responseMap, nil
+}
+
+func joinURL(base, path string) string {
+	return strings.TrimSuffix(base, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+// easypaisaAmount turns an amount in paisa into the Rupee amount with
+// decimals that Easypaisa expects, e.g. "12345" becomes "123.45"
+func easypaisaAmount(amountPaisa string) (string, error) {
+	paisa, err := strconv.ParseInt(amountPaisa, 10, 64)
+	if err != nil || paisa <= 0 {
+		return "", fmt.Errorf("invalid amount %q", amountPaisa)
+	}
+
+	return fmt.Sprintf("%d.%02d", paisa/100, paisa%100), nil
+}
+
+// easypaisaAmountPaisa reads a Rupee amount from an Easypaisa response, sent
+// as a number or a string, back into paisa. It is "" when the amount is
+// missing or unreadable.
+func easypaisaAmountPaisa(v any) string {
+	var rupees string
+	switch amount := v.(type) {
+	case string:
+		rupees = strings.TrimSpace(amount)
+	case float64:
+		rupees = strconv.FormatFloat(amount, 'f', 2, 64)
+	default:
+		return ""
+	}
+
+	whole, frac, _ := strings.Cut(rupees, ".")
+	if len(frac) > 2 {
+		return ""
+	}
+	frac += strings.Repeat("0", 2-len(frac))
+
+	paisa, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil || paisa <= 0 {
+		return ""
+	}
+
+	return strconv.FormatInt(paisa, 10)
+}
+
+// =============================================================================
+// HELPERS - Response mappers
+// =============================================================================
+
+// mapEasypaisaResponseCode maps an API response code to a Status
+func mapEasypaisaResponseCode(responseCode string) Status {
+	switch responseCode {
+	case easypaisaCodeSuccess:
+		return StatusSuccess
+	case "0002", "0003", "0005", "0006", "0007", "0008", "0010", "0013", "0014", "0015":
+		return StatusFailed
+	default:
+		return StatusUnknown
+	}
+}
+
+// mapEasypaisaTransactionStatus maps the transactionStatus of an inquiry.
+// REVERSED is left unknown on purpose: it follows a success, and flipping a
+// credited top-up to failed is for finance to decide.
+func mapEasypaisaTransactionStatus(status string) Status {
+	switch strings.ToUpper(status) {
+	case "PAID":
+		return StatusSuccess
+	case "PENDING", "INITIATED":
+		return StatusPending
+	case "FAILED", "BLOCKED", "DROPPED", "EXPIRED":
+		return StatusFailed
+	default:
+		return StatusUnknown
+	}
+}
+
+func (c *EasypaisaClient) mapInquiryResponse(responseMap map[string]any) InquiryResponse {
+	resp := InquiryResponse{
+		Raw: responseMap,
+	}
+
+	responseCode, _ := responseMap[EasypaisaFieldResponseCode].(string)
+	resp.ResponseCode = responseCode
+
+	txnStatus, _ := responseMap[EasypaisaFieldTransactionStatus].(string)
+	resp.PaymentResponseCode = txnStatus
+
+	if responseCode == easypaisaCodeSuccess {
+		resp.Status = mapEasypaisaTransactionStatus(txnStatus)
+	} else {
+		// the inquiry itself failed, e.g. the order is not known yet
+		resp.Status = StatusUnknown
+	}
+
+	responseDesc, _ := responseMap[EasypaisaFieldResponseDesc].(string)
+	resp.Message = getEasypaisaStatusMessage(resp.Status, responseCode, responseDesc)
+
+	if txnID, ok := responseMap[EasypaisaFieldTransactionID].(string); ok {
+		resp.RRN = txnID
+	}
+
+	return resp
+}
+
+// =============================================================================
+// HELPERS - Response Code
+// =============================================================================
+
+// getEasypaisaMessage maps Easypaisa response codes to user-friendly messages
+func getEasypaisaMessage(responseCode, responseDesc string) string {
+	switch responseCode {
+	case easypaisaCodeSuccess:
+		return "Transaction completed successfully"
+	case "0001":
+		return "Easypaisa could not process the request. Please try again later"
+	case "0002", "0003":
+		return "Payment request was rejected by Easypaisa. Please contact support"
+	case "0005", "0006", "0007", "0008", "0010":
+		return "Easypaisa payments are temporarily unavailable. Please try another payment method"
+	case "0013":
+		return "Insufficient balance. Please add funds to your Easypaisa account"
+	case "0014":
+		return "Easypaisa account not found. Please verify your mobile number"
+	case "0015":
+		return "Payment was not approved in the Easypaisa app"
+	}
+
+	if responseDesc != "" {
+		return responseDesc
+	}
+	if responseCode != "" {
+		return fmt.Sprintf("Transaction failed with code: %s. Please contact support", responseCode)
+	}
+	return "Transaction failed. Please try again"
+}
+
+func getEasypaisaStatusMessage(status Status, responseCode, responseDesc string) string {
+	switch status {
+	case StatusSuccess:
+		return "Transaction completed successfully"
+	case StatusPending:
+		return "Transaction is pending. Please wait for confirmation"
+	case StatusFailed:
+		return "Transaction was not completed"
+	default:
+		return getEasypaisaMessage(responseCode, responseDesc)
+	}
+}
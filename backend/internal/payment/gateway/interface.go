@@ -19,6 +19,15 @@ const (
 
 type Status string
 
+// Provider names a payment gateway. It is stored on every gateway transaction
+// so inquiries and callbacks go back to the provider that started it.
+type Provider string
+
+const (
+	ProviderJazzCash  Provider = "JAZZCASH"
+	ProviderEasypaisa Provider = "EASYPAISA"
+)
+
 type MWalletInitiateRequest struct {
 	AmountPaisa       string
 	BillRefID         string
@@ -43,14 +52,14 @@ type CardInitiateRequest struct {
 	BillRefID         string
 	TxnRefNo          string
 	Description       string
-	ReturnURL         string
+	ReturnURL         string // optional; defaults to the gateway's configured callback URL
 	TxnDateTime       string
 	TxnExpiryDateTime string
 }
 
 type CardInitiateResponse struct {
-	PostURL string            // JazzCash merchantform URL
-	Fields  map[string]string // pp_* form fields inc. pp_SecureHash
+	PostURL string            // hosted checkout URL the browser posts to
+	Fields  map[string]string // form fields inc. the gateway's signature
 }
 
 type InquiryRequest struct {
@@ -69,6 +78,7 @@ type InquiryResponse struct {
 // CardCallback Card callback payload (ReturnURL POST) after redirect
 type CardCallback struct {
 	TxnRefNo        string
+	AmountPaisa     string // empty when the gateway does not report it
 	ResponseCode    string
 	Status          Status
 	Message         string
@@ -77,16 +87,47 @@ type CardCallback struct {
 	Raw             map[string]any // full pp_* payload
 }
 
+// MWalletCallback is the server-to-server notification (IPN) a gateway posts
+// when an MWallet payment reaches a result
+type MWalletCallback struct {
+	TxnRefNo     string
+	AmountPaisa  string
+	ResponseCode string
+	Status       Status
+	Message      string
+	RRN          string
+	Raw          map[string]any // full pp_* payload
+}
+
+// RefundRequest sends money from a successful top-up back to where it came
+// from. TxnRefNo is the original top-up's reference.
+type RefundRequest struct {
+	TxnRefNo    string
+	AmountPaisa string
+	Card        bool // card top-ups are refunded through a different API
+}
+
+type RefundResponse struct {
+	Status       Status
+	ResponseCode string
+	Message      string
+	Raw          map[string]any
+}
+
 // =============================================================================
 // Interfaces
 // =============================================================================
 
 type Gateway interface {
+	Provider() Provider
+
 	SubmitMWallet(ctx context.Context, req MWalletInitiateRequest) (*MWalletInitiateResponse, error)
 	InitiateCard(ctx context.Context, req CardInitiateRequest) (*CardInitiateResponse, error)
 
 	Inquiry(ctx context.Context, req InquiryRequest) (*InquiryResponse, error)
 
+	Refund(ctx context.Context, req RefundRequest) (*RefundResponse, error)
+
 	// ParseAndVerifyCardCallback For card ReturnURL/callback validation
 	ParseAndVerifyCardCallback(ctx context.Context, form map[string]string) (*CardCallback, error)
 }
@@ -37,6 +37,7 @@ const (
 	FieldReturnURL         = "pp_ReturnURL"
 	FieldSecureHash        = "pp_SecureHash"
 	FieldTxnCurrency       = "pp_TxnCurrency"
+	FieldMerchantMPIN      = "pp_MerchantMPIN"
 )
 
 // =============================================================================
@@ -55,6 +56,8 @@ type JazzCashClient struct {
 	walletPaymentURL string
 	cardPaymentURL   string
 	statusInquiryURL string
+	walletRefundURL  string
+	cardRefundURL    string
 	httpClient       *http.Client // For making API calls
 }
 
@@ -72,6 +75,8 @@ func NewJazzCashClient(
 	walletPaymentURL string,
 	cardPaymentURL string,
 	statusInquiryURL string,
+	walletRefundURL string,
+	cardRefundURL string,
 ) *JazzCashClient {
 	return &JazzCashClient{
 		merchantID:       merchantID,
@@ -83,6 +88,8 @@ func NewJazzCashClient(
 		walletPaymentURL: walletPaymentURL,
 		cardPaymentURL:   cardPaymentURL,
 		statusInquiryURL: statusInquiryURL,
+		walletRefundURL:  walletRefundURL,
+		cardRefundURL:    cardRefundURL,
 		httpClient: &http.Client{
 			Timeout: 45 * time.Second, // HTTP timeout
 		},
@@ -93,6 +100,10 @@ func NewJazzCashClient(
 // PUBLIC API METHODS - Gateway interface implementation
 // =============================================================================
 
+func (c *JazzCashClient) Provider() Provider {
+	return ProviderJazzCash
+}
+
 func (c *JazzCashClient) SubmitMWallet(ctx context.Context, req MWalletInitiateRequest) (*MWalletInitiateResponse, error) {
 	fields := c.buildMWalletFields(req)
 
@@ -153,6 +164,10 @@ func (c *JazzCashClient) SubmitMWallet(ctx context.Context, req MWalletInitiateR
 }
 
 func (c *JazzCashClient) InitiateCard(ctx context.Context, req CardInitiateRequest) (*CardInitiateResponse, error) {
+	if req.ReturnURL == "" {
+		req.ReturnURL = c.cardCallbackURL
+	}
+
 	fields := c.buildCardFields(req)
 
 	// find the secure hash
@@ -187,6 +202,47 @@ func (c *JazzCashClient) ParseAndVerifyCardCallback(ctx context.Context, rForm m
 
 }
 
+// ParseAndVerifyMWalletCallback checks the pp_SecureHash of an MWallet IPN
+// and reads the payment result from it
+func (c *JazzCashClient) ParseAndVerifyMWalletCallback(ctx context.Context, fields map[string]string) (*MWalletCallback, error) {
+	responseMap := make(map[string]any, len(fields))
+	for key, val := range fields {
+		responseMap[key] = val
+	}
+
+	if err := c.verifyResponseHash(responseMap); err != nil {
+		return nil, commonerrors.Wrap(commonerrors.ErrInvalidInput, fmt.Errorf("IPN hash verification failed: %w", err))
+	}
+
+	callback := &MWalletCallback{
+		TxnRefNo:     fields[FieldTxnRefNo],
+		AmountPaisa:  fields[FieldAmount],
+		ResponseCode: fields["pp_ResponseCode"],
+		RRN:          fields["pp_RetreivalReferenceNo"],
+		Raw:          responseMap,
+	}
+	callback.Status = mapResponseCodeToStatus(callback.ResponseCode)
+	callback.Message = getUserFriendlyMessage(callback.ResponseCode)
+
+	if callback.TxnRefNo == "" {
+		return nil, commonerrors.Wrap(commonerrors.ErrInvalidInput, fmt.Errorf("missing %s in IPN", FieldTxnRefNo))
+	}
+
+	return callback, nil
+}
+
+// MWalletCallbackAck is the signed answer JazzCash expects to an IPN; any
+// other answer makes it send the notification again
+func (c *JazzCashClient) MWalletCallbackAck(responseCode, message string) JazzCashFields {
+	fields := JazzCashFields{
+		"pp_ResponseCode":    responseCode,
+		"pp_ResponseMessage": message,
+	}
+	fields[FieldSecureHash] = SignJazzCashFields(c.integritySalt, fields)
+
+	return fields
+}
+
 func (c *JazzCashClient) Inquiry(ctx context.Context, req InquiryRequest) (*InquiryResponse, error) {
 	fields := c.buildInquiryFields(req.TxnRefNo)
 
@@ -248,11 +304,70 @@ func (c *JazzCashClient) Inquiry(ctx context.Context, req InquiryRequest) (*Inqu
 
 }
 
+// Refund returns part or all of a successful top-up. JazzCash keys the refund
+// on the original pp_TxnRefNo; wallet refunds also need the merchant MPIN.
+func (c *JazzCashClient) Refund(ctx context.Context, req RefundRequest) (*RefundResponse, error) {
+	fields := c.buildRefundFields(req)
+
+	secureHash, err := c.JazzcashSecureHash(fields)
+
+	if err != nil {
+		return nil, commonerrors.Wrap(commonerrors.ErrInternal, fmt.Errorf("failed to compute secure hash: %w", err))
+	}
+
+	fields[FieldSecureHash] = secureHash
+
+	jsonBody, err := json.Marshal(fields)
+	if err != nil {
+		return nil, commonerrors.Wrap(commonerrors.ErrInternal, fmt.Errorf("failed to marshal refund fields: %w", err))
+	}
+
+	refundURL := c.walletRefundURL
+	if req.Card {
+		refundURL = c.cardRefundURL
+	}
+
+	fullURL := strings.TrimSuffix(c.baseURL, "/") + "/" + strings.TrimPrefix(refundURL, "/")
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", fullURL, bytes.NewBuffer(jsonBody))
+
+	if err != nil {
+		return nil, commonerrors.Wrap(commonerrors.ErrInternal, fmt.Errorf("failed to create refund http request: %w", err))
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(httpReq)
+
+	if err != nil {
+		return nil, commonerrors.Wrap(commonerrors.ErrExternalService, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, commonerrors.Wrap(commonerrors.ErrExternalService, fmt.Errorf("refund API returned status %d", resp.StatusCode))
+	}
+
+	var responseMap map[string]any
+
+	if err := json.NewDecoder(resp.Body).Decode(&responseMap); err != nil {
+		return nil, commonerrors.Wrap(commonerrors.ErrInternal, fmt.Errorf("failed to decode response: %w", err))
+	}
+
+	return c.mapRefundResponse(responseMap), nil
+}
+
 // =============================================================================
 // HELPERS - Secure hash computation and Verification
 // =============================================================================
 
 func (c *JazzCashClient) JazzcashSecureHash(requestData JazzCashFields) (string, error) {
+	return SignJazzCashFields(c.integritySalt, requestData), nil
+}
+
+// SignJazzCashFields computes pp_SecureHash for a set of fields with a
+// merchant's integrity salt. It is shared with the local JazzCash simulator
+// so both ends of a request sign the same way.
+func SignJazzCashFields(integritySalt string, requestData JazzCashFields) string {
 
 	ppFields := make(map[string]string)
 
@@ -288,15 +403,15 @@ func (c *JazzCashClient) JazzcashSecureHash(requestData JazzCashFields) (string,
 
 	// prepend the integrity salt with & to the message
 
-	saltedMessage := c.integritySalt + "&" + message.String()
+	saltedMessage := integritySalt + "&" + message.String()
 
 	// make the HMAC hash from the salted message with secret integrity salt
 
-	mac := hmac.New(sha256.New, []byte(c.integritySalt))
+	mac := hmac.New(sha256.New, []byte(integritySalt))
 	mac.Write([]byte(saltedMessage))
 	hash := mac.Sum(nil)
 
-	return strings.ToUpper(hex.EncodeToString(hash)), nil
+	return strings.ToUpper(hex.EncodeToString(hash))
 }
 
 // verifyResponseHash verifies the secure hash in JazzCash response
@@ -385,6 +500,22 @@ func (c *JazzCashClient) buildInquiryFields(txnRefNo string) JazzCashFields {
 	return fields
 }
 
+func (c *JazzCashClient) buildRefundFields(req RefundRequest) JazzCashFields {
+	fields := make(JazzCashFields)
+
+	fields[FieldTxnRefNo] = req.TxnRefNo
+	fields[FieldAmount] = req.AmountPaisa
+	fields[FieldTxnCurrency] = "PKR"
+	fields[FieldMerchantID] = c.merchantID
+	fields[FieldPassword] = c.password
+
+	if !req.Card {
+		fields[FieldMerchantMPIN] = c.merchantMPIN
+	}
+
+	return fields
+}
+
 // =============================================================================
 // HELPERS - Response mappers
 // =============================================================================
@@ -504,6 +635,8 @@ func (c *JazzCashClient) mapCardResponse(responseMap map[string]any) *CardCallba
 
 	resp.TxnRefNo = txnRefNo
 
+	resp.AmountPaisa, _ = responseMap[FieldAmount].(string)
+
 	// Extract RRN
 	if rrn, ok := responseMap["pp_RetreivalReferenceNo"].(string); ok {
 		resp.RRN = rrn
@@ -513,6 +646,19 @@ func (c *JazzCashClient) mapCardResponse(responseMap map[string]any) *CardCallba
 
 }
 
+func (c *JazzCashClient) mapRefundResponse(responseMap map[string]any) *RefundResponse {
+	resp := &RefundResponse{
+		Raw: responseMap,
+	}
+
+	responseCode, _ := responseMap["pp_ResponseCode"].(string)
+	resp.ResponseCode = responseCode
+	resp.Status = mapResponseCodeToStatus(responseCode)
+	resp.Message = getUserFriendlyMessage(responseCode)
+
+	return resp
+}
+
 // =============================================================================
 // HELPERS - Response Code
 // =============================================================================
@@ -0,0 +1,400 @@
+package jazzcashsim
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/hash-walker/giki-wallet/internal/payment/gateway"
+)
+
+// =============================================================================
+// ROUTER
+// =============================================================================
+
+// Router serves the JazzCash endpoints and the /_sim control endpoints
+func (s *Simulator) Router() http.Handler {
+	r := chi.NewRouter()
+	// JazzCash paths are configured with or without a trailing slash
+	r.Use(chimiddleware.StripSlashes)
+
+	r.Post(MWalletPath, s.handleMWallet)
+	r.Post(InquiryPath, s.handleInquiry)
+	r.Post(CardPath, s.handleCardForm)
+	r.Post(WalletRefundPath, s.handleRefund)
+	r.Post(CardRefundPath, s.handleRefund)
+
+	r.Route("/_sim", func(r chi.Router) {
+		r.Get("/scenarios", s.handleListRules)
+		r.Post("/scenarios", s.handleSetRule)
+		r.Post("/reset", s.handleReset)
+		r.Get("/transactions", s.handleListTransactions)
+		r.Get("/transactions/{txnRefNo}", s.handleGetTransaction)
+	})
+
+	return r
+}
+
+// =============================================================================
+// JAZZCASH ENDPOINTS
+// =============================================================================
+
+func (s *Simulator) handleMWallet(w http.ResponseWriter, r *http.Request) {
+	var fields gateway.JazzCashFields
+	if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	if code, ok := s.verify(fields); !ok {
+		s.writeJSON(w, s.rejection(fields, code), nil)
+		return
+	}
+
+	t := s.record("MWALLET", fields)
+	log.Printf("[jazzcash-sim] MWALLET %s amount=%s mobile=%s scenario=%s", t.TxnRefNo, t.Amount, t.MobileNumber, t.Scenario)
+
+	if s.cfg.IPNURL != "" {
+		go s.sendIPN(t)
+	}
+
+	resp := gateway.JazzCashFields{
+		gateway.FieldTxnRefNo:      t.TxnRefNo,
+		gateway.FieldBillReference: t.BillRef,
+		gateway.FieldAmount:        t.Amount,
+		gateway.FieldTxnCurrency:   "PKR",
+		gateway.FieldMerchantID:    s.cfg.MerchantID,
+		gateway.FieldLanguage:      "EN",
+		gateway.FieldMobileNumber:  t.MobileNumber,
+		gateway.FieldTxnDateTime:   fields[gateway.FieldTxnDateTime],
+		"pp_RetreivalReferenceNo":  t.RRN,
+	}
+
+	switch t.Scenario {
+	case ScenarioFailure:
+		setResult(resp, codeDeclined, "Transaction was cancelled by the customer")
+	case ScenarioDelayed:
+		setResult(resp, codePending, "Transaction is pending")
+	case ScenarioTimeout:
+		// the payment goes through but the answer arrives too late to matter
+		select {
+		case <-time.After(s.cfg.HangDuration):
+		case <-r.Context().Done():
+			log.Printf("[jazzcash-sim] MWALLET %s client gave up waiting", t.TxnRefNo)
+			return
+		}
+		setResult(resp, codeSuccess, "Thank you for Using JazzCash, your transaction was successful")
+	default:
+		setResult(resp, codeSuccess, "Thank you for Using JazzCash, your transaction was successful")
+	}
+
+	s.writeJSON(w, resp, t)
+}
+
+func (s *Simulator) handleInquiry(w http.ResponseWriter, r *http.Request) {
+	var fields gateway.JazzCashFields
+	if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	if code, ok := s.verify(fields); !ok {
+		s.writeJSON(w, s.rejection(fields, code), nil)
+		return
+	}
+
+	ref := fields[gateway.FieldTxnRefNo]
+	t, ok := s.lookup(ref)
+	if !ok {
+		resp := gateway.JazzCashFields{gateway.FieldTxnRefNo: ref}
+		setResult(resp, codeNotFound, "Transaction not found")
+		s.writeJSON(w, resp, nil)
+		return
+	}
+
+	state := t.state(time.Now())
+
+	resp := gateway.JazzCashFields{
+		gateway.FieldTxnRefNo:       t.TxnRefNo,
+		gateway.FieldAmount:         t.Amount,
+		"pp_Status":                 string(state),
+		"pp_RetreivalReferenceNo":   t.RRN,
+		"pp_PaymentResponseCode":    paymentCode(state),
+		"pp_PaymentResponseMessage": paymentMessage(state),
+	}
+	setResult(resp, codeSuccess, "Successfully returned status for the transaction")
+
+	s.writeJSON(w, resp, t)
+}
+
+// handleRefund serves both the wallet and the card refund APIs
+func (s *Simulator) handleRefund(w http.ResponseWriter, r *http.Request) {
+	var fields gateway.JazzCashFields
+	if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	if code, ok := s.verify(fields); !ok {
+		s.writeJSON(w, s.rejection(fields, code), nil)
+		return
+	}
+
+	ref := fields[gateway.FieldTxnRefNo]
+	resp := gateway.JazzCashFields{
+		gateway.FieldTxnRefNo: ref,
+		gateway.FieldAmount:   fields[gateway.FieldAmount],
+	}
+
+	t, ok := s.lookup(ref)
+	if !ok {
+		setResult(resp, codeNotFound, "Transaction not found")
+		s.writeJSON(w, resp, nil)
+		return
+	}
+
+	amount, _ := strconv.ParseInt(fields[gateway.FieldAmount], 10, 64)
+	if !s.refund(t, amount) {
+		log.Printf("[jazzcash-sim] REFUND %s amount=%s refused", ref, fields[gateway.FieldAmount])
+		setResult(resp, codeRefused, "Refund amount exceeds the refundable amount")
+		s.writeJSON(w, resp, t)
+		return
+	}
+
+	log.Printf("[jazzcash-sim] REFUND %s amount=%s", ref, fields[gateway.FieldAmount])
+	setResult(resp, codeSuccess, "Thank you for Using JazzCash, your refund was successful")
+	s.writeJSON(w, resp, t)
+}
+
+// handleCardForm plays the hosted card page: the browser posts the merchant
+// form here and is sent straight back to pp_ReturnURL with the result
+func (s *Simulator) handleCardForm(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+
+	fields := make(gateway.JazzCashFields, len(r.PostForm))
+	for k := range r.PostForm {
+		fields[k] = r.PostForm.Get(k)
+	}
+
+	returnURL := fields[gateway.FieldReturnURL]
+	if returnURL == "" {
+		http.Error(w, "pp_ReturnURL is required", http.StatusBadRequest)
+		return
+	}
+
+	if code, ok := s.verify(fields); !ok {
+		s.writeCallbackPage(w, returnURL, s.rejection(fields, code))
+		return
+	}
+
+	t := s.record("CARD", fields)
+	log.Printf("[jazzcash-sim] CARD %s amount=%s scenario=%s", t.TxnRefNo, t.Amount, t.Scenario)
+
+	if t.Scenario == ScenarioTimeout {
+		// the customer paid, but never makes it back to the merchant
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, "<!DOCTYPE html><html><body><p>JazzCash simulator: payment %s completed, the callback was dropped.</p></body></html>", html.EscapeString(t.TxnRefNo))
+		return
+	}
+
+	callback := gateway.JazzCashFields{
+		gateway.FieldVersion:       fields[gateway.FieldVersion],
+		gateway.FieldTxnType:       fields[gateway.FieldTxnType],
+		gateway.FieldLanguage:      fields[gateway.FieldLanguage],
+		gateway.FieldMerchantID:    s.cfg.MerchantID,
+		gateway.FieldAmount:        t.Amount,
+		gateway.FieldTxnCurrency:   fields[gateway.FieldTxnCurrency],
+		gateway.FieldBillReference: t.BillRef,
+		gateway.FieldTxnRefNo:      t.TxnRefNo,
+		gateway.FieldTxnDateTime:   fields[gateway.FieldTxnDateTime],
+		"pp_RetreivalReferenceNo":  t.RRN,
+		"pp_AuthCode":              "SIM" + t.RRN[len(t.RRN)-3:],
+	}
+
+	switch t.Scenario {
+	case ScenarioFailure:
+		setResult(callback, codeDeclined, "Transaction was cancelled by the customer")
+	case ScenarioDelayed:
+		setResult(callback, codePending, "Transaction is pending")
+	default:
+		setResult(callback, codeSuccess, "Thank you for Using JazzCash, your transaction was successful")
+	}
+
+	s.sign(callback, t)
+	s.writeCallbackPage(w, returnURL, callback)
+}
+
+// =============================================================================
+// CONTROL ENDPOINTS
+// =============================================================================
+
+func (s *Simulator) handleListRules(w http.ResponseWriter, r *http.Request) {
+	writeControlJSON(w, http.StatusOK, s.Rules())
+}
+
+func (s *Simulator) handleSetRule(w http.ResponseWriter, r *http.Request) {
+	var rule Rule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		writeControlJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON body"})
+		return
+	}
+
+	if err := s.SetRule(rule); err != nil {
+		writeControlJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	writeControlJSON(w, http.StatusOK, s.Rules())
+}
+
+func (s *Simulator) handleReset(w http.ResponseWriter, r *http.Request) {
+	s.Reset()
+	writeControlJSON(w, http.StatusOK, s.Rules())
+}
+
+func (s *Simulator) handleListTransactions(w http.ResponseWriter, r *http.Request) {
+	writeControlJSON(w, http.StatusOK, s.Transactions())
+}
+
+func (s *Simulator) handleGetTransaction(w http.ResponseWriter, r *http.Request) {
+	t, ok := s.Transaction(chi.URLParam(r, "txnRefNo"))
+	if !ok {
+		writeControlJSON(w, http.StatusNotFound, map[string]string{"error": "transaction not found"})
+		return
+	}
+
+	writeControlJSON(w, http.StatusOK, t)
+}
+
+// sendIPN posts the MWallet result to the IPN URL once the payment is final
+func (s *Simulator) sendIPN(t *Transaction) {
+	time.Sleep(time.Until(t.SettlesAt))
+
+	state := t.state(time.Now())
+	fields := gateway.JazzCashFields{
+		gateway.FieldTxnRefNo:     t.TxnRefNo,
+		gateway.FieldAmount:       t.Amount,
+		gateway.FieldTxnCurrency:  "PKR",
+		gateway.FieldMerchantID:   s.cfg.MerchantID,
+		"pp_RetreivalReferenceNo": t.RRN,
+	}
+	if state == stateCompleted {
+		setResult(fields, codeSuccess, "Thank you for Using JazzCash, your transaction was successful")
+	} else {
+		setResult(fields, codeDeclined, "Transaction was cancelled by the customer")
+	}
+	s.sign(fields, t)
+
+	body, _ := json.Marshal(fields)
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	resp, err := client.Post(s.cfg.IPNURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Printf("[jazzcash-sim] IPN %s failed: %v", t.TxnRefNo, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	log.Printf("[jazzcash-sim] IPN %s %s answered %d", t.TxnRefNo, state, resp.StatusCode)
+}
+
+// =============================================================================
+// HELPERS
+// =============================================================================
+
+// rejection is the signed answer to a request with bad credentials or hash
+func (s *Simulator) rejection(fields gateway.JazzCashFields, code string) gateway.JazzCashFields {
+	resp := gateway.JazzCashFields{
+		gateway.FieldTxnRefNo: fields[gateway.FieldTxnRefNo],
+	}
+
+	if code == codeBadAuth {
+		setResult(resp, code, "Invalid merchant credentials")
+	} else {
+		setResult(resp, code, "Secure hash did not match")
+	}
+
+	log.Printf("[jazzcash-sim] rejected %s with %s", fields[gateway.FieldTxnRefNo], code)
+	return resp
+}
+
+func (s *Simulator) writeJSON(w http.ResponseWriter, fields gateway.JazzCashFields, t *Transaction) {
+	s.sign(fields, t)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(fields)
+}
+
+// writeCallbackPage sends the browser back to the merchant with the result
+func (s *Simulator) writeCallbackPage(w http.ResponseWriter, returnURL string, fields gateway.JazzCashFields) {
+	if _, ok := fields[gateway.FieldSecureHash]; !ok {
+		s.sign(fields, nil)
+	}
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var inputs strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&inputs, "<input type=\"hidden\" name=\"%s\" value=\"%s\">\n", html.EscapeString(k), html.EscapeString(fields[k]))
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<body onload="document.getElementById('callback').submit()">
+<p>JazzCash simulator: returning to the merchant...</p>
+<form id="callback" method="POST" action="%s">
+%s<noscript><button type="submit">Continue</button></noscript>
+</form>
+</body>
+</html>`, html.EscapeString(returnURL), inputs.String())
+}
+
+func writeControlJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func setResult(fields gateway.JazzCashFields, code, message string) {
+	fields["pp_ResponseCode"] = code
+	fields["pp_ResponseMessage"] = message
+}
+
+func paymentCode(state paymentState) string {
+	switch state {
+	case stateCompleted:
+		return codeCompleted
+	case statePending:
+		return codePending
+	default:
+		return codeDeclined
+	}
+}
+
+func paymentMessage(state paymentState) string {
+	switch state {
+	case stateCompleted:
+		return "Transaction completed successfully"
+	case statePending:
+		return "Transaction is pending"
+	default:
+		return "Transaction was cancelled by the customer"
+	}
+}
@@ -0,0 +1,390 @@
+// Package jazzcashsim is a local stand-in for the JazzCash payment gateway.
+//
+// It serves the MWallet, card merchant-form, status-inquiry and refund endpoints at
+// the same paths as JazzCash, checks pp_SecureHash on every request, signs
+// every response and posts signed card callbacks back to pp_ReturnURL. When
+// an IPN URL is set it also posts a signed MWallet result there, as JazzCash
+// does once a payment is final.
+// Pointing JAZZCASH_BASE_URL at it runs the whole top-up flow offline.
+//
+// What happens to a payment is decided by a Scenario, picked per transaction
+// reference, then per mobile number, then from the default. Scenarios can be
+// changed while it runs through the /_sim control endpoints.
+package jazzcashsim
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hash-walker/giki-wallet/internal/payment/gateway"
+)
+
+// =============================================================================
+// CONSTANTS
+// =============================================================================
+
+type Scenario string
+
+const (
+	// ScenarioSuccess completes the payment straight away
+	ScenarioSuccess Scenario = "success"
+	// ScenarioFailure declines the payment
+	ScenarioFailure Scenario = "failure"
+	// ScenarioDelayed answers pending and completes after the settle delay
+	ScenarioDelayed Scenario = "delayed"
+	// ScenarioTimeout completes the payment but never delivers the answer:
+	// the MWallet call hangs past the client's timeout and the card page
+	// never posts its callback. Only an inquiry finds out what happened.
+	ScenarioTimeout Scenario = "timeout"
+	// ScenarioTamperedHash completes the payment but signs every response
+	// for it with a corrupted pp_SecureHash
+	ScenarioTamperedHash Scenario = "tampered_hash"
+)
+
+// Paths JazzCash serves its APIs on, relative to the base URL
+const (
+	MWalletPath = "/ApplicationAPI/API/2.0/Purchase/DoMWalletTransaction"
+	InquiryPath = "/ApplicationAPI/API/PaymentInquiry/Inquire"
+	CardPath    = "/CustomerPortal/transactionmanagement/merchantform"
+
+	WalletRefundPath = "/ApplicationAPI/API/Purchase/domwalletrefundtransaction"
+	CardRefundPath   = "/ApplicationAPI/API/authorize/Refund"
+)
+
+// JazzCash response codes the simulator answers with
+const (
+	codeSuccess   = "000"
+	codeCompleted = "121"
+	codePending   = "157"
+	codeDeclined  = "112"
+	codeBadHash   = "115"
+	codeBadAuth   = "101"
+	codeNotFound  = "199"
+	codeRefused   = "110"
+)
+
+type paymentState string
+
+const (
+	stateCompleted paymentState = "Completed"
+	statePending   paymentState = "Pending"
+	stateFailed    paymentState = "Failed"
+)
+
+// =============================================================================
+// TYPES
+// =============================================================================
+
+// Config holds the merchant credentials the simulator expects and its timings
+type Config struct {
+	MerchantID    string
+	Password      string
+	IntegritySalt string
+
+	DefaultScenario Scenario
+
+	// SettleDelay is how long a delayed payment stays pending
+	SettleDelay time.Duration
+	// HangDuration is how long a timed out MWallet call is held open
+	HangDuration time.Duration
+	// IPNURL receives MWallet results server-to-server; empty sends none
+	IPNURL string
+}
+
+// Rule scripts the scenario for one transaction reference or mobile number
+type Rule struct {
+	Scenario     Scenario `json:"scenario"`
+	TxnRefNo     string   `json:"txn_ref_no,omitempty"`
+	MobileNumber string   `json:"mobile_number,omitempty"`
+}
+
+// Transaction is a payment the simulator has seen
+type Transaction struct {
+	TxnRefNo     string    `json:"txn_ref_no"`
+	BillRef      string    `json:"bill_ref"`
+	Amount       string    `json:"amount"`
+	Method       string    `json:"method"`
+	MobileNumber string    `json:"mobile_number,omitempty"`
+	Scenario     Scenario  `json:"scenario"`
+	RRN          string    `json:"rrn"`
+	CreatedAt    time.Time `json:"created_at"`
+	SettlesAt    time.Time `json:"settles_at"`
+	Status       string    `json:"status"`
+	// Refunded is the paisa refunded so far
+	Refunded int64 `json:"refunded,omitempty"`
+}
+
+type Simulator struct {
+	cfg Config
+
+	mu              sync.Mutex
+	defaultScenario Scenario
+	byTxnRef        map[string]Scenario
+	byMobile        map[string]Scenario
+	txns            map[string]*Transaction
+}
+
+// =============================================================================
+// CONSTRUCTOR
+// =============================================================================
+
+func New(cfg Config) *Simulator {
+	if cfg.DefaultScenario == "" {
+		cfg.DefaultScenario = ScenarioSuccess
+	}
+	if cfg.SettleDelay <= 0 {
+		cfg.SettleDelay = 20 * time.Second
+	}
+	if cfg.HangDuration <= 0 {
+		cfg.HangDuration = 60 * time.Second
+	}
+
+	return &Simulator{
+		cfg:             cfg,
+		defaultScenario: cfg.DefaultScenario,
+		byTxnRef:        make(map[string]Scenario),
+		byMobile:        make(map[string]Scenario),
+		txns:            make(map[string]*Transaction),
+	}
+}
+
+// =============================================================================
+// SCENARIO SCRIPTING
+// =============================================================================
+
+func ParseScenario(s string) (Scenario, error) {
+	switch sc := Scenario(strings.ToLower(strings.TrimSpace(s))); sc {
+	case ScenarioSuccess, ScenarioFailure, ScenarioDelayed, ScenarioTimeout, ScenarioTamperedHash:
+		return sc, nil
+	default:
+		return "", fmt.Errorf("unknown scenario %q", s)
+	}
+}
+
+// SetRule scripts a scenario for a transaction reference or a mobile number;
+// a rule with neither replaces the default scenario
+func (s *Simulator) SetRule(rule Rule) error {
+	sc, err := ParseScenario(string(rule.Scenario))
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case rule.TxnRefNo != "":
+		s.byTxnRef[rule.TxnRefNo] = sc
+	case rule.MobileNumber != "":
+		s.byMobile[rule.MobileNumber] = sc
+	default:
+		s.defaultScenario = sc
+	}
+
+	return nil
+}
+
+// Rules lists the scripted scenarios, the default first
+func (s *Simulator) Rules() []Rule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rules := []Rule{{Scenario: s.defaultScenario}}
+
+	for _, ref := range sortedKeys(s.byTxnRef) {
+		rules = append(rules, Rule{Scenario: s.byTxnRef[ref], TxnRefNo: ref})
+	}
+	for _, mobile := range sortedKeys(s.byMobile) {
+		rules = append(rules, Rule{Scenario: s.byMobile[mobile], MobileNumber: mobile})
+	}
+
+	return rules
+}
+
+// Reset drops every rule and transaction and goes back to the configured default
+func (s *Simulator) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.defaultScenario = s.cfg.DefaultScenario
+	s.byTxnRef = make(map[string]Scenario)
+	s.byMobile = make(map[string]Scenario)
+	s.txns = make(map[string]*Transaction)
+}
+
+// =============================================================================
+// TRANSACTIONS
+// =============================================================================
+
+// Transactions lists every payment seen, newest first
+func (s *Simulator) Transactions() []Transaction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	txns := make([]Transaction, 0, len(s.txns))
+	for _, t := range s.txns {
+		snapshot := *t
+		snapshot.Status = string(t.state(now))
+		txns = append(txns, snapshot)
+	}
+
+	sort.Slice(txns, func(i, j int) bool {
+		return txns[i].CreatedAt.After(txns[j].CreatedAt)
+	})
+
+	return txns
+}
+
+func (s *Simulator) Transaction(txnRefNo string) (Transaction, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.txns[txnRefNo]
+	if !ok {
+		return Transaction{}, false
+	}
+
+	snapshot := *t
+	snapshot.Status = string(t.state(time.Now()))
+	return snapshot, true
+}
+
+// record stores a new payment under the scenario scripted for it. A retried
+// reference keeps its first outcome, as JazzCash does not charge twice.
+func (s *Simulator) record(method string, fields gateway.JazzCashFields) *Transaction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ref := fields[gateway.FieldTxnRefNo]
+	if t, ok := s.txns[ref]; ok {
+		return t
+	}
+
+	sc, ok := s.byTxnRef[ref]
+	if !ok {
+		sc, ok = s.byMobile[fields[gateway.FieldMobileNumber]]
+	}
+	if !ok {
+		sc = s.defaultScenario
+	}
+
+	now := time.Now()
+	t := &Transaction{
+		TxnRefNo:     ref,
+		BillRef:      fields[gateway.FieldBillReference],
+		Amount:       fields[gateway.FieldAmount],
+		Method:       method,
+		MobileNumber: fields[gateway.FieldMobileNumber],
+		Scenario:     sc,
+		RRN:          fmt.Sprintf("%012d", rand.Int63n(1_000_000_000_000)),
+		CreatedAt:    now,
+		SettlesAt:    now,
+	}
+	if sc == ScenarioDelayed {
+		t.SettlesAt = now.Add(s.cfg.SettleDelay)
+	}
+
+	s.txns[ref] = t
+	return t
+}
+
+func (s *Simulator) lookup(txnRefNo string) (*Transaction, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.txns[txnRefNo]
+	return t, ok
+}
+
+// refund takes amount paisa off a completed payment, refusing anything over
+// what is left to refund
+func (s *Simulator) refund(t *Transaction, amount int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	paid, err := strconv.ParseInt(t.Amount, 10, 64)
+	if err != nil || amount <= 0 || t.Refunded+amount > paid {
+		return false
+	}
+	if t.state(time.Now()) != stateCompleted {
+		return false
+	}
+
+	t.Refunded += amount
+	return true
+}
+
+// state is where the payment stands at JazzCash at a given time
+func (t *Transaction) state(now time.Time) paymentState {
+	switch t.Scenario {
+	case ScenarioFailure:
+		return stateFailed
+	case ScenarioDelayed:
+		if now.Before(t.SettlesAt) {
+			return statePending
+		}
+		return stateCompleted
+	default:
+		return stateCompleted
+	}
+}
+
+// =============================================================================
+// HELPERS - Signing
+// =============================================================================
+
+// sign adds pp_SecureHash to fields, corrupting it for tampered transactions
+func (s *Simulator) sign(fields gateway.JazzCashFields, t *Transaction) {
+	hash := gateway.SignJazzCashFields(s.cfg.IntegritySalt, fields)
+
+	if t != nil && t.Scenario == ScenarioTamperedHash {
+		hash = tamper(hash)
+	}
+
+	fields[gateway.FieldSecureHash] = hash
+}
+
+// verify checks a request's credentials and pp_SecureHash, returning the
+// response code to reject it with
+func (s *Simulator) verify(fields gateway.JazzCashFields) (string, bool) {
+	if fields[gateway.FieldMerchantID] != s.cfg.MerchantID || fields[gateway.FieldPassword] != s.cfg.Password {
+		return codeBadAuth, false
+	}
+
+	expected := gateway.SignJazzCashFields(s.cfg.IntegritySalt, fields)
+	if !strings.EqualFold(fields[gateway.FieldSecureHash], expected) {
+		return codeBadHash, false
+	}
+
+	return "", true
+}
+
+// tamper flips the last hex digit so the hash no longer matches
+func tamper(hash string) string {
+	if hash == "" {
+		return "0"
+	}
+
+	last := hash[len(hash)-1]
+	replacement := byte('0')
+	if last == '0' {
+		replacement = '1'
+	}
+
+	return hash[:len(hash)-1] + string(replacement)
+}
+
+func sortedKeys(m map[string]Scenario) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,326 @@
+package jazzcashsim
+
+import (
+	"context"
+	"encoding/json"
+	"html"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hash-walker/giki-wallet/internal/payment/gateway"
+)
+
+const (
+	testMerchantID = "SIM_MERCHANT"
+	testPassword   = "sim-password"
+	testSalt       = "sim-integrity-salt"
+)
+
+// startSimulator runs the simulator behind a real HTTP server with IPNs going
+// to a handler that verifies them the way the API does
+func startSimulator(t *testing.T, cfg Config) (*Simulator, *httptest.Server, <-chan *gateway.MWalletCallback) {
+	t.Helper()
+
+	ipns := make(chan *gateway.MWalletCallback, 8)
+	verifier := newTestClient("", testSalt)
+
+	ipnServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var fields map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+			http.Error(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+
+		callback, err := verifier.ParseAndVerifyMWalletCallback(r.Context(), fields)
+		if err != nil {
+			ipns <- nil
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ipns <- callback
+		json.NewEncoder(w).Encode(verifier.MWalletCallbackAck("000", "Success"))
+	}))
+	t.Cleanup(ipnServer.Close)
+
+	cfg.MerchantID = testMerchantID
+	cfg.Password = testPassword
+	cfg.IntegritySalt = testSalt
+	cfg.IPNURL = ipnServer.URL
+
+	sim := New(cfg)
+	server := httptest.NewServer(sim.Router())
+	t.Cleanup(server.Close)
+
+	return sim, server, ipns
+}
+
+func newTestClient(baseURL, salt string) *gateway.JazzCashClient {
+	return gateway.NewJazzCashClient(
+		testMerchantID,
+		testPassword,
+		salt,
+		"1234",
+		"http://localhost/payment/jazzcash/card/callback",
+		baseURL,
+		MWalletPath,
+		CardPath,
+		InquiryPath,
+		WalletRefundPath,
+		CardRefundPath,
+	)
+}
+
+func mwalletRequest(txnRefNo, mobile string) gateway.MWalletInitiateRequest {
+	now := time.Now()
+	return gateway.MWalletInitiateRequest{
+		AmountPaisa:       "50000",
+		BillRefID:         "bill-" + txnRefNo,
+		TxnRefNo:          txnRefNo,
+		Description:       "Wallet top-up",
+		MobileNumber:      mobile,
+		CNICLast6:         "123456",
+		TxnDateTime:       now.Format("20060102150405"),
+		TxnExpiryDateTime: now.Add(time.Hour).Format("20060102150405"),
+	}
+}
+
+func waitForIPN(t *testing.T, ipns <-chan *gateway.MWalletCallback) *gateway.MWalletCallback {
+	t.Helper()
+
+	select {
+	case callback := <-ipns:
+		return callback
+	case <-time.After(5 * time.Second):
+		t.Fatal("no IPN arrived")
+		return nil
+	}
+}
+
+func TestMWalletTopUp(t *testing.T) {
+	tests := []struct {
+		name        string
+		scenario    Scenario
+		wantSubmit  gateway.Status
+		wantInquiry gateway.Status
+		wantIPN     gateway.Status
+	}{
+		{"success", ScenarioSuccess, gateway.StatusSuccess, gateway.StatusSuccess, gateway.StatusSuccess},
+		{"failure", ScenarioFailure, gateway.StatusFailed, gateway.StatusFailed, gateway.StatusFailed},
+		{"delayed", ScenarioDelayed, gateway.StatusPending, gateway.StatusPending, gateway.StatusSuccess},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, server, ipns := startSimulator(t, Config{
+				DefaultScenario: tt.scenario,
+				SettleDelay:     100 * time.Millisecond,
+			})
+			client := newTestClient(server.URL, testSalt)
+			ctx := context.Background()
+
+			submitted, err := client.SubmitMWallet(ctx, mwalletRequest("T"+tt.name, "03001234567"))
+			if err != nil {
+				t.Fatalf("SubmitMWallet() error = %v", err)
+			}
+			if submitted.Status != tt.wantSubmit {
+				t.Errorf("SubmitMWallet() status = %s (%s), want %s", submitted.Status, submitted.ResponseCode, tt.wantSubmit)
+			}
+			if submitted.RRN == "" {
+				t.Error("SubmitMWallet() returned no RRN")
+			}
+
+			inquiry, err := client.Inquiry(ctx, gateway.InquiryRequest{TxnRefNo: "T" + tt.name})
+			if err != nil {
+				t.Fatalf("Inquiry() error = %v", err)
+			}
+			if inquiry.Status != tt.wantInquiry {
+				t.Errorf("Inquiry() status = %s, want %s", inquiry.Status, tt.wantInquiry)
+			}
+			if inquiry.RRN != submitted.RRN {
+				t.Errorf("Inquiry() RRN = %q, want %q", inquiry.RRN, submitted.RRN)
+			}
+
+			callback := waitForIPN(t, ipns)
+			if callback == nil {
+				t.Fatal("IPN failed hash verification")
+			}
+			if callback.TxnRefNo != "T"+tt.name || callback.AmountPaisa != "50000" || callback.Status != tt.wantIPN {
+				t.Errorf("IPN = %s %s %s, want T%s 50000 %s", callback.TxnRefNo, callback.AmountPaisa, callback.Status, tt.name, tt.wantIPN)
+			}
+
+			// once the IPN is out the payment is final, and inquiry agrees
+			inquiry, err = client.Inquiry(ctx, gateway.InquiryRequest{TxnRefNo: "T" + tt.name})
+			if err != nil {
+				t.Fatalf("Inquiry() error = %v", err)
+			}
+			if inquiry.Status != tt.wantIPN {
+				t.Errorf("Inquiry() after IPN status = %s, want %s", inquiry.Status, tt.wantIPN)
+			}
+		})
+	}
+}
+
+func TestMWalletTimeoutIsFoundByInquiry(t *testing.T) {
+	_, server, _ := startSimulator(t, Config{
+		DefaultScenario: ScenarioTimeout,
+		HangDuration:    5 * time.Second,
+	})
+	client := newTestClient(server.URL, testSalt)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.SubmitMWallet(ctx, mwalletRequest("TTIMEOUT", "03001234567")); err == nil {
+		t.Fatal("SubmitMWallet() answered a call the simulator held open")
+	}
+
+	inquiry, err := client.Inquiry(context.Background(), gateway.InquiryRequest{TxnRefNo: "TTIMEOUT"})
+	if err != nil {
+		t.Fatalf("Inquiry() error = %v", err)
+	}
+	if inquiry.Status != gateway.StatusSuccess {
+		t.Errorf("Inquiry() status = %s, want %s", inquiry.Status, gateway.StatusSuccess)
+	}
+}
+
+func TestTamperedHashIPNIsRejected(t *testing.T) {
+	_, server, ipns := startSimulator(t, Config{DefaultScenario: ScenarioTamperedHash})
+	client := newTestClient(server.URL, testSalt)
+
+	if _, err := client.SubmitMWallet(context.Background(), mwalletRequest("TTAMPER", "03001234567")); err != nil {
+		t.Fatalf("SubmitMWallet() error = %v", err)
+	}
+
+	if callback := waitForIPN(t, ipns); callback != nil {
+		t.Errorf("IPN with a tampered hash was accepted: %+v", callback)
+	}
+}
+
+func TestScenarioRules(t *testing.T) {
+	sim, server, ipns := startSimulator(t, Config{})
+	client := newTestClient(server.URL, testSalt)
+
+	if err := sim.SetRule(Rule{Scenario: ScenarioFailure, MobileNumber: "03000000001"}); err != nil {
+		t.Fatalf("SetRule() error = %v", err)
+	}
+	if err := sim.SetRule(Rule{Scenario: ScenarioSuccess, TxnRefNo: "TOVERRIDE"}); err != nil {
+		t.Fatalf("SetRule() error = %v", err)
+	}
+
+	tests := []struct {
+		txnRefNo string
+		mobile   string
+		want     gateway.Status
+	}{
+		{"TDEFAULT", "03001234567", gateway.StatusSuccess},
+		{"TMOBILE", "03000000001", gateway.StatusFailed},
+		// a rule for the reference wins over one for the mobile number
+		{"TOVERRIDE", "03000000001", gateway.StatusSuccess},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.txnRefNo, func(t *testing.T) {
+			resp, err := client.SubmitMWallet(context.Background(), mwalletRequest(tt.txnRefNo, tt.mobile))
+			if err != nil {
+				t.Fatalf("SubmitMWallet() error = %v", err)
+			}
+			if resp.Status != tt.want {
+				t.Errorf("SubmitMWallet() status = %s, want %s", resp.Status, tt.want)
+			}
+			waitForIPN(t, ipns)
+		})
+	}
+}
+
+func TestWrongCredentialsAreRejected(t *testing.T) {
+	_, server, _ := startSimulator(t, Config{})
+	client := newTestClient(server.URL, "not-the-salt")
+
+	resp, err := client.SubmitMWallet(context.Background(), mwalletRequest("TBADHASH", "03001234567"))
+	if err != nil {
+		t.Fatalf("SubmitMWallet() error = %v", err)
+	}
+	if resp.ResponseCode != codeBadHash || resp.Status != gateway.StatusFailed {
+		t.Errorf("SubmitMWallet() = %s %s, want %s %s", resp.ResponseCode, resp.Status, codeBadHash, gateway.StatusFailed)
+	}
+}
+
+var hiddenInput = regexp.MustCompile(`<input type="hidden" name="([^"]*)" value="([^"]*)">`)
+
+func TestCardTopUp(t *testing.T) {
+	tests := []struct {
+		name     string
+		scenario Scenario
+		want     gateway.Status
+	}{
+		{"success", ScenarioSuccess, gateway.StatusSuccess},
+		{"failure", ScenarioFailure, gateway.StatusFailed},
+		{"delayed", ScenarioDelayed, gateway.StatusPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, server, _ := startSimulator(t, Config{DefaultScenario: tt.scenario})
+			client := newTestClient(server.URL, testSalt)
+			ctx := context.Background()
+
+			now := time.Now()
+			form, err := client.InitiateCard(ctx, gateway.CardInitiateRequest{
+				AmountPaisa:       "75000",
+				BillRefID:         "bill-card",
+				TxnRefNo:          "TCARD",
+				Description:       "Wallet top-up",
+				TxnDateTime:       now.Format("20060102150405"),
+				TxnExpiryDateTime: now.Add(time.Hour).Format("20060102150405"),
+			})
+			if err != nil {
+				t.Fatalf("InitiateCard() error = %v", err)
+			}
+
+			// the browser posts the form and is sent back with the callback
+			values := url.Values{}
+			for k, v := range form.Fields {
+				values.Set(k, v)
+			}
+			resp, err := http.PostForm(form.PostURL, values)
+			if err != nil {
+				t.Fatalf("posting the card form: %v", err)
+			}
+			defer resp.Body.Close()
+
+			page, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("reading the card page: %v", err)
+			}
+
+			fields := make(map[string]string)
+			for _, m := range hiddenInput.FindAllStringSubmatch(string(page), -1) {
+				fields[html.UnescapeString(m[1])] = html.UnescapeString(m[2])
+			}
+
+			signed := make(gateway.JazzCashFields, len(fields))
+			for k, v := range fields {
+				if k != gateway.FieldSecureHash {
+					signed[k] = v
+				}
+			}
+			if fields[gateway.FieldSecureHash] != gateway.SignJazzCashFields(testSalt, signed) {
+				t.Fatal("callback is not signed with the integrity salt")
+			}
+
+			callback, err := client.ParseAndVerifyCardCallback(ctx, fields)
+			if err != nil {
+				t.Fatalf("ParseAndVerifyCardCallback() error = %v", err)
+			}
+			if callback.TxnRefNo != "TCARD" || callback.Status != tt.want {
+				t.Errorf("callback = %s %s, want TCARD %s", callback.TxnRefNo, callback.Status, tt.want)
+			}
+		})
+	}
+}
@@ -1,28 +1,36 @@
 package payment
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/hash-walker/giki-wallet/internal/audit"
 	"github.com/hash-walker/giki-wallet/internal/auth"
 	"github.com/hash-walker/giki-wallet/internal/common"
 	commonerrors "github.com/hash-walker/giki-wallet/internal/common/errors"
 	"github.com/hash-walker/giki-wallet/internal/middleware"
+	"github.com/hash-walker/giki-wallet/internal/payment/gateway"
 	"github.com/hash-walker/giki-wallet/internal/wallet"
 )
 
 type Handler struct {
 	pService *Service
 	wService *wallet.Service
+	audit    *audit.Service
 }
 
-func NewHandler(pService *Service, wService *wallet.Service) *Handler {
+func NewHandler(pService *Service, wService *wallet.Service, audit *audit.Service) *Handler {
 	return &Handler{
 		pService: pService,
 		wService: wService,
+		audit:    audit,
 	}
 }
 
@@ -55,7 +63,9 @@ func (h *Handler) TopUp(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if response.PaymentMethod == PaymentMethodMWallet && response.Status == PaymentStatusPending {
-		go h.pService.startPollingForTransaction(response.TxnRefNo)
+		if err := h.pService.StartReconciliation(r.Context(), response.TxnRefNo); err != nil {
+			middleware.LogAppError(err, requestID)
+		}
 	}
 
 	switch response.Status {
@@ -91,7 +101,85 @@ func (h *Handler) CardCallBack(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	auditID, err := h.pService.LogCardCallbackAudit(r.Context(), r.Form)
+	h.completeCardCallback(w, r, gateway.ProviderJazzCash)
+}
+
+// EasypaisaCallBack handles both redirects of the Easypaisa hosted checkout:
+// the first carries an auth_token that is posted on to the confirm page, the
+// second carries the payment result.
+func (h *Handler) EasypaisaCallBack(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	err := r.ParseForm()
+	if err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidInput, err), requestID)
+		return
+	}
+
+	if authToken := r.Form.Get(gateway.EasypaisaFieldAuthToken); authToken != "" {
+		html, err := h.pService.ContinueEasypaisaCheckout(authToken)
+		if err != nil {
+			middleware.HandleError(w, err, requestID)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(html))
+		return
+	}
+
+	h.completeCardCallback(w, r, gateway.ProviderEasypaisa)
+}
+
+// MWalletCallBack receives JazzCash's server-to-server notification (IPN) for
+// MWallet payments, posted as JSON or as a form
+func (h *Handler) MWalletCallBack(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	fields := make(map[string]string)
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		// numbers are kept as sent so the hash still matches
+		decoder := json.NewDecoder(r.Body)
+		decoder.UseNumber()
+
+		var body map[string]any
+		if err := decoder.Decode(&body); err != nil {
+			middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidJSON, err), requestID)
+			return
+		}
+		for k, v := range body {
+			if v != nil {
+				fields[k] = fmt.Sprintf("%v", v)
+			}
+		}
+	} else {
+		if err := r.ParseForm(); err != nil {
+			middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidInput, err), requestID)
+			return
+		}
+		for k := range r.Form {
+			fields[k] = r.Form.Get(k)
+		}
+	}
+
+	ack, err := h.pService.HandleMWalletCallback(r.Context(), fields)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	// JazzCash reads the ack as bare pp_* fields, not the API envelope
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ack)
+}
+
+// completeCardCallback settles a card payment from a gateway's parsed
+// callback form and sends the browser back to the app
+func (h *Handler) completeCardCallback(w http.ResponseWriter, r *http.Request, provider gateway.Provider) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	auditID, err := h.pService.LogCardCallbackAudit(r.Context(), provider, r.Form)
 
 	if err != nil {
 		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInternal, err), requestID)
@@ -108,7 +196,7 @@ func (h *Handler) CardCallBack(w http.ResponseWriter, r *http.Request) {
 	}
 	defer tx.Rollback(r.Context())
 
-	result, err := h.pService.CompleteCardPayment(r.Context(), tx, r.Form, auditID)
+	result, err := h.pService.CompleteCardPayment(r.Context(), tx, provider, r.Form, auditID)
 
 	if err != nil {
 		middleware.LogAppError(err, requestID)
@@ -149,6 +237,101 @@ func (h *Handler) CheckStatus(w http.ResponseWriter, r *http.Request) {
 	common.ResponseWithJSON(w, http.StatusOK, result, requestID)
 }
 
+const (
+	statusStreamHeartbeat = 15 * time.Second
+	statusStreamMaxAge    = 10 * time.Minute
+)
+
+// StreamStatus pushes a top-up's status as server-sent events, so the page
+// waiting on a payment does not have to poll. The current status is sent
+// first and the stream ends after a final status, which is the page's cue to
+// close its EventSource. Streams cut short otherwise are reconnected by the
+// browser and start again from the stored status.
+func (h *Handler) StreamStatus(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+	txnRefNo := chi.URLParam(r, "txnRefNo")
+
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		middleware.HandleError(w, commonerrors.ErrUnauthorized, requestID)
+		return
+	}
+
+	if txnRefNo == "" {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidInput, nil), requestID)
+		return
+	}
+
+	// subscribe before reading so a transition in between is not lost
+	events, unsubscribe := h.pService.SubscribeStatus(txnRefNo)
+	defer unsubscribe()
+
+	current, err := h.pService.CurrentStatus(r.Context(), userID, txnRefNo)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// keep nginx from buffering the stream
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if err := writeStatusEvent(w, rc, *current); err != nil || isFinalStatus(current.Status) {
+		return
+	}
+
+	heartbeat := time.NewTicker(statusStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	maxAge := time.NewTimer(statusStreamMaxAge)
+	defer maxAge.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+
+		case <-maxAge.C:
+			return
+
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+
+		case event := <-events:
+			if err := writeStatusEvent(w, rc, event); err != nil || isFinalStatus(event.Status) {
+				return
+			}
+		}
+	}
+}
+
+func writeStatusEvent(w http.ResponseWriter, rc *http.ResponseController, event StatusEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "event: status\ndata: %s\n\n", data); err != nil {
+		return err
+	}
+
+	return rc.Flush()
+}
+
+func isFinalStatus(status PaymentStatus) bool {
+	return status == PaymentStatusSuccess || status == PaymentStatusFailed
+}
+
 // =============================================================================
 // ADMIN HANDLERS
 // =============================================================================
@@ -163,22 +346,23 @@ func (h *Handler) ListGatewayTransactions(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	txns, totalCount, totalAmount, err := h.pService.GetGatewayTransactions(r.Context(), params)
+	page, err := h.pService.GetGatewayTransactions(r.Context(), params)
 	if err != nil {
 		middleware.HandleError(w, err, requestID)
 		return
 	}
 
-	response := make([]AdminGatewayTransaction, len(txns))
-	for i, txn := range txns {
+	response := make([]AdminGatewayTransaction, len(page.Transactions))
+	for i, txn := range page.Transactions {
 		response[i] = AdminGatewayTransaction{
 			TxnRefNo:      txn.TxnRefNo,
 			UserID:        txn.UserID,
 			UserName:      txn.UserName,
 			UserEmail:     txn.UserEmail,
-			Amount:        fmt.Sprintf("%d", txn.Amount),
+			Amount:        common.Money(txn.Amount),
 			Status:        PaymentStatus(txn.Status),
 			PaymentMethod: PaymentMethod(txn.PaymentMethod),
+			Gateway:       txn.GatewayProvider,
 			CreatedAt:         txn.CreatedAt.Format(time.RFC3339),
 			UpdatedAt:         txn.UpdatedAt.Format(time.RFC3339),
 			BillRefID:         txn.BillRefID,
@@ -188,13 +372,20 @@ func (h *Handler) ListGatewayTransactions(w http.ResponseWriter, r *http.Request
 
 	}
 
-	common.ResponseWithJSON(w, http.StatusOK, map[string]interface{}{
-		"data":         response,
-		"total_count":  totalCount,
-		"total_amount": fmt.Sprintf("%d", totalAmount),
-		"page":         params.Page,
-		"page_size":    params.PageSize,
-	}, requestID)
+	body := map[string]interface{}{
+		"data":        response,
+		"page_size":   params.PageSize,
+		"next_cursor": page.NextCursor,
+		"has_more":    page.NextCursor != "",
+	}
+
+	// totals only come back with the first page
+	if page.TotalCount != nil {
+		body["total_count"] = *page.TotalCount
+		body["total_amount"] = common.Money(*page.TotalAmount)
+	}
+
+	common.ResponseWithJSON(w, http.StatusOK, body, requestID)
 }
 
 func (h *Handler) VerifyGatewayTransaction(w http.ResponseWriter, r *http.Request) {
@@ -302,3 +493,393 @@ func (h *Handler) GetTransactionAuditLogs(w http.ResponseWriter, r *http.Request
 
 	common.ResponseWithJSON(w, http.StatusOK, logs, requestID)
 }
+
+// =============================================================================
+// SETTLEMENT RECONCILIATION
+// =============================================================================
+
+// maxSettlementFileSize caps settlement uploads; a day's file is a few
+// thousand rows
+const maxSettlementFileSize = 10 << 20
+
+// AdminImportSettlement takes a multipart upload with the settlement CSV as
+// "file", the business day it covers as "settlement_date" (YYYY-MM-DD) and
+// optionally the "gateway", JAZZCASH by default.
+func (h *Handler) AdminImportSettlement(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	actorID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		middleware.HandleError(w, commonerrors.ErrUnauthorized, requestID)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxSettlementFileSize)
+	if err := r.ParseMultipartForm(maxSettlementFileSize); err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(ErrInvalidSettlementFile, err), requestID)
+		return
+	}
+
+	file, fileHeader, err := r.FormFile("file")
+	if err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(ErrInvalidSettlementFile, err), requestID)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(ErrInvalidSettlementFile, err), requestID)
+		return
+	}
+
+	provider := gateway.ProviderJazzCash
+	if v := r.FormValue("gateway"); v != "" {
+		provider = gateway.Provider(strings.ToUpper(v))
+	}
+
+	report, err := h.pService.ImportSettlement(r.Context(), provider, r.FormValue("settlement_date"), fileHeader.Filename, data, actorID)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	common.ResponseWithJSON(w, http.StatusCreated, report, requestID)
+}
+
+func (h *Handler) AdminListSettlements(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	var params common.PaginationParams
+	if err := params.Bind(r); err != nil {
+		appErr := commonerrors.New("INVALID_REQUEST", http.StatusBadRequest, err.Error())
+		middleware.HandleError(w, appErr, requestID)
+		return
+	}
+
+	settlements, total, err := h.pService.ListSettlements(r.Context(), params.Page, params.PageSize)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	response := map[string]interface{}{
+		"data": settlements,
+		"meta": map[string]interface{}{
+			"page":          params.Page,
+			"page_size":     params.PageSize,
+			"total_records": total,
+		},
+	}
+
+	common.ResponseWithJSON(w, http.StatusOK, response, requestID)
+}
+
+// AdminGetSettlement returns a settlement with its items; ?category= narrows
+// the items to MATCHED, MISSING_ON_OUR_SIDE, MISSING_AT_GATEWAY or AMOUNT_MISMATCH
+func (h *Handler) AdminGetSettlement(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	settlementID, err := uuid.Parse(chi.URLParam(r, "settlement_id"))
+	if err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidUUID, err), requestID)
+		return
+	}
+
+	report, err := h.pService.GetSettlementReport(r.Context(), settlementID, strings.ToUpper(r.URL.Query().Get("category")))
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	common.ResponseWithJSON(w, http.StatusOK, report, requestID)
+}
+
+func (h *Handler) AdminResolveSettlementItem(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	itemID, err := uuid.Parse(chi.URLParam(r, "item_id"))
+	if err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidUUID, err), requestID)
+		return
+	}
+
+	actorID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		middleware.HandleError(w, commonerrors.ErrUnauthorized, requestID)
+		return
+	}
+
+	var params ResolveSettlementItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidJSON, err), requestID)
+		return
+	}
+
+	item, err := h.pService.ResolveSettlementItem(r.Context(), itemID, actorID, params)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	common.ResponseWithJSON(w, http.StatusOK, item, requestID)
+}
+
+func (h *Handler) AdminListRefunds(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	var params common.PaginationParams
+	if err := params.Bind(r); err != nil {
+		appErr := commonerrors.New("INVALID_REQUEST", http.StatusBadRequest, err.Error())
+		middleware.HandleError(w, appErr, requestID)
+		return
+	}
+
+	refunds, total, err := h.pService.ListRefunds(r.Context(), r.URL.Query().Get("status"), params.Page, params.PageSize)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	response := map[string]interface{}{
+		"data": refunds,
+		"meta": map[string]interface{}{
+			"page":          params.Page,
+			"page_size":     params.PageSize,
+			"total_records": total,
+		},
+	}
+
+	common.ResponseWithJSON(w, http.StatusOK, response, requestID)
+}
+
+func (h *Handler) AdminRequestRefund(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	actorID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		middleware.HandleError(w, commonerrors.ErrUnauthorized, requestID)
+		return
+	}
+
+	var params RequestRefundRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidJSON, err), requestID)
+		return
+	}
+
+	refund, err := h.pService.RequestRefund(r.Context(), actorID, params)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	h.logAdminAction(r.Context(), r, audit.ActionAdminRequestRefund, &refund.UserID, map[string]interface{}{
+		"refund_id":        refund.ID,
+		"topup_txn_ref_no": refund.TopUpTxnRefNo,
+		"amount":           refund.Amount,
+		"reason":           refund.Reason,
+	})
+
+	common.ResponseWithJSON(w, http.StatusCreated, refund, requestID)
+}
+
+func (h *Handler) AdminApproveRefund(w http.ResponseWriter, r *http.Request) {
+	h.reviewRefund(w, r, true)
+}
+
+func (h *Handler) AdminRejectRefund(w http.ResponseWriter, r *http.Request) {
+	h.reviewRefund(w, r, false)
+}
+
+func (h *Handler) reviewRefund(w http.ResponseWriter, r *http.Request, approve bool) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	refundID, err := uuid.Parse(chi.URLParam(r, "refund_id"))
+	if err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidUUID, err), requestID)
+		return
+	}
+
+	actorID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		middleware.HandleError(w, commonerrors.ErrUnauthorized, requestID)
+		return
+	}
+
+	var params ReviewRefundRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidJSON, err), requestID)
+		return
+	}
+
+	var (
+		refund *GatewayRefund
+		action string
+	)
+
+	if approve {
+		action = audit.ActionAdminApproveRefund
+		refund, err = h.pService.ApproveRefund(r.Context(), refundID, actorID, params.Note)
+	} else {
+		action = audit.ActionAdminRejectRefund
+		refund, err = h.pService.RejectRefund(r.Context(), refundID, actorID, params.Note)
+	}
+
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	h.logAdminAction(r.Context(), r, action, &refund.UserID, map[string]interface{}{
+		"refund_id":         refund.ID,
+		"topup_txn_ref_no":  refund.TopUpTxnRefNo,
+		"refund_txn_ref_no": refund.RefundTxnRefNo,
+		"amount":            refund.Amount,
+		"status":            refund.Status,
+		"requested_by":      refund.RequestedBy,
+		"note":              refund.ReviewNote,
+	})
+
+	common.ResponseWithJSON(w, http.StatusOK, refund, requestID)
+}
+
+func (h *Handler) AdminListForceResolutions(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	var params common.PaginationParams
+	if err := params.Bind(r); err != nil {
+		appErr := commonerrors.New("INVALID_REQUEST", http.StatusBadRequest, err.Error())
+		middleware.HandleError(w, appErr, requestID)
+		return
+	}
+
+	resolutions, total, err := h.pService.ListForceResolutions(r.Context(), r.URL.Query().Get("status"), params.Page, params.PageSize)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	response := map[string]interface{}{
+		"data": resolutions,
+		"meta": map[string]interface{}{
+			"page":          params.Page,
+			"page_size":     params.PageSize,
+			"total_records": total,
+		},
+	}
+
+	common.ResponseWithJSON(w, http.StatusOK, response, requestID)
+}
+
+func (h *Handler) AdminRequestForceResolution(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+	txnRefNo := chi.URLParam(r, "txnRefNo")
+
+	actorID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		middleware.HandleError(w, commonerrors.ErrUnauthorized, requestID)
+		return
+	}
+
+	var params RequestForceResolutionRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidJSON, err), requestID)
+		return
+	}
+
+	res, err := h.pService.RequestForceResolution(r.Context(), txnRefNo, actorID, params)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	h.logAdminAction(r.Context(), r, audit.ActionAdminRequestForceResolution, &res.UserID, map[string]interface{}{
+		"resolution_id":  res.ID,
+		"txn_ref_no":     res.TxnRefNo,
+		"from_status":    res.FromStatus,
+		"target_status":  res.TargetStatus,
+		"reason":         res.Reason,
+		"evidence":       res.Evidence,
+		"bank_reference": res.BankReference,
+	})
+
+	common.ResponseWithJSON(w, http.StatusCreated, res, requestID)
+}
+
+func (h *Handler) AdminApproveForceResolution(w http.ResponseWriter, r *http.Request) {
+	h.reviewForceResolution(w, r, true)
+}
+
+func (h *Handler) AdminRejectForceResolution(w http.ResponseWriter, r *http.Request) {
+	h.reviewForceResolution(w, r, false)
+}
+
+func (h *Handler) reviewForceResolution(w http.ResponseWriter, r *http.Request, approve bool) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	resolutionID, err := uuid.Parse(chi.URLParam(r, "resolution_id"))
+	if err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidUUID, err), requestID)
+		return
+	}
+
+	actorID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		middleware.HandleError(w, commonerrors.ErrUnauthorized, requestID)
+		return
+	}
+
+	var params ReviewForceResolutionRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidJSON, err), requestID)
+		return
+	}
+
+	var (
+		res    *GatewayForceResolution
+		action string
+	)
+
+	if approve {
+		action = audit.ActionAdminApproveForceResolution
+		res, err = h.pService.ApproveForceResolution(r.Context(), resolutionID, actorID, params.Note)
+	} else {
+		action = audit.ActionAdminRejectForceResolution
+		res, err = h.pService.RejectForceResolution(r.Context(), resolutionID, actorID, params.Note)
+	}
+
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	h.logAdminAction(r.Context(), r, action, &res.UserID, map[string]interface{}{
+		"resolution_id": res.ID,
+		"txn_ref_no":    res.TxnRefNo,
+		"target_status": res.TargetStatus,
+		"amount":        res.Amount,
+		"requested_by":  res.RequestedBy,
+		"note":          res.ReviewNote,
+		"audit_id":      res.AuditID,
+	})
+
+	common.ResponseWithJSON(w, http.StatusOK, res, requestID)
+}
+
+// logAdminAction records an admin action in the system audit log
+func (h *Handler) logAdminAction(ctx context.Context, r *http.Request, action string, targetID *uuid.UUID, details map[string]interface{}) {
+	ip := common.GetClientIP(r)
+	userAgent := r.UserAgent()
+	actorID, _ := auth.GetUserIDFromContext(ctx)
+
+	_ = h.audit.LogSecurityEvent(ctx, audit.Event{
+		ActorID:   &actorID,
+		Action:    action,
+		TargetID:  targetID,
+		IPAddress: ip,
+		UserAgent: userAgent,
+		Status:    audit.StatusSuccess,
+		Details:   details,
+	})
+}
@@ -1,7 +1,10 @@
 package payment
 
 import (
+	"time"
+
 	"github.com/google/uuid"
+	"github.com/hash-walker/giki-wallet/internal/common"
 	"github.com/hash-walker/giki-wallet/internal/payment/gateway"
 	paymentdb "github.com/hash-walker/giki-wallet/internal/payment/payment_db"
 )
@@ -43,7 +46,7 @@ type TopUpResult struct {
 	PaymentPageURL string `json:"redirect,omitempty"`
 
 	// Useful for UI
-	Amount float64 `json:"amount,omitempty"`
+	Amount common.Money `json:"amount,omitempty"`
 }
 
 //type RedirectPayload struct {
@@ -66,7 +69,7 @@ func MapMWalletToTopUpResult(
 		Status:        GatewayStatusToPaymentStatus(mwResp.Status),
 		Message:       mwResp.Message,
 		PaymentMethod: PaymentMethod(existing.PaymentMethod),
-		Amount:        float64(existing.Amount),
+		Amount:        common.Money(existing.Amount),
 	}
 }
 
@@ -80,7 +83,7 @@ func MapInquiryToTopUpResult(
 		Status:        GatewayStatusToPaymentStatus(inquiryResult.Status),
 		Message:       inquiryResult.Message,
 		PaymentMethod: PaymentMethod(existing.PaymentMethod),
-		Amount:        float64(existing.Amount),
+		Amount:        common.Money(existing.Amount),
 	}
 }
 
@@ -94,7 +97,7 @@ func MapCardCallbackToTopUpResult(
 		Status:        GatewayStatusToPaymentStatus(callback.Status),
 		Message:       callback.Message,
 		PaymentMethod: PaymentMethod(existing.PaymentMethod),
-		Amount:        float64(existing.Amount),
+		Amount:        common.Money(existing.Amount),
 	}
 }
 
@@ -117,9 +120,10 @@ type AdminGatewayTransaction struct {
 	UserID        uuid.UUID     `json:"user_id"`
 	UserName      string        `json:"user_name"`
 	UserEmail     string        `json:"user_email"`
-	Amount        string        `json:"amount"` // BigInt as string to avoid precision loss in JS
+	Amount        common.Money  `json:"amount"`
 	Status        PaymentStatus `json:"status"`
 	PaymentMethod PaymentMethod `json:"payment_method"`
+	Gateway       string        `json:"gateway"`
 	CreatedAt     string        `json:"created_at"`
 	UpdatedAt         string        `json:"updated_at"`
 	BillRefID         string        `json:"bill_ref_id,omitempty"`
@@ -127,7 +131,185 @@ type AdminGatewayTransaction struct {
 	GatewayStatusCode string        `json:"gateway_status_code,omitempty"`
 }
 
+// GatewayTransactionPage is one keyset page of gateway transactions.
+// Totals are nil on every page after the first.
+type GatewayTransactionPage struct {
+	Transactions []paymentdb.GetGatewayTransactionsRow
+	NextCursor   string
+	TotalCount   *int64
+	TotalAmount  *int64
+}
+
+
+// ReconcileTransactionJob is one inquiry in a transaction's reconciliation round
+type ReconcileTransactionJob struct {
+	TxnRefNo string `json:"txn_ref_no"`
+	Attempt  int    `json:"attempt"`
+}
 
 type UpdateGatewayStatusRequest struct {
 	Status PaymentStatus `json:"status"`
 }
+
+// =============================================================================
+// SETTLEMENT RECONCILIATION
+// =============================================================================
+
+type SettlementCategory string
+
+const (
+	SettlementMatched          SettlementCategory = "MATCHED"
+	SettlementMissingOnOurSide SettlementCategory = "MISSING_ON_OUR_SIDE"
+	SettlementMissingAtGateway SettlementCategory = "MISSING_AT_GATEWAY"
+	SettlementAmountMismatch   SettlementCategory = "AMOUNT_MISMATCH"
+)
+
+type SettlementResolution string
+
+const (
+	// SettlementResolutionCorrected: our records were fixed, e.g. by verifying
+	// the transaction with the gateway
+	SettlementResolutionCorrected SettlementResolution = "CORRECTED"
+	// SettlementResolutionGatewayError: the settlement file is wrong and was
+	// raised with the gateway
+	SettlementResolutionGatewayError SettlementResolution = "GATEWAY_ERROR"
+	// SettlementResolutionAccepted: the difference was reviewed and accepted
+	SettlementResolutionAccepted SettlementResolution = "ACCEPTED"
+)
+
+// GatewaySettlement is one imported settlement file with its match counts
+type GatewaySettlement struct {
+	ID                  uuid.UUID `json:"id"`
+	Gateway             string    `json:"gateway"`
+	SettlementDate      string    `json:"settlement_date"`
+	FileName            string    `json:"file_name"`
+	RowCount            int32     `json:"row_count"`
+	MatchedCount        int32     `json:"matched_count"`
+	MissingOursCount    int32     `json:"missing_on_our_side_count"`
+	MissingGatewayCount int32     `json:"missing_at_gateway_count"`
+	MismatchCount       int32     `json:"amount_mismatch_count"`
+	UploadedBy          uuid.UUID `json:"uploaded_by"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+// GatewaySettlementItem is one transaction of a settlement and where it
+// stands on each side. Anything not matched carries the audit entry that
+// tracks its resolution.
+type GatewaySettlementItem struct {
+	ID            uuid.UUID          `json:"id"`
+	TxnRefNo      string             `json:"txn_ref_no"`
+	Category      SettlementCategory `json:"category"`
+	GatewayAmount *common.Money      `json:"gateway_amount"`
+	OurAmount     *common.Money      `json:"our_amount"`
+	LedgerAmount  *common.Money      `json:"ledger_amount"`
+	OurStatus     string             `json:"our_status,omitempty"`
+	AuditID       *uuid.UUID         `json:"audit_id,omitempty"`
+	Resolved      bool               `json:"resolved"`
+	ResolvedAt    *time.Time         `json:"resolved_at,omitempty"`
+}
+
+type GatewaySettlementReport struct {
+	Settlement GatewaySettlement       `json:"settlement"`
+	Items      []GatewaySettlementItem `json:"items"`
+}
+
+type ResolveSettlementItemRequest struct {
+	Resolution SettlementResolution `json:"resolution"`
+	Note       string               `json:"note"`
+}
+
+// =============================================================================
+// REFUNDS
+// =============================================================================
+
+// Kinds of gateway transaction: money coming in, or a refund going back out
+const (
+	GatewayTxnKindTopUp  = "TOPUP"
+	GatewayTxnKindRefund = "REFUND"
+)
+
+const (
+	RefundStatusPending    = "PENDING"
+	RefundStatusRejected   = "REJECTED"
+	RefundStatusProcessing = "PROCESSING"
+	RefundStatusCompleted  = "COMPLETED"
+	RefundStatusFailed     = "FAILED"
+)
+
+type RequestRefundRequest struct {
+	TxnRefNo string  `json:"txn_ref_no"` // the top-up to refund
+	Amount   float64 `json:"amount"`     // in Rupees
+	Reason   string  `json:"reason"`
+}
+
+type ReviewRefundRequest struct {
+	Note string `json:"note"`
+}
+
+type GatewayRefund struct {
+	ID              uuid.UUID    `json:"id"`
+	TopUpID         uuid.UUID    `json:"topup_id"`
+	TopUpTxnRefNo   string       `json:"topup_txn_ref_no,omitempty"`
+	UserID          uuid.UUID    `json:"user_id"`
+	UserName        string       `json:"user_name,omitempty"`
+	UserEmail       string       `json:"user_email,omitempty"`
+	Amount          common.Money `json:"amount"`
+	Reason          string       `json:"reason"`
+	Status          string       `json:"status"`
+	RequestedBy     uuid.UUID    `json:"requested_by"`
+	RequestedByName string       `json:"requested_by_name,omitempty"`
+	ReviewedBy      *uuid.UUID   `json:"reviewed_by,omitempty"`
+	ReviewedByName  *string      `json:"reviewed_by_name,omitempty"`
+	ReviewNote      string       `json:"review_note,omitempty"`
+	GatewayTxnID    *uuid.UUID   `json:"gateway_txn_id,omitempty"`
+	RefundTxnRefNo  string       `json:"refund_txn_ref_no,omitempty"`
+	TransactionID   *uuid.UUID   `json:"transaction_id,omitempty"`
+	CreatedAt       time.Time    `json:"created_at"`
+	ReviewedAt      *time.Time   `json:"reviewed_at,omitempty"`
+	CompletedAt     *time.Time   `json:"completed_at,omitempty"`
+}
+
+// =============================================================================
+// FORCE RESOLUTIONS
+// =============================================================================
+
+const (
+	ForceResolutionStatusPending  = "PENDING"
+	ForceResolutionStatusApproved = "APPROVED"
+	ForceResolutionStatusRejected = "REJECTED"
+)
+
+type RequestForceResolutionRequest struct {
+	Status        PaymentStatus `json:"status"` // SUCCESS or FAILED, as confirmed with the bank
+	Reason        string        `json:"reason"`
+	Evidence      string        `json:"evidence"`
+	BankReference string        `json:"bank_reference"`
+}
+
+type ReviewForceResolutionRequest struct {
+	Note string `json:"note"`
+}
+
+type GatewayForceResolution struct {
+	ID              uuid.UUID    `json:"id"`
+	GatewayTxnID    uuid.UUID    `json:"gateway_txn_id"`
+	TxnRefNo        string       `json:"txn_ref_no,omitempty"`
+	Kind            string       `json:"kind,omitempty"`
+	UserID          uuid.UUID    `json:"user_id,omitempty"`
+	UserName        string       `json:"user_name,omitempty"`
+	Amount          common.Money `json:"amount,omitempty"`
+	FromStatus      string       `json:"from_status"`
+	TargetStatus    string       `json:"target_status"`
+	Reason          string       `json:"reason"`
+	Evidence        string       `json:"evidence"`
+	BankReference   string       `json:"bank_reference,omitempty"`
+	Status          string       `json:"status"`
+	RequestedBy     uuid.UUID    `json:"requested_by"`
+	RequestedByName string       `json:"requested_by_name,omitempty"`
+	ReviewedBy      *uuid.UUID   `json:"reviewed_by,omitempty"`
+	ReviewedByName  *string      `json:"reviewed_by_name,omitempty"`
+	ReviewNote      string       `json:"review_note,omitempty"`
+	AuditID         *uuid.UUID   `json:"audit_id,omitempty"`
+	CreatedAt       time.Time    `json:"created_at"`
+	ReviewedAt      *time.Time   `json:"reviewed_at,omitempty"`
+}
@@ -0,0 +1,148 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/hash-walker/giki-wallet/internal/common"
+	commonerrors "github.com/hash-walker/giki-wallet/internal/common/errors"
+	"github.com/hash-walker/giki-wallet/internal/middleware"
+	"github.com/hash-walker/giki-wallet/internal/payment/gateway"
+	payment "github.com/hash-walker/giki-wallet/internal/payment/payment_db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// HandleMWalletCallback settles an MWallet top-up from JazzCash's signed IPN.
+// Every notification is logged as MWALLET_CALLBACK before it is checked. A
+// final result goes through finalizeTransaction, so a repeated or late IPN
+// cannot credit twice and never turns a settled SUCCESS into FAILED; anything
+// else leaves the transaction to reconciliation. It returns the signed ack
+// JazzCash expects.
+func (s *Service) HandleMWalletCallback(ctx context.Context, fields map[string]string) (gateway.JazzCashFields, error) {
+	gw, ok := s.gateways[gateway.ProviderJazzCash].(*gateway.JazzCashClient)
+	if !ok {
+		return nil, commonerrors.Wrap(ErrGatewayUnavailable, fmt.Errorf("gateway %s is not configured", gateway.ProviderJazzCash))
+	}
+
+	auditID, err := s.logMWalletCallbackAudit(ctx, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	callback, err := gw.ParseAndVerifyMWalletCallback(ctx, fields)
+	if err != nil {
+		s.MarkAuditFailed(ctx, auditID, err.Error())
+		return nil, commonerrors.Wrap(ErrCallbackRejected, err)
+	}
+
+	txn, err := s.q.GetTransactionByTxnRefNo(ctx, callback.TxnRefNo)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.MarkAuditFailed(ctx, auditID, "no gateway transaction matches the callback")
+			return nil, ErrTransactionNotFound
+		}
+		return nil, commonerrors.Wrap(ErrDatabaseQuery, err)
+	}
+
+	if err := checkMWalletCallback(txn, callback); err != nil {
+		s.MarkAuditFailed(ctx, auditID, err.Error())
+		return nil, err
+	}
+
+	status := GatewayStatusToPaymentStatus(callback.Status)
+
+	switch {
+	case status == PaymentStatusFailed && txn.Status == payment.CurrentStatusSUCCESS:
+		// the payment was already confirmed; a late failure does not undo it
+		s.MarkAuditFailed(ctx, auditID, fmt.Sprintf("callback reports FAILED (%s) for a settled SUCCESS", callback.ResponseCode))
+
+	case status == PaymentStatusSuccess || status == PaymentStatusFailed:
+		done, finalizeErr := s.finalizeTransaction(ctx, txn.TxnRefNo, &gateway.InquiryResponse{
+			Status:       callback.Status,
+			ResponseCode: callback.ResponseCode,
+			Message:      callback.Message,
+			RRN:          callback.RRN,
+			Raw:          callback.Raw,
+		})
+
+		if !done || finalizeErr != nil {
+			// the IPN is on record; reconciliation retries the credit
+			s.MarkAuditFailed(ctx, auditID, fmt.Sprintf("finalize failed: %v", finalizeErr))
+			if err := s.StartReconciliation(ctx, txn.TxnRefNo); err != nil {
+				middleware.LogAppError(err, "mwallet-callback-"+txn.TxnRefNo)
+			}
+			break
+		}
+
+		if err := s.q.MarkAuditsProcessedByTxn(ctx, common.StringToText(txn.TxnRefNo)); err != nil {
+			middleware.LogAppError(commonerrors.Wrap(ErrDatabaseQuery, err), "mwallet-callback-"+txn.TxnRefNo)
+		}
+
+	default:
+		// still pending at JazzCash; keep asking until it is final
+		if err := s.StartReconciliation(ctx, txn.TxnRefNo); err != nil {
+			middleware.LogAppError(err, "mwallet-callback-"+txn.TxnRefNo)
+		}
+	}
+
+	return gw.MWalletCallbackAck("000", "IPN received"), nil
+}
+
+// logMWalletCallbackAudit logs the raw IPN before it is verified
+func (s *Service) logMWalletCallbackAudit(ctx context.Context, fields map[string]string) (uuid.UUID, error) {
+	rawPayload, err := json.Marshal(fields)
+	if err != nil {
+		return uuid.Nil, commonerrors.Wrap(commonerrors.ErrInvalidJSON, err)
+	}
+
+	txnRefNo := fields[gateway.FieldTxnRefNo]
+	gatewayRef := fields["pp_RetreivalReferenceNo"]
+
+	auditLog, err := s.q.CreateAuditLog(ctx, payment.CreateAuditLogParams{
+		EventType:  payment.GikiWalletAuditEventTypeMWALLETCALLBACK,
+		RawPayload: rawPayload,
+		TxnRefNo:   pgtype.Text{String: txnRefNo, Valid: txnRefNo != ""},
+		GatewayRef: pgtype.Text{String: gatewayRef, Valid: gatewayRef != ""},
+		UserID:     pgtype.UUID{},
+	})
+	if err != nil {
+		return uuid.Nil, commonerrors.Wrap(commonerrors.ErrDatabase, fmt.Errorf("failed to create audit log: %w", err))
+	}
+
+	return auditLog.ID, nil
+}
+
+// checkMWalletCallback makes sure a verified IPN is about a JazzCash MWallet
+// top-up and, when it reports success, for the amount we asked for
+func checkMWalletCallback(txn payment.GikiWalletGatewayTransaction, callback *gateway.MWalletCallback) error {
+	if txn.GatewayProvider != string(gateway.ProviderJazzCash) ||
+		txn.Kind != GatewayTxnKindTopUp ||
+		PaymentMethod(txn.PaymentMethod) != PaymentMethodMWallet {
+		return commonerrors.Wrap(ErrCallbackRejected, fmt.Errorf("MWallet callback for %s %s %s transaction %s", txn.GatewayProvider, txn.PaymentMethod, txn.Kind, txn.TxnRefNo))
+	}
+
+	if callback.Status == gateway.StatusSuccess && callback.AmountPaisa != AmountToPaisa(txn.Amount) {
+		return commonerrors.Wrap(ErrCallbackRejected, fmt.Errorf("callback amount %s does not match %d for %s", callback.AmountPaisa, txn.Amount, txn.TxnRefNo))
+	}
+
+	return nil
+}
+
+// checkCardCallback makes sure a card callback belongs to a top-up its own
+// gateway started and, where the gateway reports one, is for the amount that
+// top-up asked for
+func checkCardCallback(provider gateway.Provider, txn payment.GikiWalletGatewayTransaction, callback *gateway.CardCallback) error {
+	if txn.GatewayProvider != string(provider) || txn.Kind != GatewayTxnKindTopUp {
+		return commonerrors.Wrap(ErrCallbackRejected, fmt.Errorf("%s card callback for %s %s transaction %s", provider, txn.GatewayProvider, txn.Kind, txn.TxnRefNo))
+	}
+
+	if callback.Status == gateway.StatusSuccess && callback.AmountPaisa != "" && callback.AmountPaisa != AmountToPaisa(txn.Amount) {
+		return commonerrors.Wrap(ErrCallbackRejected, fmt.Errorf("callback amount %s does not match %d for %s", callback.AmountPaisa, txn.Amount, txn.TxnRefNo))
+	}
+
+	return nil
+}
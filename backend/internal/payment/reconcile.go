@@ -0,0 +1,335 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hash-walker/giki-wallet/internal/common"
+	commonerrors "github.com/hash-walker/giki-wallet/internal/common/errors"
+	"github.com/hash-walker/giki-wallet/internal/middleware"
+	"github.com/hash-walker/giki-wallet/internal/payment/gateway"
+	payment "github.com/hash-walker/giki-wallet/internal/payment/payment_db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	JobReconciliationSweep  = "PAYMENT_RECONCILIATION_SWEEP"
+	JobReconcileTransaction = "PAYMENT_RECONCILE_TRANSACTION"
+
+	reconcileSweepInterval = 5 * time.Minute
+
+	// reconcileStaleAfter is how long an unsettled transaction or unprocessed
+	// callback is left to the request that created it before the sweep steps in
+	reconcileStaleAfter = 5 * time.Minute
+
+	// reconcileLeaseTimeout is how long a polling lock may be held before its
+	// round is taken to have died with the process running it
+	reconcileLeaseTimeout = 30 * time.Minute
+
+	// reconcileExpiry is when a transaction the gateway never settled is
+	// failed; top-ups are sent with a 24 hour expiry
+	reconcileExpiry = 25 * time.Hour
+
+	// a round makes reconcileRoundAttempts inquiries, starting
+	// reconcileFirstDelay apart and doubling up to reconcileMaxDelay, before
+	// handing the transaction back to the sweep
+	reconcileRoundAttempts = 10
+	reconcileFirstDelay    = 2 * time.Second
+	reconcileMaxDelay      = time.Minute
+
+	reconcileBatchSize = 100
+
+	// auditMaxRetries is how many rounds an unprocessed callback gets before
+	// the sweep leaves it for finance
+	auditMaxRetries = 10
+)
+
+// RegisterReconciliationJobs hands reconciliation to the worker and runs a
+// sweep right away, so anything a crash left behind is picked up on start.
+func (s *Service) RegisterReconciliationJobs(ctx context.Context) error {
+	s.worker.RegisterHandler(JobReconciliationSweep, s.handleReconciliationSweep)
+	s.worker.RegisterHandler(JobReconcileTransaction, s.handleReconcileTransaction)
+
+	return s.worker.EnsureScheduled(ctx, JobReconciliationSweep, struct{}{}, time.Now())
+}
+
+// StartReconciliation takes the transaction's polling lock and queues the
+// first inquiry of a round. It is a no-op while another round holds the lock.
+func (s *Service) StartReconciliation(ctx context.Context, txnRefNo string) error {
+	_, err := s.q.UpdatePollingStatus(ctx, txnRefNo)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return commonerrors.Wrap(ErrDatabaseQuery, err)
+	}
+
+	err = s.worker.EnqueueIn(ctx, JobReconcileTransaction, ReconcileTransactionJob{TxnRefNo: txnRefNo}, reconcileFirstDelay)
+	if err != nil {
+		s.endReconciliation(ctx, txnRefNo)
+		return commonerrors.Wrap(ErrInternal, err)
+	}
+
+	return nil
+}
+
+// handleReconciliationSweep releases polling locks lost in a crash and starts
+// rounds for old unsettled transactions and for callbacks that were never
+// processed, then schedules the next sweep.
+func (s *Service) handleReconciliationSweep(ctx context.Context, _ json.RawMessage) error {
+	now := time.Now()
+
+	if err := s.worker.EnsureScheduled(ctx, JobReconciliationSweep, struct{}{}, now.Add(reconcileSweepInterval)); err != nil {
+		return err
+	}
+
+	recovered, err := s.q.RecoverStalePolling(ctx, now.Add(-reconcileLeaseTimeout))
+	if err != nil {
+		return commonerrors.Wrap(ErrDatabaseQuery, err)
+	}
+
+	stale, err := s.q.GetStaleUnsettledTransactions(ctx, payment.GetStaleUnsettledTransactionsParams{
+		CreatedBefore: now.Add(-reconcileStaleAfter),
+		Limit:         reconcileBatchSize,
+	})
+	if err != nil {
+		return commonerrors.Wrap(ErrDatabaseQuery, err)
+	}
+
+	for _, txn := range stale {
+		if err := s.StartReconciliation(ctx, txn.TxnRefNo); err != nil {
+			return err
+		}
+	}
+
+	audits := 0
+	for _, eventType := range []payment.GikiWalletAuditEventType{
+		payment.GikiWalletAuditEventTypeCARDCALLBACK,
+		payment.GikiWalletAuditEventTypeMWALLETCALLBACK,
+	} {
+		n, err := s.sweepUnprocessedAudits(ctx, eventType, now)
+		if err != nil {
+			return err
+		}
+		audits += n
+	}
+
+	log.Printf("[Reconciliation] released %d stale polling locks, queued %d unsettled transactions and %d unprocessed callbacks",
+		len(recovered), len(stale), audits)
+	return nil
+}
+
+// sweepUnprocessedAudits starts a round for the transaction behind each old
+// unprocessed callback; the round marks the callback processed once the
+// transaction is final
+func (s *Service) sweepUnprocessedAudits(ctx context.Context, eventType payment.GikiWalletAuditEventType, now time.Time) (int, error) {
+	audits, err := s.q.GetUnprocessedAudits(ctx, payment.GetUnprocessedAuditsParams{
+		EventType:      eventType,
+		ReceivedBefore: now.Add(-reconcileStaleAfter),
+		MaxRetries:     auditMaxRetries,
+		Limit:          reconcileBatchSize,
+	})
+	if err != nil {
+		return 0, commonerrors.Wrap(ErrDatabaseQuery, err)
+	}
+
+	for _, audit := range audits {
+		if !audit.TxnRefNo.Valid || audit.TxnRefNo.String == "" {
+			s.MarkAuditFailed(ctx, audit.ID, "callback carries no transaction reference")
+			continue
+		}
+
+		_, err := s.q.GetTransactionByTxnRefNo(ctx, audit.TxnRefNo.String)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				s.MarkAuditFailed(ctx, audit.ID, "no gateway transaction matches the callback")
+				continue
+			}
+			return 0, commonerrors.Wrap(ErrDatabaseQuery, err)
+		}
+
+		if err := s.StartReconciliation(ctx, audit.TxnRefNo.String); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(audits), nil
+}
+
+// handleReconcileTransaction makes one inquiry. A final answer settles the
+// transaction and its callbacks; otherwise the next inquiry is queued with
+// backoff until the round runs out or the transaction expires.
+func (s *Service) handleReconcileTransaction(ctx context.Context, payload json.RawMessage) error {
+	var job ReconcileTransactionJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return err
+	}
+
+	txn, err := s.q.GetTransactionByTxnRefNo(ctx, job.TxnRefNo)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return commonerrors.Wrap(ErrDatabaseQuery, err)
+	}
+
+	if txn.Kind != GatewayTxnKindTopUp {
+		// refunds are settled through their refund request, not by inquiry
+		s.endReconciliation(ctx, job.TxnRefNo)
+		return nil
+	}
+
+	if txn.Status == payment.CurrentStatusSUCCESS || txn.Status == payment.CurrentStatusFAILED {
+		// settled by a callback or the top-up request while this was queued
+		s.endReconciliation(ctx, job.TxnRefNo)
+		if err := s.q.MarkAuditsProcessedByTxn(ctx, common.StringToText(job.TxnRefNo)); err != nil {
+			return commonerrors.Wrap(ErrDatabaseQuery, err)
+		}
+		return nil
+	}
+
+	gw, err := s.gatewayFor(txn)
+	if err != nil {
+		// the provider was removed from this deployment; nothing to ask
+		middleware.LogAppError(err, "reconcile-"+job.TxnRefNo)
+		s.endReconciliation(ctx, job.TxnRefNo)
+		return nil
+	}
+
+	inquiry, err := s.inquire(ctx, gw, job.TxnRefNo)
+	if err != nil {
+		middleware.LogAppError(commonerrors.Wrap(commonerrors.ErrExternalService, fmt.Errorf("inquiry failed (will retry): %w", err)), "reconcile-"+job.TxnRefNo)
+	} else if inquiry.Status == gateway.StatusSuccess || inquiry.Status == gateway.StatusFailed {
+		done, finalizeErr := s.finalizeTransaction(ctx, job.TxnRefNo, inquiry)
+		if done {
+			if finalizeErr != nil {
+				// final status is stored but the wallet credit failed;
+				// failing the job retries it
+				return finalizeErr
+			}
+
+			if err := s.q.MarkAuditsProcessedByTxn(ctx, common.StringToText(job.TxnRefNo)); err != nil {
+				return commonerrors.Wrap(ErrDatabaseQuery, err)
+			}
+			return nil
+		}
+	}
+
+	if time.Since(txn.CreatedAt) > reconcileExpiry {
+		return s.expireTransaction(ctx, job.TxnRefNo)
+	}
+
+	next := job.Attempt + 1
+	if next >= reconcileRoundAttempts {
+		// the sweep starts a new round later
+		s.markAuditsUnsettled(ctx, job.TxnRefNo, fmt.Sprintf("no final status after %d inquiries", next))
+		s.endReconciliation(ctx, job.TxnRefNo)
+		return nil
+	}
+
+	return s.worker.EnqueueIn(ctx, JobReconcileTransaction, ReconcileTransactionJob{
+		TxnRefNo: job.TxnRefNo,
+		Attempt:  next,
+	}, reconcileBackoff(next))
+}
+
+// inquire asks the gateway for a transaction's status, sharing the inquiry
+// rate limit with every other caller
+func (s *Service) inquire(ctx context.Context, gw gateway.Gateway, txnRefNo string) (*gateway.InquiryResponse, error) {
+	if err := s.rateLimiter.Acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer s.rateLimiter.Release()
+
+	return gw.Inquiry(ctx, gateway.InquiryRequest{TxnRefNo: txnRefNo})
+}
+
+// expireTransaction fails a transaction the gateway never settled. The row is
+// locked and checked again first: a callback may have settled it since this
+// round read it, and a settled transaction is left as it is.
+func (s *Service) expireTransaction(ctx context.Context, txnRefNo string) error {
+	expired := false
+
+	err := common.WithTransaction(ctx, s.dbPool, func(tx pgx.Tx) error {
+		paymentQ := s.q.WithTx(tx)
+
+		txn, err := paymentQ.GetTransactionByTxnRefNoForUpdate(ctx, txnRefNo)
+		if err != nil {
+			return err
+		}
+
+		if txn.Status != payment.CurrentStatusPENDING && txn.Status != payment.CurrentStatusUNKNOWN {
+			if err := paymentQ.ClearPollingStatus(ctx, txnRefNo); err != nil {
+				return err
+			}
+			return paymentQ.MarkAuditsProcessedByTxn(ctx, common.StringToText(txnRefNo))
+		}
+
+		err = paymentQ.UpdateGatewayTransactionStatus(ctx, payment.UpdateGatewayTransactionStatusParams{
+			Status:            payment.CurrentStatus(PaymentStatusFailed),
+			TxnRefNo:          txnRefNo,
+			GatewayMessage:    pgtype.Text{String: "No final status from the gateway before the transaction expired", Valid: true},
+			GatewayStatusCode: pgtype.Text{String: "EXPIRED", Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := paymentQ.ClearPollingStatus(ctx, txnRefNo); err != nil {
+			return err
+		}
+
+		if err := paymentQ.MarkAuditsFailedByTxn(ctx, payment.MarkAuditsFailedByTxnParams{
+			TxnRefNo:     common.StringToText(txnRefNo),
+			ProcessError: common.StringToText("transaction expired without a final gateway status"),
+		}); err != nil {
+			return err
+		}
+
+		expired = true
+		return s.notifyStatus(ctx, paymentQ, txnRefNo, PaymentStatusFailed, "Transaction expired")
+	})
+	if err != nil {
+		return commonerrors.Wrap(ErrTransactionUpdate, err)
+	}
+
+	if !expired {
+		// settled while this round was running
+		return nil
+	}
+
+	log.Printf("[Reconciliation] %s expired without a final gateway status", txnRefNo)
+	return nil
+}
+
+// endReconciliation releases the polling lock so a later sweep can start a new round
+func (s *Service) endReconciliation(ctx context.Context, txnRefNo string) {
+	if err := s.q.ClearPollingStatus(ctx, txnRefNo); err != nil {
+		middleware.LogAppError(commonerrors.Wrap(commonerrors.ErrDatabase, fmt.Errorf("failed to clear polling status: %w", err)), "reconcile-"+txnRefNo)
+	}
+}
+
+func (s *Service) markAuditsUnsettled(ctx context.Context, txnRefNo string, reason string) {
+	err := s.q.MarkAuditsFailedByTxn(ctx, payment.MarkAuditsFailedByTxnParams{
+		TxnRefNo:     common.StringToText(txnRefNo),
+		ProcessError: common.StringToText(reason),
+	})
+	if err != nil {
+		middleware.LogAppError(commonerrors.Wrap(commonerrors.ErrDatabase, fmt.Errorf("failed to mark callbacks unsettled: %w", err)), "reconcile-"+txnRefNo)
+	}
+}
+
+// reconcileBackoff is the wait before the given attempt of a round
+func reconcileBackoff(attempt int) time.Duration {
+	delay := reconcileFirstDelay
+	for i := 0; i < attempt && delay < reconcileMaxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, reconcileMaxDelay)
+}
@@ -0,0 +1,41 @@
+package payment
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReconcileBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{-1, 2 * time.Second},
+		{0, 2 * time.Second},
+		{1, 4 * time.Second},
+		{2, 8 * time.Second},
+		{3, 16 * time.Second},
+		{4, 32 * time.Second},
+		{5, time.Minute},
+		{reconcileRoundAttempts - 1, time.Minute},
+		{1000, time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := reconcileBackoff(tt.attempt); got != tt.want {
+			t.Errorf("reconcileBackoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestReconcileRoundFitsLease(t *testing.T) {
+	// a round that outlives the lease would let a second worker start another
+	var total time.Duration
+	for attempt := 0; attempt < reconcileRoundAttempts; attempt++ {
+		total += reconcileBackoff(attempt)
+	}
+
+	if total >= reconcileLeaseTimeout {
+		t.Errorf("a full round waits %v, longer than the %v lease", total, reconcileLeaseTimeout)
+	}
+}
@@ -0,0 +1,422 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hash-walker/giki-wallet/internal/common"
+	commonerrors "github.com/hash-walker/giki-wallet/internal/common/errors"
+	"github.com/hash-walker/giki-wallet/internal/middleware"
+	"github.com/hash-walker/giki-wallet/internal/payment/gateway"
+	payment "github.com/hash-walker/giki-wallet/internal/payment/payment_db"
+	"github.com/hash-walker/giki-wallet/internal/wallet"
+	"github.com/jackc/pgx/v5"
+)
+
+// RequestRefund records a pending refund of a successful top-up. Nothing
+// leaves the user's wallet until a different admin approves it.
+func (s *Service) RequestRefund(ctx context.Context, requestedBy uuid.UUID, req RequestRefundRequest) (*GatewayRefund, error) {
+	amount := int64(common.AmountToLowestUnit(req.Amount))
+	reason := strings.TrimSpace(req.Reason)
+	if amount <= 0 || reason == "" {
+		return nil, ErrInvalidRefund
+	}
+
+	topUp, err := s.q.GetTransactionByTxnRefNo(ctx, strings.TrimSpace(req.TxnRefNo))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, commonerrors.Wrap(ErrDatabaseQuery, err)
+	}
+
+	if topUp.Kind != GatewayTxnKindTopUp || topUp.Status != payment.CurrentStatusSUCCESS {
+		return nil, ErrRefundNotAllowed
+	}
+
+	if _, err := s.gatewayFor(topUp); err != nil {
+		return nil, err
+	}
+
+	refunded, err := s.q.GetRefundedAmount(ctx, topUp.ID)
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrDatabaseQuery, err)
+	}
+
+	if refunded+amount > topUp.Amount {
+		return nil, commonerrors.Wrap(ErrRefundExceedsPayment, fmt.Errorf("top-up %s: paid %d, refunded %d, requested %d", topUp.TxnRefNo, topUp.Amount, refunded, amount))
+	}
+
+	refund, err := s.q.CreateGatewayRefund(ctx, payment.CreateGatewayRefundParams{
+		TopupID:     topUp.ID,
+		UserID:      topUp.UserID,
+		Amount:      amount,
+		Reason:      reason,
+		RequestedBy: requestedBy,
+	})
+	if err != nil {
+		if CheckUniqueConstraintViolation(err) {
+			return nil, ErrRefundInProgress
+		}
+		return nil, commonerrors.Wrap(ErrDatabaseQuery, err)
+	}
+
+	result := mapDBRefund(refund)
+	result.TopUpTxnRefNo = topUp.TxnRefNo
+	return result, nil
+}
+
+// ApproveRefund holds the amount in the user's wallet, records the refund as
+// a REFUND gateway transaction and sends it to the gateway the top-up came
+// in on. The ledger is only debited once the gateway confirms; a refund the
+// gateway has not answered for stays PROCESSING with its funds held.
+func (s *Service) ApproveRefund(ctx context.Context, refundID, approverID uuid.UUID, note string) (*GatewayRefund, error) {
+	var (
+		refund    payment.GikiWalletGatewayRefund
+		topUp     payment.GikiWalletGatewayTransaction
+		refundTxn payment.GikiWalletGatewayTransaction
+		gw        gateway.Gateway
+	)
+
+	err := common.WithTransaction(ctx, s.dbPool, func(tx pgx.Tx) error {
+		paymentQ := s.q.WithTx(tx)
+
+		var err error
+		refund, err = s.lockRefund(ctx, paymentQ, refundID, RefundStatusPending)
+		if err != nil {
+			return err
+		}
+
+		if refund.RequestedBy == approverID {
+			return ErrSelfApproval
+		}
+
+		topUp, err = paymentQ.GetGatewayTransactionByID(ctx, refund.TopupID)
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabaseQuery, err)
+		}
+
+		gw, err = s.gatewayFor(topUp)
+		if err != nil {
+			return err
+		}
+
+		txnRefNo, err := GenerateTxnRefNo()
+		if err != nil {
+			return commonerrors.Wrap(ErrInternal, err)
+		}
+
+		refundTxn, err = paymentQ.CreateRefundGatewayTransaction(ctx, payment.CreateRefundGatewayTransactionParams{
+			UserID:          refund.UserID,
+			IdempotencyKey:  refund.ID,
+			BillRefID:       topUp.BillRefID,
+			TxnRefNo:        txnRefNo,
+			PaymentMethod:   topUp.PaymentMethod,
+			Status:          payment.CurrentStatusPENDING,
+			Amount:          refund.Amount,
+			GatewayProvider: topUp.GatewayProvider,
+			RefundOfID:      common.GoogleUUIDtoPgUUID(topUp.ID, true),
+		})
+		if err != nil {
+			return commonerrors.Wrap(ErrTransactionCreation, err)
+		}
+
+		userWallet, err := s.walletS.GetOrCreateWallet(ctx, tx, refund.UserID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.walletS.ReserveFunds(
+			ctx,
+			tx,
+			userWallet.ID,
+			refund.Amount,
+			wallet.TransactionTypeGatewayRefund,
+			refundTxn.TxnRefNo,
+			fmt.Sprintf("Refund of top-up %s", topUp.TxnRefNo),
+			// held until settleRefund captures or releases it, however long
+			// the gateway takes to answer
+			time.Time{},
+		); err != nil {
+			return err
+		}
+
+		refund, err = paymentQ.ReviewGatewayRefund(ctx, payment.ReviewGatewayRefundParams{
+			ID:           refund.ID,
+			Status:       RefundStatusProcessing,
+			ReviewedBy:   common.GoogleUUIDtoPgUUID(approverID, true),
+			ReviewNote:   common.StringToText(strings.TrimSpace(note)),
+			GatewayTxnID: common.GoogleUUIDtoPgUUID(refundTxn.ID, true),
+		})
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabaseQuery, err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	// External API call - no transaction held
+	resp, err := s.sendRefund(ctx, gw, topUp, refund.Amount)
+	if err != nil {
+		middleware.LogAppError(fmt.Errorf("refund %s left in flight: %w", refundTxn.TxnRefNo, err), "refund-"+refund.ID.String())
+		s.markRefundUnsettled(ctx, refundTxn.TxnRefNo, err.Error())
+		return s.refundResult(refund, topUp, refundTxn), nil
+	}
+
+	status := GatewayStatusToPaymentStatus(resp.Status)
+	if status != PaymentStatusSuccess && status != PaymentStatusFailed {
+		s.markRefundUnsettled(ctx, refundTxn.TxnRefNo, resp.Message)
+		return s.refundResult(refund, topUp, refundTxn), nil
+	}
+
+	err = common.WithTransaction(ctx, s.dbPool, func(tx pgx.Tx) error {
+		locked, err := s.lockRefund(ctx, s.q.WithTx(tx), refund.ID, RefundStatusProcessing)
+		if err != nil {
+			return err
+		}
+
+		refund, err = s.settleRefund(ctx, tx, locked, refundTxn.TxnRefNo, status, resp.Message, resp.ResponseCode)
+		return err
+	})
+
+	if err != nil {
+		// the gateway has answered but the refund could not be settled; it
+		// stays PROCESSING for finance to resolve
+		middleware.LogAppError(fmt.Errorf("refund %s answered %s but was not settled: %w", refundTxn.TxnRefNo, status, err), "refund-"+refund.ID.String())
+		return nil, err
+	}
+
+	return s.refundResult(refund, topUp, refundTxn), nil
+}
+
+// RejectRefund closes a pending refund without touching the wallet or the gateway
+func (s *Service) RejectRefund(ctx context.Context, refundID, reviewerID uuid.UUID, note string) (*GatewayRefund, error) {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return nil, ErrReasonRequired
+	}
+
+	var result *GatewayRefund
+
+	err := common.WithTransaction(ctx, s.dbPool, func(tx pgx.Tx) error {
+		paymentQ := s.q.WithTx(tx)
+
+		refund, err := s.lockRefund(ctx, paymentQ, refundID, RefundStatusPending)
+		if err != nil {
+			return err
+		}
+
+		if refund.RequestedBy == reviewerID {
+			return ErrSelfApproval
+		}
+
+		rejected, err := paymentQ.ReviewGatewayRefund(ctx, payment.ReviewGatewayRefundParams{
+			ID:         refund.ID,
+			Status:     RefundStatusRejected,
+			ReviewedBy: common.GoogleUUIDtoPgUUID(reviewerID, true),
+			ReviewNote: common.StringToText(note),
+		})
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabaseQuery, err)
+		}
+
+		result = mapDBRefund(rejected)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *Service) ListRefunds(ctx context.Context, status string, page, pageSize int) ([]GatewayRefund, int64, error) {
+	rows, err := s.q.ListGatewayRefunds(ctx, payment.ListGatewayRefundsParams{
+		Status: strings.ToUpper(status),
+		Limit:  int32(pageSize),
+		Offset: int32((page - 1) * pageSize),
+	})
+	if err != nil {
+		return nil, 0, commonerrors.Wrap(ErrDatabaseQuery, err)
+	}
+
+	var totalCount int64
+	items := make([]GatewayRefund, 0, len(rows))
+	for _, r := range rows {
+		totalCount = r.TotalCount
+		items = append(items, GatewayRefund{
+			ID:              r.ID,
+			TopUpID:         r.TopupID,
+			TopUpTxnRefNo:   r.TopupTxnRefNo,
+			UserID:          r.UserID,
+			UserName:        r.UserName,
+			UserEmail:       r.UserEmail,
+			Amount:          common.Money(r.Amount),
+			Reason:          r.Reason,
+			Status:          r.Status,
+			RequestedBy:     r.RequestedBy,
+			RequestedByName: r.RequestedByName,
+			ReviewedBy:      common.PgUUIDToUUIDPointer(r.ReviewedBy),
+			ReviewedByName:  common.TextToStringPointer(r.ReviewedByName),
+			ReviewNote:      common.TextToString(r.ReviewNote),
+			GatewayTxnID:    common.PgUUIDToUUIDPointer(r.GatewayTxnID),
+			RefundTxnRefNo:  common.TextToString(r.RefundTxnRefNo),
+			TransactionID:   common.PgUUIDToUUIDPointer(r.TransactionID),
+			CreatedAt:       r.CreatedAt,
+			ReviewedAt:      common.TimestamptzToTimePointer(r.ReviewedAt),
+			CompletedAt:     common.TimestamptzToTimePointer(r.CompletedAt),
+		})
+	}
+
+	return items, totalCount, nil
+}
+
+// settleRefund applies a final answer to a refund in flight. SUCCESS turns the
+// hold into a GATEWAY_REFUND debit from the user's wallet back to the
+// liability wallet; FAILED releases the hold. The refund's gateway
+// transaction takes the same status. The caller holds the refund row lock.
+func (s *Service) settleRefund(ctx context.Context, tx pgx.Tx, refund payment.GikiWalletGatewayRefund, refundTxnRefNo string, status PaymentStatus, message, responseCode string) (payment.GikiWalletGatewayRefund, error) {
+	paymentQ := s.q.WithTx(tx)
+
+	if err := paymentQ.UpdateGatewayTransactionStatus(ctx, payment.UpdateGatewayTransactionStatusParams{
+		Status:            payment.CurrentStatus(status),
+		TxnRefNo:          refundTxnRefNo,
+		GatewayMessage:    common.StringToText(message),
+		GatewayStatusCode: common.StringToText(responseCode),
+	}); err != nil {
+		return refund, commonerrors.Wrap(ErrTransactionUpdate, err)
+	}
+
+	if status != PaymentStatusSuccess {
+		if err := s.walletS.ReleaseReservations(ctx, tx, []string{refundTxnRefNo}); err != nil {
+			return refund, err
+		}
+
+		failed, err := paymentQ.CompleteGatewayRefund(ctx, payment.CompleteGatewayRefundParams{
+			ID:     refund.ID,
+			Status: RefundStatusFailed,
+		})
+		if err != nil {
+			return refund, commonerrors.Wrap(ErrDatabaseQuery, err)
+		}
+		return failed, nil
+	}
+
+	// frees the held amount for the debit below
+	if _, err := s.walletS.CaptureReservation(ctx, tx, refundTxnRefNo); err != nil {
+		return refund, err
+	}
+
+	liabilityWalletID, err := s.walletS.GetSystemWalletByName(ctx, wallet.GikiWallet, wallet.SystemWalletLiability)
+	if err != nil {
+		return refund, fmt.Errorf("failed to get system liability wallet: %w", err)
+	}
+
+	userWallet, err := s.walletS.GetOrCreateWallet(ctx, tx, refund.UserID)
+	if err != nil {
+		return refund, fmt.Errorf("failed to get user wallet: %w", err)
+	}
+
+	headerID, err := s.walletS.PostJournalEntry(ctx, tx, wallet.JournalEntry{
+		Type:        wallet.TransactionTypeGatewayRefund,
+		ReferenceID: refundTxnRefNo,
+		Description: fmt.Sprintf("Refund via payment %s: %s", refundTxnRefNo, refund.Reason),
+		Legs: []wallet.JournalLeg{
+			{WalletID: userWallet.ID, Amount: -refund.Amount},
+			{WalletID: liabilityWalletID, Amount: refund.Amount},
+		},
+	})
+	if err != nil {
+		return refund, err
+	}
+
+	completed, err := paymentQ.CompleteGatewayRefund(ctx, payment.CompleteGatewayRefundParams{
+		ID:            refund.ID,
+		Status:        RefundStatusCompleted,
+		TransactionID: common.GoogleUUIDtoPgUUID(headerID, true),
+	})
+	if err != nil {
+		return refund, commonerrors.Wrap(ErrDatabaseQuery, err)
+	}
+
+	return completed, nil
+}
+
+// sendRefund asks the gateway to refund part of a top-up, sharing the rate
+// limit with inquiries
+func (s *Service) sendRefund(ctx context.Context, gw gateway.Gateway, topUp payment.GikiWalletGatewayTransaction, amount int64) (*gateway.RefundResponse, error) {
+	if err := s.rateLimiter.Acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer s.rateLimiter.Release()
+
+	return gw.Refund(ctx, gateway.RefundRequest{
+		TxnRefNo:    topUp.TxnRefNo,
+		AmountPaisa: AmountToPaisa(amount),
+		Card:        PaymentMethod(topUp.PaymentMethod) == PaymentMethodCard,
+	})
+}
+
+// markRefundUnsettled records that the gateway has not given a final answer
+// for a refund; the refund and its hold stay in place
+func (s *Service) markRefundUnsettled(ctx context.Context, refundTxnRefNo string, message string) {
+	err := s.q.UpdateGatewayTransactionStatus(ctx, payment.UpdateGatewayTransactionStatusParams{
+		Status:         payment.CurrentStatusUNKNOWN,
+		TxnRefNo:       refundTxnRefNo,
+		GatewayMessage: common.StringToText(message),
+	})
+	if err != nil {
+		middleware.LogAppError(fmt.Errorf("failed to mark refund %s unsettled: %w", refundTxnRefNo, err), "refund-"+refundTxnRefNo)
+	}
+}
+
+func (s *Service) lockRefund(ctx context.Context, paymentQ *payment.Queries, refundID uuid.UUID, want string) (payment.GikiWalletGatewayRefund, error) {
+	refund, err := paymentQ.GetGatewayRefundForUpdate(ctx, refundID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return refund, ErrRefundNotFound
+		}
+		return refund, commonerrors.Wrap(ErrDatabaseQuery, err)
+	}
+
+	if refund.Status != want {
+		return refund, commonerrors.Wrap(ErrRefundNotPending, fmt.Errorf("refund %s is %s", refund.ID, refund.Status))
+	}
+
+	return refund, nil
+}
+
+func (s *Service) refundResult(refund payment.GikiWalletGatewayRefund, topUp, refundTxn payment.GikiWalletGatewayTransaction) *GatewayRefund {
+	result := mapDBRefund(refund)
+	result.TopUpTxnRefNo = topUp.TxnRefNo
+	result.RefundTxnRefNo = refundTxn.TxnRefNo
+	return result
+}
+
+func mapDBRefund(r payment.GikiWalletGatewayRefund) *GatewayRefund {
+	return &GatewayRefund{
+		ID:            r.ID,
+		TopUpID:       r.TopupID,
+		UserID:        r.UserID,
+		Amount:        common.Money(r.Amount),
+		Reason:        r.Reason,
+		Status:        r.Status,
+		RequestedBy:   r.RequestedBy,
+		ReviewedBy:    common.PgUUIDToUUIDPointer(r.ReviewedBy),
+		ReviewNote:    common.TextToString(r.ReviewNote),
+		GatewayTxnID:  common.PgUUIDToUUIDPointer(r.GatewayTxnID),
+		TransactionID: common.PgUUIDToUUIDPointer(r.TransactionID),
+		CreatedAt:     r.CreatedAt,
+		ReviewedAt:    common.TimestamptzToTimePointer(r.ReviewedAt),
+		CompletedAt:   common.TimestamptzToTimePointer(r.CompletedAt),
+	}
+}
@@ -7,18 +7,21 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"html"
 	"net/url"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/hash-walker/giki-wallet/internal/auth"
 	"github.com/hash-walker/giki-wallet/internal/common"
 	commonerrors "github.com/hash-walker/giki-wallet/internal/common/errors"
-	"github.com/hash-walker/giki-wallet/internal/config"
 	"github.com/hash-walker/giki-wallet/internal/config_management"
 	"github.com/hash-walker/giki-wallet/internal/payment/gateway"
 	payment "github.com/hash-walker/giki-wallet/internal/payment/payment_db"
 	"github.com/hash-walker/giki-wallet/internal/wallet"
+	"github.com/hash-walker/giki-wallet/internal/worker"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -32,13 +35,17 @@ import (
 
 // Service handles payment business logic
 type Service struct {
-	q             *payment.Queries
-	walletS       *wallet.Service
-	dbPool        *pgxpool.Pool
-	gatewayClient gateway.Gateway
-	rateLimiter   *RateLimiter
-	configS       *config_management.Service
-	AppURL        string
+	q           *payment.Queries
+	walletS     *wallet.Service
+	dbPool      *pgxpool.Pool
+	gateways    map[gateway.Provider]gateway.Gateway
+	rateLimiter *RateLimiter
+	configS     *config_management.Service
+	worker      *worker.JobWorker
+	loc         *time.Location
+	AppURL      string
+
+	statusBroker *statusBroker
 }
 
 // RateLimiter limits concurrent API calls to external services
@@ -50,16 +57,26 @@ type RateLimiter struct {
 // CONSTRUCTORS
 // =============================================================================
 
-// NewService creates a new payment service
-func NewService(dbPool *pgxpool.Pool, gatewayClient gateway.Gateway, walletS *wallet.Service, rateLimiter *RateLimiter, configS *config_management.Service, appURL string) *Service {
+// NewService creates a new payment service. Every configured gateway is
+// passed in; which one a new top-up uses is decided per payment method.
+func NewService(dbPool *pgxpool.Pool, gateways []gateway.Gateway, walletS *wallet.Service, rateLimiter *RateLimiter, configS *config_management.Service, worker *worker.JobWorker, loc *time.Location, appURL string) *Service {
+	byProvider := make(map[gateway.Provider]gateway.Gateway, len(gateways))
+	for _, gw := range gateways {
+		byProvider[gw.Provider()] = gw
+	}
+
 	return &Service{
-		q:             payment.New(dbPool),
-		dbPool:        dbPool,
-		walletS:       walletS,
-		gatewayClient: gatewayClient,
-		rateLimiter:   rateLimiter,
-		configS:       configS,
-		AppURL:        appURL,
+		q:           payment.New(dbPool),
+		dbPool:      dbPool,
+		walletS:     walletS,
+		gateways:    byProvider,
+		rateLimiter: rateLimiter,
+		configS:     configS,
+		worker:      worker,
+		loc:         loc,
+		AppURL:      appURL,
+
+		statusBroker: newStatusBroker(),
 	}
 }
 
@@ -133,12 +150,21 @@ func (s *Service) InitiatePayment(ctx context.Context, payload TopUpRequest) (*T
 		return nil, commonerrors.Wrap(commonerrors.ErrInvalidInput, fmt.Errorf("amount must be greater than 0"))
 	}
 
+	gw, err := s.gatewayForMethod(ctx, payload.Method)
+	if err != nil {
+		return nil, err
+	}
+
+	// Refuse top-ups the ledger would reject after the user has already paid
+	if err := s.walletS.CheckCanReceive(ctx, userID, depositTxnType(gw.Provider())); err != nil {
+		return nil, err
+	}
+
 	maxLimit, err := s.configS.GetMaxTopUpAmount(ctx)
 	if err == nil {
 		balanceResp, balanceErr := s.walletS.GetUserBalance(ctx, userID)
 		if balanceErr == nil {
-			currentBalancePaisa := common.AmountToLowestUnit(balanceResp.Balance)
-			if int64(amountPaisa)+int64(currentBalancePaisa) > maxLimit {
+			if int64(amountPaisa)+balanceResp.Balance.Paisa() > maxLimit {
 				limitRs := maxLimit / 100
 				return nil, commonerrors.Wrap(commonerrors.ErrInvalidInput, fmt.Errorf("top-up would exceed maximum allowed wallet balance of Rs. %d", limitRs))
 			}
@@ -156,13 +182,14 @@ func (s *Service) InitiatePayment(ctx context.Context, payload TopUpRequest) (*T
 	}
 
 	gatewayTxn, err := s.q.CreateGatewayTransaction(ctx, payment.CreateGatewayTransactionParams{
-		UserID:         userID,
-		IdempotencyKey: payload.IdempotencyKey,
-		BillRefID:      billRefNo,
-		TxnRefNo:       txnRefNo,
-		PaymentMethod:  string(payload.Method),
-		Status:         payment.CurrentStatus(PaymentStatusPending),
-		Amount:         int64(amountPaisa),
+		UserID:          userID,
+		IdempotencyKey:  payload.IdempotencyKey,
+		BillRefID:       billRefNo,
+		TxnRefNo:        txnRefNo,
+		PaymentMethod:   string(payload.Method),
+		Status:          payment.CurrentStatus(PaymentStatusPending),
+		Amount:          int64(amountPaisa),
+		GatewayProvider: string(gw.Provider()),
 	})
 
 	if err != nil {
@@ -187,7 +214,7 @@ func (s *Service) InitiatePayment(ctx context.Context, payload TopUpRequest) (*T
 
 	switch payload.Method {
 	case PaymentMethodMWallet:
-		return s.initiateMWalletPayment(ctx, gatewayTxn, payload, billRefNo, txnRefNo)
+		return s.initiateMWalletPayment(ctx, gw, gatewayTxn, payload, billRefNo, txnRefNo)
 	case PaymentMethodCard:
 		return &TopUpResult{
 			ID:             gatewayTxn.ID,
@@ -206,15 +233,22 @@ func (s *Service) InitiatePayment(ctx context.Context, payload TopUpRequest) (*T
 // =============================================================================
 
 // LogCardCallbackAudit logs the raw callback data before processing
-func (s *Service) LogCardCallbackAudit(ctx context.Context, formData url.Values) (uuid.UUID, error) {
+func (s *Service) LogCardCallbackAudit(ctx context.Context, provider gateway.Provider, formData url.Values) (uuid.UUID, error) {
 
 	rawPayload, err := json.Marshal(formData)
 	if err != nil {
 		return uuid.Nil, commonerrors.Wrap(commonerrors.ErrInvalidJSON, err)
 	}
 
-	gatewayRef := formData.Get("pp_BillReference")
-	txnRefNo := formData.Get("pp_TxnRefNo")
+	var gatewayRef, txnRefNo string
+	switch provider {
+	case gateway.ProviderEasypaisa:
+		gatewayRef = formData.Get(gateway.EasypaisaFieldAuthToken)
+		txnRefNo = formData.Get(gateway.EasypaisaFieldOrderRefNumber)
+	default:
+		gatewayRef = formData.Get(gateway.FieldBillReference)
+		txnRefNo = formData.Get(gateway.FieldTxnRefNo)
+	}
 
 	auditLog, err := s.q.CreateAuditLog(ctx, payment.CreateAuditLogParams{
 		EventType:  payment.GikiWalletAuditEventTypeCARDCALLBACK,
@@ -250,28 +284,51 @@ func (s *Service) MarkAuditProcessed(ctx context.Context, auditID uuid.UUID) {
 	}
 }
 
-func (s *Service) CompleteCardPayment(ctx context.Context, tx pgx.Tx, rForm url.Values, auditID uuid.UUID) (*TopUpResult, error) {
+func (s *Service) CompleteCardPayment(ctx context.Context, tx pgx.Tx, provider gateway.Provider, rForm url.Values, auditID uuid.UUID) (*TopUpResult, error) {
 
 	callbackData := make(map[string]string)
 	for k := range rForm {
 		callbackData[k] = rForm.Get(k)
 	}
 
-	callback, err := s.gatewayClient.ParseAndVerifyCardCallback(ctx, callbackData)
+	gw, ok := s.gateways[provider]
+	if !ok {
+		return nil, commonerrors.Wrap(ErrGatewayUnavailable, fmt.Errorf("gateway %s is not configured", provider))
+	}
+
+	callback, err := gw.ParseAndVerifyCardCallback(ctx, callbackData)
 	if err != nil {
 		return nil, commonerrors.Wrap(ErrGatewayUnavailable, err)
 	}
 
 	paymentQ := s.q.WithTx(tx)
 
-	gatewayTxn, err := paymentQ.GetTransactionByTxnRefNo(ctx, callback.TxnRefNo)
-
+	// locked so a reconciliation or force resolution settling the same
+	// top-up waits for this callback, and the other way round
+	gatewayTxn, err := paymentQ.GetTransactionByTxnRefNoForUpdate(ctx, callback.TxnRefNo)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTransactionNotFound
+		}
 		return nil, commonerrors.Wrap(ErrDatabaseQuery, err)
 	}
 
+	if err := checkCardCallback(provider, gatewayTxn, callback); err != nil {
+		return nil, err
+	}
+
 	paymentStatus := GatewayStatusToPaymentStatus(callback.Status)
 
+	// the payment was already confirmed, by the gateway or by an admin; a late
+	// or replayed failure does not undo it
+	if gatewayTxn.Status == payment.CurrentStatusSUCCESS && paymentStatus != PaymentStatusSuccess {
+		s.MarkAuditFailed(ctx, auditID, fmt.Sprintf("callback reports %s (%s) for a settled SUCCESS", paymentStatus, callback.ResponseCode))
+
+		result := MapCardCallbackToTopUpResult(gatewayTxn, callback)
+		result.Status = PaymentStatusSuccess
+		return result, nil
+	}
+
 	err = paymentQ.UpdateGatewayTransactionStatus(ctx, payment.UpdateGatewayTransactionStatusParams{
 		Status:            payment.CurrentStatus(paymentStatus),
 		TxnRefNo:          callback.TxnRefNo,
@@ -288,7 +345,7 @@ func (s *Service) CompleteCardPayment(ctx context.Context, tx pgx.Tx, rForm url.
 	case PaymentStatusFailed:
 		s.MarkAuditFailed(ctx, auditID, fmt.Sprintf("Gateway logic: %s (%s)", callback.Message, callback.ResponseCode))
 	case PaymentStatusSuccess:
-		err = s.creditWalletFromPayment(ctx, tx, gatewayTxn.UserID, gatewayTxn.Amount, gatewayTxn.TxnRefNo)
+		err = s.creditWalletFromPayment(ctx, tx, gatewayTxn.UserID, gatewayTxn.Amount, gatewayTxn.TxnRefNo, depositTxnType(provider))
 		if err != nil {
 			if errors.Is(err, ErrIdempotentSuccess) {
 				return MapCardCallbackToTopUpResult(gatewayTxn, callback), nil
@@ -298,8 +355,9 @@ func (s *Service) CompleteCardPayment(ctx context.Context, tx pgx.Tx, rForm url.
 
 		s.MarkAuditProcessed(ctx, auditID)
 	}
-	if err != nil {
-		// Don't fail the whole operation for this
+
+	if err := s.notifyStatus(ctx, paymentQ, gatewayTxn.TxnRefNo, paymentStatus, callback.Message); err != nil {
+		return nil, commonerrors.Wrap(ErrTransactionUpdate, err)
 	}
 
 	return MapCardCallbackToTopUpResult(gatewayTxn, callback), nil
@@ -311,6 +369,7 @@ func (s *Service) CompleteCardPayment(ctx context.Context, tx pgx.Tx, rForm url.
 
 func (s *Service) initiateMWalletPayment(
 	ctx context.Context,
+	gw gateway.Gateway,
 	gatewayTxn payment.GikiWalletGatewayTransaction,
 	payload TopUpRequest,
 	billRefNo, txnRefNo string,
@@ -340,7 +399,7 @@ func (s *Service) initiateMWalletPayment(
 		TxnExpiryDateTime: txnExpiryDateTime,
 	}
 
-	_, err = s.gatewayClient.SubmitMWallet(ctx, mwRequest)
+	_, err = gw.SubmitMWallet(ctx, mwRequest)
 	if err != nil {
 		return nil, commonerrors.Wrap(ErrGatewayUnavailable, err)
 	}
@@ -362,32 +421,48 @@ func (s *Service) initiateCardPayment(
 		return "", fmt.Errorf("transaction is not pending")
 	}
 
-	// Build request
+	gw, err := s.gatewayFor(txn)
+	if err != nil {
+		return "", err
+	}
+
+	// Build request; the gateway fills in its own callback URL
 	txnDateTime := time.Now().Format("20060102150405")
 	txnExpiryDateTime := time.Now().Add(24 * time.Hour).Format("20060102150405")
-	returnURL := config.LoadConfig().Jazzcash.CardCallbackURL
 
 	cardRequest := gateway.CardInitiateRequest{
 		AmountPaisa:       AmountToPaisa(txn.Amount),
 		BillRefID:         txn.BillRefID,
 		TxnRefNo:          txn.TxnRefNo,
 		Description:       "GIKI-Wallet-TopUp",
-		ReturnURL:         returnURL,
 		TxnDateTime:       txnDateTime,
 		TxnExpiryDateTime: txnExpiryDateTime,
 	}
 
-	cardInitiateResponse, err := s.gatewayClient.InitiateCard(ctx, cardRequest)
+	cardInitiateResponse, err := gw.InitiateCard(ctx, cardRequest)
 
 	if err != nil {
 		return "", commonerrors.Wrap(ErrGatewayUnavailable, err)
 	}
 
-	html := s.buildAutoSubmitForm(cardInitiateResponse.Fields, cardInitiateResponse.PostURL)
+	html := s.buildAutoSubmitForm(gw.Provider(), cardInitiateResponse.Fields, cardInitiateResponse.PostURL)
 
 	return html, nil
 }
 
+// ContinueEasypaisaCheckout renders the page that posts the auth token from
+// Easypaisa's first checkout redirect on to its confirm page
+func (s *Service) ContinueEasypaisaCheckout(authToken string) (string, error) {
+	gw, ok := s.gateways[gateway.ProviderEasypaisa].(*gateway.EasypaisaClient)
+	if !ok {
+		return "", commonerrors.Wrap(ErrGatewayUnavailable, fmt.Errorf("gateway %s is not configured", gateway.ProviderEasypaisa))
+	}
+
+	confirm := gw.ConfirmCheckout(authToken)
+
+	return s.buildAutoSubmitForm(gateway.ProviderEasypaisa, confirm.Fields, confirm.PostURL), nil
+}
+
 func (s *Service) GetTransactionStatus(ctx context.Context, txnRefNo string) (*TopUpResult, error) {
 	txn, err := s.q.GetTransactionByTxnRefNo(ctx, txnRefNo)
 	if err != nil {
@@ -397,7 +472,7 @@ func (s *Service) GetTransactionStatus(ctx context.Context, txnRefNo string) (*T
 	return s.checkTransactionStatus(ctx, txn)
 }
 
-func (s *Service) creditWalletFromPayment(ctx context.Context, tx pgx.Tx, userID uuid.UUID, amount int64, paymentTxnRefNo string) error {
+func (s *Service) creditWalletFromPayment(ctx context.Context, tx pgx.Tx, userID uuid.UUID, amount int64, paymentTxnRefNo string, txnType string) error {
 
 	// get user's wallet
 	userWallet, err := s.walletS.GetOrCreateWallet(ctx, tx, userID)
@@ -412,7 +487,6 @@ func (s *Service) creditWalletFromPayment(ctx context.Context, tx pgx.Tx, userID
 	}
 
 	// prepare transaction details
-	txnType := "JAZZCASH_DEPOSIT"
 	description := fmt.Sprintf("Wallet top-up via payment %s", paymentTxnRefNo)
 
 	err = s.walletS.ExecuteTransaction(
@@ -459,13 +533,13 @@ func (s *Service) handleExistingTransaction(
 			Status:        PaymentStatusSuccess,
 			Message:       "Transaction has already completed",
 			PaymentMethod: PaymentMethod(existing.PaymentMethod),
-			Amount:        float64(existing.Amount),
+			Amount:        common.Money(existing.Amount),
 		}, nil
 
 	case payment.CurrentStatus(PaymentStatusPending),
 		payment.CurrentStatus(PaymentStatusUnknown),
 		payment.CurrentStatus(PaymentStatusFailed):
-		// For all non-success statuses, check the REAL status at JazzCash
+		// For all non-success statuses, check the REAL status at the gateway
 		return s.checkTransactionStatus(ctx, existing)
 
 	default:
@@ -475,7 +549,7 @@ func (s *Service) handleExistingTransaction(
 			Status:        PaymentStatusFailed,
 			Message:       "Transaction has failed. Please create a new payment.",
 			PaymentMethod: PaymentMethod(existing.PaymentMethod),
-			Amount:        float64(existing.Amount) / 100.0,
+			Amount:        common.Money(existing.Amount),
 		}, nil
 	}
 }
@@ -485,8 +559,17 @@ func (s *Service) checkTransactionStatus(
 	ctx context.Context,
 	existing payment.GikiWalletGatewayTransaction,
 ) (*TopUpResult, error) {
+	if existing.Kind != GatewayTxnKindTopUp {
+		return nil, ErrRefundTransaction
+	}
+
+	gw, err := s.gatewayFor(existing)
+	if err != nil {
+		return nil, err
+	}
+
 	// External API call - no transaction held
-	inquiryResult, err := s.gatewayClient.Inquiry(ctx, gateway.InquiryRequest{TxnRefNo: existing.TxnRefNo})
+	inquiryResult, err := gw.Inquiry(ctx, gateway.InquiryRequest{TxnRefNo: existing.TxnRefNo})
 
 	if err != nil {
 		return nil, commonerrors.Wrap(ErrGatewayUnavailable, err)
@@ -525,107 +608,40 @@ func (s *Service) checkTransactionStatus(
 }
 
 // =============================================================================
-// PRIVATE SERVICE METHODS - Background Polling
+// PRIVATE SERVICE METHODS - Finalization
 // =============================================================================
 
-// startPollingForTransaction polls gateway for transaction status updates
-func (s *Service) startPollingForTransaction(txRefNo string) {
-	conn, err := s.dbPool.Acquire(context.Background())
-	if err != nil {
-		middleware.LogAppError(commonerrors.Wrap(commonerrors.ErrDatabase, fmt.Errorf("failed to acquire db connection for polling: %w", err)), "polling-"+txRefNo)
-		return
-	}
-
-	defer conn.Release()
-
-	paymentQ := payment.New(conn)
-
-	// Acquire polling lock
-	_, err = paymentQ.UpdatePollingStatus(context.Background(), txRefNo)
-	if err != nil {
-		// polling already started or transaction not found
-		middleware.LogAppError(commonerrors.Wrap(commonerrors.ErrDatabase, fmt.Errorf("polling already started or transaction not found: %w", err)), "polling-"+txRefNo)
-		return
-	}
-
-	pollCtx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
-	defer cancel()
-
-	ticker := time.NewTicker(2 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-pollCtx.Done():
-			s.handlePollingTimeout(paymentQ, txRefNo)
-			return
-
-		case <-ticker.C:
-			if done := s.pollTransactionOnce(pollCtx, txRefNo); done {
-				return
-			}
-		}
-	}
-}
-
-// handlePollingTimeout handles timeout during polling
-func (s *Service) handlePollingTimeout(paymentQ *payment.Queries, txRefNo string) {
-	cleanupCtx := context.Background()
-
-	err := paymentQ.UpdateGatewayTransactionStatus(cleanupCtx, payment.UpdateGatewayTransactionStatusParams{
-		Status:            payment.CurrentStatus(PaymentStatusFailed),
-		TxnRefNo:          txRefNo,
-		GatewayMessage:    pgtype.Text{String: "Polling timeout reached", Valid: true},
-		GatewayStatusCode: pgtype.Text{String: "TIMEOUT", Valid: true},
-	})
-
-	if err != nil {
-		middleware.LogAppError(commonerrors.Wrap(ErrTransactionUpdate, fmt.Errorf("failed to update status on timeout: %w", err)), "polling-"+txRefNo)
-	}
-
-	if err := paymentQ.ClearPollingStatus(cleanupCtx, txRefNo); err != nil {
-		middleware.LogAppError(commonerrors.Wrap(commonerrors.ErrDatabase, fmt.Errorf("failed to clear polling status: %w", err)), "polling-"+txRefNo)
-	}
-}
-
-func (s *Service) pollTransactionOnce(ctx context.Context, txRefNo string) bool {
-	// 1. Acquire Rate Limiter
-	if err := s.rateLimiter.Acquire(ctx); err != nil {
-		middleware.LogAppError(commonerrors.Wrap(commonerrors.ErrInternal, fmt.Errorf("rate limiter acquire failed: %w", err)), "polling-"+txRefNo)
-		return true
-	}
-	// Guaranteed release on exit
-	defer s.rateLimiter.Release()
-
-	// 2. Inquiry API Call
-	inquiryResult, err := s.gatewayClient.Inquiry(ctx, gateway.InquiryRequest{TxnRefNo: txRefNo})
-	if err != nil {
-		middleware.LogAppError(commonerrors.Wrap(commonerrors.ErrExternalService, fmt.Errorf("inquiry API failed (will retry): %w", err)), "polling-"+txRefNo)
-		return false
-	}
-
-	// 3. Database Transaction Block
-	done, _ := s.finalizeTransaction(ctx, txRefNo, inquiryResult)
-	return done
-}
-
-
 func (s *Service) finalizeTransaction(ctx context.Context, txRefNo string, inquiry *gateway.InquiryResponse) (bool, error) {
 	status := GatewayStatusToPaymentStatus(inquiry.Status)
 	isTerminal := status == PaymentStatusSuccess || status == PaymentStatusFailed
 
 	// 1. PHASE 1: Update Status (PERSISTENT & INDEPENDENT)
 	// We want this to stick even if Phase 2 fails.
-	var existing payment.GikiWalletGatewayTransaction
+	var (
+		existing       payment.GikiWalletGatewayTransaction
+		alreadySettled bool
+	)
 	err := common.WithTransaction(ctx, s.dbPool, func(tx pgx.Tx) error {
 		paymentQ := s.q.WithTx(tx)
 
 		var err error
-		existing, err = paymentQ.GetTransactionByTxnRefNo(ctx, txRefNo)
+		existing, err = paymentQ.GetTransactionByTxnRefNoForUpdate(ctx, txRefNo)
 		if err != nil {
 			return err
 		}
 
+		// an inquiry answer is never credited as a deposit for a refund
+		if existing.Kind != GatewayTxnKindTopUp {
+			return ErrRefundTransaction
+		}
+
+		// a credited SUCCESS, whether confirmed by the gateway or resolved by
+		// an admin, is not undone by a later answer
+		if existing.Status == payment.CurrentStatusSUCCESS && status != PaymentStatusSuccess {
+			alreadySettled = true
+			return nil
+		}
+
 		// Update Status & Clear Polling ONLY IF terminal
 		updateErr := paymentQ.UpdateGatewayTransactionStatus(ctx, payment.UpdateGatewayTransactionStatusParams{
 			Status:            payment.CurrentStatus(status),
@@ -643,6 +659,11 @@ func (s *Service) finalizeTransaction(ctx context.Context, txRefNo string, inqui
 			}
 		}
 
+		// a SUCCESS is announced by phase 2, once the wallet is credited
+		if status != PaymentStatusSuccess && payment.CurrentStatus(status) != existing.Status {
+			return s.notifyStatus(ctx, paymentQ, txRefNo, status, inquiry.Message)
+		}
+
 		return nil
 	})
 
@@ -651,6 +672,11 @@ func (s *Service) finalizeTransaction(ctx context.Context, txRefNo string, inqui
 		return false, err
 	}
 
+	if alreadySettled {
+		middleware.LogAppError(fmt.Errorf("%s is already SUCCESS; ignoring %s from the gateway", txRefNo, status), "finalizer-phase1")
+		return true, nil
+	}
+
 	// 2. PHASE 2: Credit Wallet (ONLY IF Success & Terminal)
 	// This runs in a SEPARATE transaction. If this fails (e.g. unique constraint), 
 	// it won't roll back the Status Update from Phase 1.
@@ -661,14 +687,15 @@ func (s *Service) finalizeTransaction(ctx context.Context, txRefNo string, inqui
 				middleware.LogAppError(fmt.Errorf("RECONCILIATION RECOVERY: Transaction %s recovered to SUCCESS from %s", txRefNo, existing.Status), "reconciliation-recovery")
 			}
 
-			if creditErr := s.creditWalletFromPayment(ctx, tx, existing.UserID, existing.Amount, existing.TxnRefNo); creditErr != nil {
+			txnType := depositTxnType(gateway.Provider(existing.GatewayProvider))
+			if creditErr := s.creditWalletFromPayment(ctx, tx, existing.UserID, existing.Amount, existing.TxnRefNo, txnType); creditErr != nil {
 				// Don't treat idempotent success as an error
 				if errors.Is(creditErr, ErrIdempotentSuccess) {
 					return nil
 				}
 				return creditErr
 			}
-			return nil
+			return s.notifyStatus(ctx, s.q.WithTx(tx), txRefNo, status, inquiry.Message)
 		})
 
 		if creditErr != nil {
@@ -685,12 +712,70 @@ func (s *Service) finalizeTransaction(ctx context.Context, txRefNo string, inqui
 
 
 
+// =============================================================================
+// HELPERS - Gateway Selection
+// =============================================================================
+
+// gatewayForMethod picks the gateway new top-ups with this payment method go
+// through. Finance can switch a method to another provider while one is down.
+func (s *Service) gatewayForMethod(ctx context.Context, method PaymentMethod) (gateway.Gateway, error) {
+	if method != PaymentMethodMWallet && method != PaymentMethodCard {
+		return nil, commonerrors.Wrap(ErrInvalidPaymentMethod, fmt.Errorf("method: %s", method))
+	}
+
+	provider := gateway.Provider(s.configS.GetTopUpGateway(ctx, string(method)))
+
+	gw, ok := s.gateways[provider]
+	if !ok {
+		return nil, commonerrors.Wrap(ErrGatewayUnavailable, fmt.Errorf("gateway %s is not configured", provider))
+	}
+
+	return gw, nil
+}
+
+// gatewayFor returns the gateway an existing transaction was started with, so
+// inquiries keep going to it after the method is switched to another provider
+func (s *Service) gatewayFor(txn payment.GikiWalletGatewayTransaction) (gateway.Gateway, error) {
+	gw, ok := s.gateways[gateway.Provider(txn.GatewayProvider)]
+	if !ok {
+		return nil, commonerrors.Wrap(ErrGatewayUnavailable, fmt.Errorf("gateway %s for transaction %s is not configured", txn.GatewayProvider, txn.TxnRefNo))
+	}
+
+	return gw, nil
+}
+
+// depositTxnType is the ledger transaction type for a top-up through provider
+func depositTxnType(provider gateway.Provider) string {
+	return string(provider) + "_DEPOSIT"
+}
+
+func gatewayDisplayName(provider gateway.Provider) string {
+	switch provider {
+	case gateway.ProviderEasypaisa:
+		return "Easypaisa"
+	default:
+		return "JazzCash"
+	}
+}
+
 // =============================================================================
 // HELPERS - Form Builder
 // =============================================================================
 
-func (s *Service) buildAutoSubmitForm(fields gateway.JazzCashFields, jazzcashPostURL string) string {
-	html := fmt.Sprintf(`
+func (s *Service) buildAutoSubmitForm(provider gateway.Provider, fields map[string]string, postURL string) string {
+	// render the gateway's fields in a stable order
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var inputs strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&inputs, "\t\t\t\t\t<input type=\"hidden\" name=\"%s\" value=\"%s\">\n", html.EscapeString(k), html.EscapeString(fields[k]))
+	}
+
+	page := fmt.Sprintf(`
 		<!DOCTYPE html>
 		<html lang="en">
 		<head>
@@ -756,7 +841,7 @@ func (s *Service) buildAutoSubmitForm(fields gateway.JazzCashFields, jazzcashPos
 				<div class="text-center mb-6">
 					<h1 class="text-2xl font-bold text-gray-900 mb-2">Redirecting to Payment</h1>
 					<p class="text-gray-600 text-sm leading-relaxed">
-						Securely transferring you to JazzCash Payment Gateway...
+						Securely transferring you to %s Payment Gateway...
 					</p>
 				</div>
 
@@ -770,21 +855,7 @@ func (s *Service) buildAutoSubmitForm(fields gateway.JazzCashFields, jazzcashPos
 
 				<!-- Hidden Form -->
 				<form id="payForm" method="POST" action="%s" style="display: none;">
-					<input type="hidden" name="pp_Amount" value="%s">
-					<input type="hidden" name="pp_BillReference" value="%s">
-					<input type="hidden" name="pp_Description" value="%s">
-					<input type="hidden" name="pp_Language" value="EN">
-					<input type="hidden" name="pp_TxnRefNo" value="%s">
-					<input type="hidden" name="pp_MerchantID" value="%s">
-					<input type="hidden" name="pp_Password" value="%s">
-					<input type="hidden" name="pp_ReturnURL" value="%s">
-					<input type="hidden" name="pp_TxnCurrency" value="PKR">
-					<input type="hidden" name="pp_TxnDateTime" value="%s">
-					<input type="hidden" name="pp_TxnExpiryDateTime" value="%s">
-					<input type="hidden" name="pp_TxnType" value="%s">
-					<input type="hidden" name="pp_Version" value="1.1">
-					<input type="hidden" name="pp_SecureHash" value="%s">
-				</form>
+%s				</form>
 
 				<!-- Fallback for JavaScript Disabled -->
 				<noscript>
@@ -804,43 +875,65 @@ func (s *Service) buildAutoSubmitForm(fields gateway.JazzCashFields, jazzcashPos
 			</div>
 		</body>
 		</html>
-	`, jazzcashPostURL, fields[gateway.FieldAmount], fields[gateway.FieldBillReference], fields[gateway.FieldDescription],
-		fields[gateway.FieldTxnRefNo], fields[gateway.FieldMerchantID], fields[gateway.FieldPassword], fields[gateway.FieldReturnURL],
-		fields[gateway.FieldTxnDateTime], fields[gateway.FieldTxnExpiryDateTime], fields[gateway.FieldTxnType], fields[gateway.FieldSecureHash],
-	)
+	`, gatewayDisplayName(provider), html.EscapeString(postURL), inputs.String())
 
-	return html
+	return page
 }
 
 // =============================================================================
 // ADMIN SERVICE METHODS
 // =============================================================================
 
-func (s *Service) GetGatewayTransactions(ctx context.Context, params common.GatewayTransactionListParams) ([]payment.GetGatewayTransactionsRow, int64, int64, error) {
+// GetGatewayTransactions returns one keyset page of gateway transactions.
+// The filter totals are only computed for the first page, since they are the
+// expensive part and do not change while the admin pages through.
+func (s *Service) GetGatewayTransactions(ctx context.Context, params common.GatewayTransactionListParams) (*GatewayTransactionPage, error) {
+	status := pgtype.Text{String: params.Status, Valid: params.Status != ""}
+	paymentMethod := pgtype.Text{String: params.PaymentMethod, Valid: params.PaymentMethod != ""}
+	cursorCreatedAt, cursorID := params.CursorArgs()
+
 	arg := payment.GetGatewayTransactionsParams{
-		Status:        pgtype.Text{String: params.Status, Valid: params.Status != ""},
-		PaymentMethod: pgtype.Text{String: params.PaymentMethod, Valid: params.PaymentMethod != ""},
-		StartDate:     params.StartDate,
-		EndDate:       params.EndDate,
-		Search:        params.Search,
-		Limit:         int32(params.PageSize),
-		Offset:        int32((params.Page - 1) * params.PageSize),
+		Status:          status,
+		PaymentMethod:   paymentMethod,
+		StartDate:       params.StartDate,
+		EndDate:         params.EndDate,
+		Search:          params.Search,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		Limit:           params.FetchLimit(),
 	}
 
 	txns, err := s.q.GetGatewayTransactions(ctx, arg)
 	if err != nil {
 		middleware.LogAppError(fmt.Errorf("GetGatewayTransactions failed: %w; params: %+v", err, params), "payment-get-gateway-transactions")
-		return nil, 0, 0, commonerrors.Wrap(ErrDatabaseQuery, err)
+		return nil, commonerrors.Wrap(ErrDatabaseQuery, err)
 	}
 
-	var total int64
-	var totalAmount int64
-	if len(txns) > 0 {
-		total = txns[0].TotalCount
-		totalAmount = txns[0].TotalAmount
+	txns, nextCursor := common.TrimPage(txns, params.PageSize, func(t payment.GetGatewayTransactionsRow) (time.Time, uuid.UUID) {
+		return t.CreatedAt, t.ID
+	})
+
+	page := &GatewayTransactionPage{
+		Transactions: txns,
+		NextCursor:   nextCursor,
 	}
 
-	return txns, total, totalAmount, nil
+	if params.After == nil {
+		summary, err := s.q.GetGatewayTransactionsSummary(ctx, payment.GetGatewayTransactionsSummaryParams{
+			Status:        status,
+			PaymentMethod: paymentMethod,
+			StartDate:     params.StartDate,
+			EndDate:       params.EndDate,
+			Search:        params.Search,
+		})
+		if err != nil {
+			return nil, commonerrors.Wrap(ErrDatabaseQuery, err)
+		}
+		page.TotalCount = &summary.TotalCount
+		page.TotalAmount = &summary.TotalAmount
+	}
+
+	return page, nil
 }
 
 func (s *Service) VerifyTransaction(ctx context.Context, txnRefNo string) (*AdminGatewayTransaction, error) {
@@ -862,9 +955,10 @@ func (s *Service) VerifyTransaction(ctx context.Context, txnRefNo string) (*Admi
 		UserID:            txn.UserID,
 		UserName:          txn.UserName,
 		UserEmail:         txn.UserEmail,
-		Amount:            fmt.Sprintf("%d", txn.Amount),
+		Amount:            common.Money(txn.Amount),
 		Status:            statusResult.Status,
 		PaymentMethod:     PaymentMethod(txn.PaymentMethod),
+		Gateway:           txn.GatewayProvider,
 		CreatedAt:         txn.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:         txn.UpdatedAt.Format(time.RFC3339),
 		BillRefID:         txn.BillRefID,
@@ -875,22 +969,21 @@ func (s *Service) VerifyTransaction(ctx context.Context, txnRefNo string) (*Admi
 
 
 
-func (s *Service) GetLiabilityWalletBalance(ctx context.Context) (float64, error) {
+func (s *Service) GetLiabilityWalletBalance(ctx context.Context) (common.Money, error) {
 	return s.walletS.GetSystemWalletBalance(ctx, wallet.GikiWallet, wallet.SystemWalletLiability)
 }
 
-func (s *Service) GetTransportRevenueWalletBalance(ctx context.Context) (float64, error) {
+func (s *Service) GetTransportRevenueWalletBalance(ctx context.Context) (common.Money, error) {
 	return s.walletS.GetSystemWalletBalance(ctx, wallet.TransportSystemWallet, wallet.SystemWalletRevenue)
 }
 
-func (s *Service) GetTransportRevenuePeriodVolume(ctx context.Context, startDate, endDate time.Time) (float64, error) {
+func (s *Service) GetTransportRevenuePeriodVolume(ctx context.Context, startDate, endDate time.Time) (common.Money, error) {
 	stats, err := s.walletS.GetWeeklyStats(ctx, startDate, endDate)
 	if err != nil {
 		return 0, err
 	}
 
-	netRevenue := float64(stats.TotalIncome+stats.TotalRefunds) / 100.0
-	return netRevenue, nil
+	return common.Money(stats.TotalIncome + stats.TotalRefunds), nil
 }
 
 func (s *Service) ExportGatewayTransactions(ctx context.Context, params common.GatewayTransactionListParams) ([]byte, error) {
@@ -919,6 +1012,7 @@ func (s *Service) ExportGatewayTransactions(ctx context.Context, params common.G
 		"Amount",
 		"Status",
 		"Method",
+		"Gateway",
 		"Date",
 	}); err != nil {
 		return nil, commonerrors.Wrap(commonerrors.ErrInternal, err)
@@ -926,16 +1020,15 @@ func (s *Service) ExportGatewayTransactions(ctx context.Context, params common.G
 
 	// Rows
 	for _, row := range rows {
-		amount := float64(row.Amount) / 100.0
-
 		if err := w.Write([]string{
 			row.TxnRefNo,
 			row.BillRefID,
 			row.UserName,
 			row.UserEmail,
-			fmt.Sprintf("%.2f", amount),
+			common.Money(row.Amount).Decimal(),
 			string(row.Status),
 			string(row.PaymentMethod),
+			row.GatewayProvider,
 			row.CreatedAt.Format(time.RFC3339),
 		}); err != nil {
 			return nil, commonerrors.Wrap(commonerrors.ErrInternal, err)
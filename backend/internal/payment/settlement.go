@@ -0,0 +1,569 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/hash-walker/giki-wallet/internal/common"
+	commonerrors "github.com/hash-walker/giki-wallet/internal/common/errors"
+	"github.com/hash-walker/giki-wallet/internal/middleware"
+	"github.com/hash-walker/giki-wallet/internal/payment/gateway"
+	payment "github.com/hash-walker/giki-wallet/internal/payment/payment_db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Settlement file headers we recognise, lower-cased with everything but
+// letters and digits removed. Amounts are in Rupees.
+var (
+	settlementRefHeaders    = []string{"txnrefno", "pptxnrefno", "transactionrefno", "transactionreferenceno", "transactionreference", "orderrefnumber", "orderid"}
+	settlementAmountHeaders = []string{"amount", "transactionamount", "txnamount", "amountpkr", "grossamount"}
+)
+
+// settlementLine is one transaction as the gateway reports it, in paisa
+type settlementLine struct {
+	TxnRefNo string
+	Amount   int64
+}
+
+// ImportSettlement matches a gateway's settlement file for one business day
+// (YYYY-MM-DD in the app timezone) against our gateway transactions and the
+// deposits the ledger credited for them. Everything that does not match
+// opens a SETTLEMENT_DISCREPANCY audit entry for finance to resolve.
+func (s *Service) ImportSettlement(ctx context.Context, provider gateway.Provider, date, fileName string, data []byte, uploadedBy uuid.UUID) (*GatewaySettlementReport, error) {
+	if provider != gateway.ProviderJazzCash && provider != gateway.ProviderEasypaisa {
+		return nil, commonerrors.Wrap(ErrGatewayUnavailable, fmt.Errorf("unknown gateway %s", provider))
+	}
+
+	start, err := time.ParseInLocation("2006-01-02", date, s.loc)
+	if err != nil || !start.Before(time.Now()) {
+		return nil, ErrInvalidSettlementDate
+	}
+	end := start.AddDate(0, 0, 1)
+
+	lines, err := parseSettlementFile(data)
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrInvalidSettlementFile, err)
+	}
+
+	refs := make([]string, len(lines))
+	for i, line := range lines {
+		refs[i] = line.TxnRefNo
+	}
+
+	candidates, err := s.q.ListSettlementCandidates(ctx, payment.ListSettlementCandidatesParams{
+		DepositType:     depositTxnType(provider),
+		GatewayProvider: string(provider),
+		TxnRefNos:       refs,
+		StartDate:       start,
+		EndDate:         end,
+	})
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrDatabaseQuery, err)
+	}
+
+	items := classifySettlement(lines, candidates)
+
+	counts := make(map[SettlementCategory]int32)
+	for _, item := range items {
+		counts[item.Category]++
+	}
+
+	sum := sha256.Sum256(data)
+
+	var report *GatewaySettlementReport
+
+	err = common.WithTransaction(ctx, s.dbPool, func(tx pgx.Tx) error {
+		paymentQ := s.q.WithTx(tx)
+
+		settlement, err := paymentQ.CreateGatewaySettlement(ctx, payment.CreateGatewaySettlementParams{
+			GatewayProvider:     string(provider),
+			SettlementDate:      pgtype.Date{Time: start, Valid: true},
+			FileName:            fileName,
+			FileSha256:          hex.EncodeToString(sum[:]),
+			RowCount:            int32(len(lines)),
+			MatchedCount:        counts[SettlementMatched],
+			MissingOursCount:    counts[SettlementMissingOnOurSide],
+			MissingGatewayCount: counts[SettlementMissingAtGateway],
+			MismatchCount:       counts[SettlementAmountMismatch],
+			UploadedBy:          uploadedBy,
+		})
+		if err != nil {
+			if CheckUniqueConstraintViolation(err) {
+				return ErrSettlementAlreadyImported
+			}
+			return commonerrors.Wrap(ErrDatabaseQuery, err)
+		}
+
+		for i := range items {
+			item := &items[i]
+
+			auditID := pgtype.UUID{}
+			if item.Category != SettlementMatched {
+				id, err := s.openSettlementDiscrepancy(ctx, paymentQ, settlement, *item)
+				if err != nil {
+					return err
+				}
+				item.AuditID = &id
+				auditID = common.GoogleUUIDtoPgUUID(id, true)
+			}
+
+			err = paymentQ.CreateGatewaySettlementItem(ctx, payment.CreateGatewaySettlementItemParams{
+				SettlementID:  settlement.ID,
+				TxnRefNo:      item.TxnRefNo,
+				Category:      string(item.Category),
+				GatewayAmount: moneyToInt8(item.GatewayAmount),
+				OurAmount:     moneyToInt8(item.OurAmount),
+				LedgerAmount:  moneyToInt8(item.LedgerAmount),
+				OurStatus:     pgtype.Text{String: item.OurStatus, Valid: item.OurStatus != ""},
+				AuditID:       auditID,
+			})
+			if err != nil {
+				return commonerrors.Wrap(ErrDatabaseQuery, err)
+			}
+		}
+
+		report = &GatewaySettlementReport{
+			Settlement: mapDBSettlement(settlement),
+			Items:      items,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// settled at the gateway but still open here: an inquiry may settle it
+	for _, item := range items {
+		if item.Category == SettlementMissingOnOurSide &&
+			(item.OurStatus == string(PaymentStatusPending) || item.OurStatus == string(PaymentStatusUnknown)) {
+			if err := s.StartReconciliation(ctx, item.TxnRefNo); err != nil {
+				middleware.LogAppError(err, "settlement-"+item.TxnRefNo)
+			}
+		}
+	}
+
+	return report, nil
+}
+
+func (s *Service) openSettlementDiscrepancy(ctx context.Context, paymentQ *payment.Queries, settlement payment.GikiWalletGatewaySettlement, item GatewaySettlementItem) (uuid.UUID, error) {
+	rawPayload, err := json.Marshal(map[string]interface{}{
+		"settlement_id":   settlement.ID,
+		"settlement_date": settlement.SettlementDate.Time.Format("2006-01-02"),
+		"file_name":       settlement.FileName,
+		"category":        item.Category,
+		"gateway_amount":  item.GatewayAmount,
+		"our_amount":      item.OurAmount,
+		"ledger_amount":   item.LedgerAmount,
+		"our_status":      item.OurStatus,
+	})
+	if err != nil {
+		return uuid.Nil, commonerrors.Wrap(ErrInternal, err)
+	}
+
+	auditLog, err := paymentQ.CreateAuditLog(ctx, payment.CreateAuditLogParams{
+		EventType:  payment.GikiWalletAuditEventTypeSETTLEMENTDISCREPANCY,
+		RawPayload: rawPayload,
+		TxnRefNo:   common.StringToText(item.TxnRefNo),
+		GatewayRef: pgtype.Text{},
+		UserID:     pgtype.UUID{},
+	})
+	if err != nil {
+		return uuid.Nil, commonerrors.Wrap(ErrDatabaseQuery, err)
+	}
+
+	return auditLog.ID, nil
+}
+
+// ListSettlements lists imported settlement files, newest business day first
+func (s *Service) ListSettlements(ctx context.Context, page, pageSize int) ([]GatewaySettlement, int64, error) {
+	rows, err := s.q.ListGatewaySettlements(ctx, payment.ListGatewaySettlementsParams{
+		Limit:  int32(pageSize),
+		Offset: int32((page - 1) * pageSize),
+	})
+	if err != nil {
+		return nil, 0, commonerrors.Wrap(ErrDatabaseQuery, err)
+	}
+
+	var totalCount int64
+	settlements := make([]GatewaySettlement, 0, len(rows))
+	for _, r := range rows {
+		totalCount = r.TotalCount
+		settlements = append(settlements, mapDBSettlement(payment.GikiWalletGatewaySettlement{
+			ID:                  r.ID,
+			GatewayProvider:     r.GatewayProvider,
+			SettlementDate:      r.SettlementDate,
+			FileName:            r.FileName,
+			FileSha256:          r.FileSha256,
+			RowCount:            r.RowCount,
+			MatchedCount:        r.MatchedCount,
+			MissingOursCount:    r.MissingOursCount,
+			MissingGatewayCount: r.MissingGatewayCount,
+			MismatchCount:       r.MismatchCount,
+			UploadedBy:          r.UploadedBy,
+			CreatedAt:           r.CreatedAt,
+		}))
+	}
+
+	return settlements, totalCount, nil
+}
+
+// GetSettlementReport returns a settlement with its items, optionally only
+// those in one category
+func (s *Service) GetSettlementReport(ctx context.Context, settlementID uuid.UUID, category string) (*GatewaySettlementReport, error) {
+	if category != "" && !isSettlementCategory(SettlementCategory(category)) {
+		return nil, commonerrors.Wrap(commonerrors.ErrInvalidInput, fmt.Errorf("unknown category %q", category))
+	}
+
+	settlement, err := s.q.GetGatewaySettlement(ctx, settlementID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrSettlementNotFound
+		}
+		return nil, commonerrors.Wrap(ErrDatabaseQuery, err)
+	}
+
+	rows, err := s.q.ListGatewaySettlementItems(ctx, payment.ListGatewaySettlementItemsParams{
+		SettlementID: settlementID,
+		Category:     category,
+	})
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrDatabaseQuery, err)
+	}
+
+	items := make([]GatewaySettlementItem, 0, len(rows))
+	for _, r := range rows {
+		items = append(items, GatewaySettlementItem{
+			ID:            r.ID,
+			TxnRefNo:      r.TxnRefNo,
+			Category:      SettlementCategory(r.Category),
+			GatewayAmount: int8ToMoney(r.GatewayAmount),
+			OurAmount:     int8ToMoney(r.OurAmount),
+			LedgerAmount:  int8ToMoney(r.LedgerAmount),
+			OurStatus:     r.OurStatus.String,
+			AuditID:       common.PgUUIDToUUIDPointer(r.AuditID),
+			Resolved:      r.Resolved.Bool,
+			ResolvedAt:    common.TimestamptzToTimePointer(r.ResolvedAt),
+		})
+	}
+
+	return &GatewaySettlementReport{
+		Settlement: mapDBSettlement(settlement),
+		Items:      items,
+	}, nil
+}
+
+// ResolveSettlementItem records how finance settled a discrepancy as a
+// SETTLEMENT_RESOLUTION audit entry and closes the discrepancy's entry.
+func (s *Service) ResolveSettlementItem(ctx context.Context, itemID, resolvedBy uuid.UUID, req ResolveSettlementItemRequest) (*GatewaySettlementItem, error) {
+	note := strings.TrimSpace(req.Note)
+	switch req.Resolution {
+	case SettlementResolutionCorrected, SettlementResolutionGatewayError, SettlementResolutionAccepted:
+	default:
+		return nil, ErrInvalidSettlementResolution
+	}
+	if note == "" {
+		return nil, ErrInvalidSettlementResolution
+	}
+
+	var result *GatewaySettlementItem
+
+	err := common.WithTransaction(ctx, s.dbPool, func(tx pgx.Tx) error {
+		paymentQ := s.q.WithTx(tx)
+
+		item, err := paymentQ.GetGatewaySettlementItemForUpdate(ctx, itemID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrSettlementItemNotFound
+			}
+			return commonerrors.Wrap(ErrDatabaseQuery, err)
+		}
+
+		if !item.AuditID.Valid || item.Resolved.Bool {
+			return ErrSettlementItemResolved
+		}
+
+		discrepancyID := uuid.UUID(item.AuditID.Bytes)
+
+		rawPayload, err := json.Marshal(map[string]interface{}{
+			"settlement_id":  item.SettlementID,
+			"item_id":        item.ID,
+			"discrepancy_id": discrepancyID,
+			"category":       item.Category,
+			"resolution":     req.Resolution,
+			"note":           note,
+			"resolved_by":    resolvedBy,
+		})
+		if err != nil {
+			return commonerrors.Wrap(ErrInternal, err)
+		}
+
+		_, err = paymentQ.CreateAuditLog(ctx, payment.CreateAuditLogParams{
+			EventType:  payment.GikiWalletAuditEventTypeSETTLEMENTRESOLUTION,
+			RawPayload: rawPayload,
+			TxnRefNo:   common.StringToText(item.TxnRefNo),
+			GatewayRef: pgtype.Text{},
+			UserID:     pgtype.UUID{},
+		})
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabaseQuery, err)
+		}
+
+		if err := paymentQ.MarkAuditProcessed(ctx, discrepancyID); err != nil {
+			return commonerrors.Wrap(ErrDatabaseQuery, err)
+		}
+
+		resolvedAt := time.Now()
+		result = &GatewaySettlementItem{
+			ID:            item.ID,
+			TxnRefNo:      item.TxnRefNo,
+			Category:      SettlementCategory(item.Category),
+			GatewayAmount: int8ToMoney(item.GatewayAmount),
+			OurAmount:     int8ToMoney(item.OurAmount),
+			LedgerAmount:  int8ToMoney(item.LedgerAmount),
+			OurStatus:     item.OurStatus.String,
+			AuditID:       &discrepancyID,
+			Resolved:      true,
+			ResolvedAt:    &resolvedAt,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// =============================================================================
+// HELPERS - Settlement Matching
+// =============================================================================
+
+// classifySettlement puts every transaction in the file, and every one we
+// settled that day but the file leaves out, into one of the four categories.
+// Only a successful transaction the ledger credited counts as present here.
+func classifySettlement(lines []settlementLine, candidates []payment.ListSettlementCandidatesRow) []GatewaySettlementItem {
+	ours := make(map[string]payment.ListSettlementCandidatesRow, len(candidates))
+	for _, c := range candidates {
+		ours[c.TxnRefNo] = c
+	}
+
+	inFile := make(map[string]bool, len(lines))
+	items := make([]GatewaySettlementItem, 0, len(lines))
+
+	for _, line := range lines {
+		inFile[line.TxnRefNo] = true
+
+		item := GatewaySettlementItem{
+			TxnRefNo:      line.TxnRefNo,
+			GatewayAmount: common.MoneyPtr(line.Amount),
+		}
+
+		c, ok := ours[line.TxnRefNo]
+		if ok {
+			item.OurAmount = common.MoneyPtr(c.Amount)
+			item.LedgerAmount = common.MoneyPtr(c.LedgerAmount)
+			item.OurStatus = string(c.Status)
+		}
+
+		switch {
+		case !ok || c.Status != payment.CurrentStatusSUCCESS || c.LedgerAmount == 0:
+			item.Category = SettlementMissingOnOurSide
+		case line.Amount != c.Amount || c.LedgerAmount != c.Amount:
+			item.Category = SettlementAmountMismatch
+		default:
+			item.Category = SettlementMatched
+		}
+
+		items = append(items, item)
+	}
+
+	for _, c := range candidates {
+		if inFile[c.TxnRefNo] || c.Status != payment.CurrentStatusSUCCESS {
+			continue
+		}
+
+		items = append(items, GatewaySettlementItem{
+			TxnRefNo:     c.TxnRefNo,
+			Category:     SettlementMissingAtGateway,
+			OurAmount:    common.MoneyPtr(c.Amount),
+			LedgerAmount: common.MoneyPtr(c.LedgerAmount),
+			OurStatus:    string(c.Status),
+		})
+	}
+
+	return items
+}
+
+// parseSettlementFile reads a settlement CSV with a header row. Rows without
+// a transaction reference, such as totals, are skipped, and a reference that
+// appears more than once is reported with the sum of its amounts.
+func parseSettlementFile(data []byte) ([]settlementLine, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("file is empty")
+		}
+		return nil, err
+	}
+
+	refCol, amountCol := -1, -1
+	for i, h := range header {
+		name := normalizeSettlementHeader(h)
+		if refCol < 0 && containsString(settlementRefHeaders, name) {
+			refCol = i
+		}
+		if amountCol < 0 && containsString(settlementAmountHeaders, name) {
+			amountCol = i
+		}
+	}
+	if refCol < 0 || amountCol < 0 {
+		return nil, fmt.Errorf("header needs a transaction reference and an amount column")
+	}
+
+	var lines []settlementLine
+	index := make(map[string]int)
+
+	for rowNum := 2; ; rowNum++ {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if refCol >= len(record) || strings.TrimSpace(record[refCol]) == "" {
+			continue
+		}
+		ref := strings.TrimSpace(record[refCol])
+
+		if amountCol >= len(record) {
+			return nil, fmt.Errorf("row %d: missing amount", rowNum)
+		}
+		amount, err := parseRupees(record[amountCol])
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", rowNum, err)
+		}
+
+		if i, ok := index[ref]; ok {
+			lines[i].Amount += amount
+			continue
+		}
+		index[ref] = len(lines)
+		lines = append(lines, settlementLine{TxnRefNo: ref, Amount: amount})
+	}
+
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("file has no transactions")
+	}
+
+	return lines, nil
+}
+
+// parseRupees turns an amount like "1,250.50" or "PKR 1250.5" into paisa
+// without going through floating point
+func parseRupees(s string) (int64, error) {
+	v := strings.TrimSpace(s)
+	for _, prefix := range []string{"PKR", "Rs.", "Rs"} {
+		v = strings.TrimPrefix(v, prefix)
+	}
+	v = strings.ReplaceAll(strings.TrimSpace(v), ",", "")
+
+	negative := strings.HasPrefix(v, "-")
+	v = strings.TrimPrefix(v, "-")
+
+	whole, frac, _ := strings.Cut(v, ".")
+	if whole == "" || len(frac) > 2 {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	frac += strings.Repeat("0", 2-len(frac))
+
+	rupees, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	paisa, err := strconv.ParseInt(frac, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+
+	amount := rupees*100 + paisa
+	if negative {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+func normalizeSettlementHeader(h string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, h)
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func isSettlementCategory(c SettlementCategory) bool {
+	switch c {
+	case SettlementMatched, SettlementMissingOnOurSide, SettlementMissingAtGateway, SettlementAmountMismatch:
+		return true
+	default:
+		return false
+	}
+}
+
+func mapDBSettlement(s payment.GikiWalletGatewaySettlement) GatewaySettlement {
+	return GatewaySettlement{
+		ID:                  s.ID,
+		Gateway:             s.GatewayProvider,
+		SettlementDate:      s.SettlementDate.Time.Format("2006-01-02"),
+		FileName:            s.FileName,
+		RowCount:            s.RowCount,
+		MatchedCount:        s.MatchedCount,
+		MissingOursCount:    s.MissingOursCount,
+		MissingGatewayCount: s.MissingGatewayCount,
+		MismatchCount:       s.MismatchCount,
+		UploadedBy:          s.UploadedBy,
+		CreatedAt:           s.CreatedAt,
+	}
+}
+
+func moneyToInt8(m *common.Money) pgtype.Int8 {
+	if m == nil {
+		return pgtype.Int8{}
+	}
+	return pgtype.Int8{Int64: m.Paisa(), Valid: true}
+}
+
+func int8ToMoney(n pgtype.Int8) *common.Money {
+	if !n.Valid {
+		return nil
+	}
+	return common.MoneyPtr(n.Int64)
+}
@@ -0,0 +1,216 @@
+package payment
+
+import (
+	"reflect"
+	"testing"
+
+	payment "github.com/hash-walker/giki-wallet/internal/payment/payment_db"
+)
+
+func TestParseRupees(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{"1250", 125000, false},
+		{"1250.5", 125050, false},
+		{"1,250.50", 125050, false},
+		{"PKR 1250.5", 125050, false},
+		{"Rs. 100", 10000, false},
+		{"Rs 100.05", 10005, false},
+		{"  0.05 ", 5, false},
+		{"-20", -2000, false},
+		{"1,00,000.00", 10000000, false},
+		{"12.345", 0, true},
+		{".50", 0, true},
+		{"", 0, true},
+		{"PKR", 0, true},
+		{"abc", 0, true},
+		{"1.2.3", 0, true},
+		{"12.x", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseRupees(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRupees(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseRupees(%q) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSettlementFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []settlementLine
+		wantErr bool
+	}{
+		{
+			name: "jazzcash headers",
+			data: "pp_TxnRefNo,Amount,Status\nT1,1000.00,Settled\nT2,\"1,250.50\",Settled\n",
+			want: []settlementLine{{"T1", 100000}, {"T2", 125050}},
+		},
+		{
+			name: "spaced headers in another order",
+			data: "Settlement Date, Transaction Amount, Transaction Ref No\n2026-10-16, 500, T1\n",
+			want: []settlementLine{{"T1", 50000}},
+		},
+		{
+			name: "byte order mark",
+			data: "\xef\xbb\xbfTxnRefNo,Amount\nT1,10\n",
+			want: []settlementLine{{"T1", 1000}},
+		},
+		{
+			name: "totals row skipped",
+			data: "TxnRefNo,Amount\nT1,10\n,10\n",
+			want: []settlementLine{{"T1", 1000}},
+		},
+		{
+			name: "repeated reference summed",
+			data: "TxnRefNo,Amount\nT1,10\nT2,5\nT1,2.50\n",
+			want: []settlementLine{{"T1", 1250}, {"T2", 500}},
+		},
+		{
+			name:    "empty file",
+			data:    "",
+			wantErr: true,
+		},
+		{
+			name:    "no amount column",
+			data:    "TxnRefNo,Status\nT1,Settled\n",
+			wantErr: true,
+		},
+		{
+			name:    "header only",
+			data:    "TxnRefNo,Amount\n",
+			wantErr: true,
+		},
+		{
+			name:    "bad amount",
+			data:    "TxnRefNo,Amount\nT1,ten\n",
+			wantErr: true,
+		},
+		{
+			name:    "short row",
+			data:    "TxnRefNo,Status,Amount\nT1,Settled,10\nT2\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSettlementFile([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSettlementFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSettlementFile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifySettlement(t *testing.T) {
+	candidate := func(ref string, amount int64, status payment.CurrentStatus, ledger int64) payment.ListSettlementCandidatesRow {
+		return payment.ListSettlementCandidatesRow{TxnRefNo: ref, Amount: amount, Status: status, LedgerAmount: ledger}
+	}
+
+	tests := []struct {
+		name       string
+		lines      []settlementLine
+		candidates []payment.ListSettlementCandidatesRow
+		want       map[string]SettlementCategory
+	}{
+		{
+			name:       "matched",
+			lines:      []settlementLine{{"T1", 1000}},
+			candidates: []payment.ListSettlementCandidatesRow{candidate("T1", 1000, payment.CurrentStatusSUCCESS, 1000)},
+			want:       map[string]SettlementCategory{"T1": SettlementMatched},
+		},
+		{
+			name:  "unknown to us",
+			lines: []settlementLine{{"T1", 1000}},
+			want:  map[string]SettlementCategory{"T1": SettlementMissingOnOurSide},
+		},
+		{
+			name:       "still pending on our side",
+			lines:      []settlementLine{{"T1", 1000}},
+			candidates: []payment.ListSettlementCandidatesRow{candidate("T1", 1000, payment.CurrentStatusPENDING, 0)},
+			want:       map[string]SettlementCategory{"T1": SettlementMissingOnOurSide},
+		},
+		{
+			name:       "successful but never credited",
+			lines:      []settlementLine{{"T1", 1000}},
+			candidates: []payment.ListSettlementCandidatesRow{candidate("T1", 1000, payment.CurrentStatusSUCCESS, 0)},
+			want:       map[string]SettlementCategory{"T1": SettlementMissingOnOurSide},
+		},
+		{
+			name:       "gateway amount differs",
+			lines:      []settlementLine{{"T1", 900}},
+			candidates: []payment.ListSettlementCandidatesRow{candidate("T1", 1000, payment.CurrentStatusSUCCESS, 1000)},
+			want:       map[string]SettlementCategory{"T1": SettlementAmountMismatch},
+		},
+		{
+			name:       "ledger credited a different amount",
+			lines:      []settlementLine{{"T1", 1000}},
+			candidates: []payment.ListSettlementCandidatesRow{candidate("T1", 1000, payment.CurrentStatusSUCCESS, 2000)},
+			want:       map[string]SettlementCategory{"T1": SettlementAmountMismatch},
+		},
+		{
+			name:  "settled by us but left out of the file",
+			lines: []settlementLine{{"T1", 1000}},
+			candidates: []payment.ListSettlementCandidatesRow{
+				candidate("T1", 1000, payment.CurrentStatusSUCCESS, 1000),
+				candidate("T2", 500, payment.CurrentStatusSUCCESS, 500),
+				candidate("T3", 700, payment.CurrentStatusFAILED, 0),
+			},
+			want: map[string]SettlementCategory{"T1": SettlementMatched, "T2": SettlementMissingAtGateway},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := classifySettlement(tt.lines, tt.candidates)
+
+			got := make(map[string]SettlementCategory, len(items))
+			for _, item := range items {
+				got[item.TxnRefNo] = item.Category
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("classifySettlement() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifySettlementAmounts(t *testing.T) {
+	lines := []settlementLine{{"T1", 900}, {"T2", 100}}
+	candidates := []payment.ListSettlementCandidatesRow{
+		{TxnRefNo: "T1", Amount: 1000, Status: payment.CurrentStatusSUCCESS, LedgerAmount: 1000},
+		{TxnRefNo: "T3", Amount: 500, Status: payment.CurrentStatusSUCCESS, LedgerAmount: 500},
+	}
+
+	items := classifySettlement(lines, candidates)
+	if len(items) != 3 {
+		t.Fatalf("got %d items, want 3", len(items))
+	}
+
+	// file lines come first in file order, then what only we have
+	mismatch, missingOurs, missingGateway := items[0], items[1], items[2]
+
+	if mismatch.TxnRefNo != "T1" || *mismatch.GatewayAmount != 900 || *mismatch.OurAmount != 1000 || *mismatch.LedgerAmount != 1000 {
+		t.Errorf("mismatch item = %+v", mismatch)
+	}
+	if missingOurs.TxnRefNo != "T2" || *missingOurs.GatewayAmount != 100 || missingOurs.OurAmount != nil || missingOurs.LedgerAmount != nil {
+		t.Errorf("missing on our side item = %+v", missingOurs)
+	}
+	if missingGateway.TxnRefNo != "T3" || missingGateway.GatewayAmount != nil || *missingGateway.OurAmount != 500 {
+		t.Errorf("missing at gateway item = %+v", missingGateway)
+	}
+}
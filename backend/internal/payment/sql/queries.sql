@@ -1,6 +1,6 @@
 -- name: CreateGatewayTransaction :one
-INSERT INTO giki_wallet.gateway_transactions(user_id, idempotency_key, bill_ref_id, txn_ref_no, payment_method, status, amount)
-VALUES ($1, $2,$3,$4, $5, $6, $7)
+INSERT INTO giki_wallet.gateway_transactions(user_id, idempotency_key, bill_ref_id, txn_ref_no, payment_method, status, amount, gateway_provider)
+VALUES ($1, $2,$3,$4, $5, $6, $7, $8)
 RETURNING *;
 
 -- name: UpdateGatewayTransactionStatus :exec
@@ -23,27 +23,63 @@ WHERE idempotency_key = $1;
 
 SELECT * FROM giki_wallet.gateway_transactions
 WHERE user_id = $1
+    AND kind = 'TOPUP'
     AND status IN ('PENDING', 'UNKNOWN')
 LIMIT 1;    
 
+-- name: GetGatewayTransactionByID :one
+SELECT * FROM giki_wallet.gateway_transactions
+WHERE id = $1;
+
+-- name: GetGatewayTransactionForUpdate :one
+SELECT * FROM giki_wallet.gateway_transactions
+WHERE id = $1
+    FOR UPDATE;
+
 -- name: GetTransactionByTxnRefNo :one
 
 SELECT * from giki_wallet.gateway_transactions
 WHERE txn_ref_no = $1;
 
+-- name: GetTransactionByTxnRefNoForUpdate :one
+SELECT * FROM giki_wallet.gateway_transactions
+WHERE txn_ref_no = $1
+    FOR UPDATE;
+
 --- update polling status
 
 -- name: UpdatePollingStatus :one
 UPDATE giki_wallet.gateway_transactions
-SET is_polling = TRUE
+SET is_polling = TRUE, polling_started_at = NOW()
 WHERE txn_ref_no = $1 AND is_polling = FALSE
 RETURNING *;
 
 -- name: ClearPollingStatus :exec
 UPDATE giki_wallet.gateway_transactions
-SET is_polling = FALSE
+SET is_polling = FALSE, polling_started_at = NULL
 WHERE txn_ref_no = $1;
 
+-- name: RecoverStalePolling :many
+-- Releases polling locks whose round died with the process that held them
+UPDATE giki_wallet.gateway_transactions
+SET is_polling = FALSE, polling_started_at = NULL
+WHERE is_polling = TRUE
+    AND (polling_started_at IS NULL OR polling_started_at < sqlc.arg('started_before')::timestamptz)
+RETURNING txn_ref_no;
+
+-- name: NotifyGatewayTransactionStatus :exec
+-- Delivered to listeners when the surrounding transaction commits
+SELECT pg_notify('gateway_transaction_status', sqlc.arg('payload')::text);
+
+-- name: GetStaleUnsettledTransactions :many
+SELECT * FROM giki_wallet.gateway_transactions
+WHERE status IN ('PENDING', 'UNKNOWN')
+    AND kind = 'TOPUP'
+    AND is_polling = FALSE
+    AND created_at < sqlc.arg('created_before')
+ORDER BY created_at
+LIMIT sqlc.arg('limit');
+
 -- =============================================================================
 -- AUDIT LOG QUERIES
 -- =============================================================================
@@ -66,12 +102,26 @@ WHERE id = $1;
 
 -- name: GetUnprocessedAudits :many
 SELECT * FROM giki_wallet.payment_audit_log
-WHERE processed = FALSE AND event_type = $1
+WHERE processed = FALSE
+    AND event_type = sqlc.arg('event_type')
+    AND received_at < sqlc.arg('received_before')
+    AND COALESCE(retry_count, 0) < sqlc.arg('max_retries')::INTEGER
 ORDER BY received_at
-LIMIT $2;
+LIMIT sqlc.arg('limit');
+
+-- name: MarkAuditsProcessedByTxn :exec
+UPDATE giki_wallet.payment_audit_log
+SET processed = TRUE, processed_at = NOW()
+WHERE txn_ref_no = $1 AND processed = FALSE;
+
+-- name: MarkAuditsFailedByTxn :exec
+UPDATE giki_wallet.payment_audit_log
+SET process_error = $2, retry_count = COALESCE(retry_count, 0) + 1
+WHERE txn_ref_no = $1 AND processed = FALSE;
 
 -- name: GetGatewayTransactions :many
 SELECT 
+    gt.id,
     gt.txn_ref_no,
     gt.user_id,
     u.name as user_name,
@@ -79,14 +129,13 @@ SELECT
     gt.amount,
     gt.status,
     gt.payment_method,
+    gt.gateway_provider,
     gt.created_at,
     gt.updated_at,
     gt.bill_ref_id,
     gt.gateway_message,
     gt.gateway_status_code,
-
-    COUNT(*) OVER() as total_count,
-    SUM(CASE WHEN gt.status = 'SUCCESS' THEN gt.amount ELSE 0 END) OVER() as total_amount
+    gt.kind
 FROM giki_wallet.gateway_transactions gt
 
 JOIN giki_wallet.users u ON gt.user_id = u.id
@@ -105,12 +154,44 @@ WHERE
         u.name ILIKE '%' || sqlc.arg('search')::text || '%' OR
         u.email ILIKE '%' || sqlc.arg('search')::text || '%'
     )
-ORDER BY gt.created_at DESC
-LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+    AND (
+        sqlc.narg('cursor_created_at')::timestamptz IS NULL OR
+        (gt.created_at, gt.id) < (sqlc.narg('cursor_created_at')::timestamptz, sqlc.narg('cursor_id')::uuid)
+    )
+ORDER BY gt.created_at DESC, gt.id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: GetGatewayTransactionsSummary :one
+SELECT
+    COUNT(*) as total_count,
+    COALESCE(SUM(CASE WHEN gt.status = 'SUCCESS' AND gt.kind = 'TOPUP' THEN gt.amount ELSE 0 END), 0)::BIGINT as total_amount
+FROM giki_wallet.gateway_transactions gt
+JOIN giki_wallet.users u ON gt.user_id = u.id
+WHERE 
+    (COALESCE(sqlc.narg('status')::text, '') = '' OR gt.status::text = sqlc.narg('status')::text)
+    AND (
+        COALESCE(sqlc.narg('payment_method')::text, '') = '' OR 
+        gt.payment_method = sqlc.narg('payment_method')
+    )
+    AND gt.created_at >= sqlc.arg('start_date')
+    AND gt.created_at <= sqlc.arg('end_date')
+    AND (
+        sqlc.arg('search')::text = '' OR
+        gt.txn_ref_no ILIKE '%' || sqlc.arg('search')::text || '%' OR
+        gt.bill_ref_id ILIKE '%' || sqlc.arg('search')::text || '%' OR
+        u.name ILIKE '%' || sqlc.arg('search')::text || '%' OR
+        u.email ILIKE '%' || sqlc.arg('search')::text || '%'
+    );
 
 -- name: ForceUpdateGatewayTransactionStatus :one
+-- Applies an admin-approved resolution and ends any polling round
 UPDATE giki_wallet.gateway_transactions
-SET status = sqlc.arg('status')::current_status
+SET status = sqlc.arg('status')::current_status,
+    gateway_message = sqlc.narg('gateway_message'),
+    gateway_status_code = 'FORCED',
+    is_polling = FALSE,
+    polling_started_at = NULL,
+    updated_at = NOW()
 WHERE txn_ref_no = sqlc.arg('txn_ref_no')
 RETURNING *;
 
@@ -125,14 +206,12 @@ SELECT
     gt.amount,
     gt.status,
     gt.payment_method,
+    gt.gateway_provider,
     gt.created_at,
     gt.updated_at,
     gt.bill_ref_id,
     gt.gateway_message,
-    gt.gateway_status_code,
-
-    COUNT(*) OVER() as total_count,
-    SUM(CASE WHEN gt.status = 'SUCCESS' THEN gt.amount ELSE 0 END) OVER() as total_amount
+    gt.gateway_status_code
 FROM giki_wallet.gateway_transactions gt
 
 JOIN giki_wallet.users u ON gt.user_id = u.id
@@ -167,6 +246,7 @@ SELECT
     gt.amount,
     gt.status,
     gt.payment_method,
+    gt.gateway_provider,
     gt.created_at,
     gt.updated_at,
     gt.bill_ref_id,
@@ -174,4 +254,198 @@ SELECT
     gt.gateway_status_code
 FROM giki_wallet.gateway_transactions gt
 JOIN giki_wallet.users u ON gt.user_id = u.id
-WHERE gt.txn_ref_no = $1;
\ No newline at end of file
+WHERE gt.txn_ref_no = $1;
+
+-- =============================================================================
+-- SETTLEMENT QUERIES
+-- =============================================================================
+
+-- name: ListSettlementCandidates :many
+-- Our side of a settlement file: every transaction the file mentions, plus
+-- every transaction we settled with the gateway that day, with what the
+-- ledger credited for it. A transaction settles when it turns SUCCESS, which
+-- is its updated_at, not the day it was started. The credit is never older
+-- than the transaction, so the ledger is only read from created_at onwards.
+SELECT
+    gt.txn_ref_no,
+    gt.amount,
+    gt.status,
+    COALESCE((
+        SELECT SUM(l.amount)
+        FROM giki_wallet.transactions t
+        JOIN giki_wallet.ledger l ON l.transaction_id = t.id
+        WHERE t.type = sqlc.arg('deposit_type')
+            AND t.reference_id = gt.txn_ref_no
+            AND t.created_at >= gt.created_at
+            AND l.created_at >= gt.created_at
+            AND l.amount > 0
+    ), 0)::BIGINT AS ledger_amount
+FROM giki_wallet.gateway_transactions gt
+WHERE gt.gateway_provider = sqlc.arg('gateway_provider')
+    AND gt.kind = 'TOPUP'
+    AND (
+        gt.txn_ref_no = ANY(sqlc.arg('txn_ref_nos')::text[]) OR
+        (gt.status = 'SUCCESS' AND gt.updated_at >= sqlc.arg('start_date') AND gt.updated_at < sqlc.arg('end_date'))
+    );
+
+-- name: CreateGatewaySettlement :one
+INSERT INTO giki_wallet.gateway_settlements (
+    gateway_provider, settlement_date, file_name, file_sha256, row_count,
+    matched_count, missing_ours_count, missing_gateway_count, mismatch_count, uploaded_by
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: CreateGatewaySettlementItem :exec
+INSERT INTO giki_wallet.gateway_settlement_items (
+    settlement_id, txn_ref_no, category, gateway_amount, our_amount, ledger_amount, our_status, audit_id
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: GetGatewaySettlement :one
+SELECT * FROM giki_wallet.gateway_settlements
+WHERE id = $1;
+
+-- name: ListGatewaySettlements :many
+SELECT *, COUNT(*) OVER() AS total_count
+FROM giki_wallet.gateway_settlements
+ORDER BY settlement_date DESC, created_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: ListGatewaySettlementItems :many
+SELECT
+    i.id, i.settlement_id, i.txn_ref_no, i.category,
+    i.gateway_amount, i.our_amount, i.ledger_amount, i.our_status, i.audit_id,
+    a.processed AS resolved,
+    a.processed_at AS resolved_at
+FROM giki_wallet.gateway_settlement_items i
+LEFT JOIN giki_wallet.payment_audit_log a ON a.id = i.audit_id
+WHERE i.settlement_id = sqlc.arg('settlement_id')
+    AND (sqlc.arg('category')::text = '' OR i.category = sqlc.arg('category')::text)
+ORDER BY i.category, i.txn_ref_no;
+
+-- name: GetGatewaySettlementItemForUpdate :one
+SELECT
+    i.id, i.settlement_id, i.txn_ref_no, i.category,
+    i.gateway_amount, i.our_amount, i.ledger_amount, i.our_status, i.audit_id,
+    a.processed AS resolved
+FROM giki_wallet.gateway_settlement_items i
+LEFT JOIN giki_wallet.payment_audit_log a ON a.id = i.audit_id
+WHERE i.id = $1
+FOR UPDATE OF i;
+
+-- =============================================================================
+-- REFUND QUERIES
+-- =============================================================================
+
+-- name: CreateRefundGatewayTransaction :one
+INSERT INTO giki_wallet.gateway_transactions(
+    user_id, idempotency_key, bill_ref_id, txn_ref_no, payment_method, status, amount, gateway_provider, kind, refund_of_id
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 'REFUND', $9)
+RETURNING *;
+
+-- name: GetRefundedAmount :one
+-- Paisa of a top-up already refunded or on its way back
+SELECT COALESCE(SUM(amount), 0)::BIGINT AS refunded
+FROM giki_wallet.gateway_refunds
+WHERE topup_id = $1
+    AND status IN ('PENDING', 'PROCESSING', 'COMPLETED');
+
+-- name: CreateGatewayRefund :one
+INSERT INTO giki_wallet.gateway_refunds (topup_id, user_id, amount, reason, requested_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetGatewayRefundForUpdate :one
+SELECT * FROM giki_wallet.gateway_refunds
+WHERE id = $1
+    FOR UPDATE;
+
+-- name: ReviewGatewayRefund :one
+UPDATE giki_wallet.gateway_refunds
+SET status = sqlc.arg('status'),
+    reviewed_by = sqlc.arg('reviewed_by'),
+    review_note = sqlc.narg('review_note'),
+    gateway_txn_id = sqlc.narg('gateway_txn_id'),
+    reviewed_at = NOW()
+WHERE id = sqlc.arg('id') AND status = 'PENDING'
+RETURNING *;
+
+-- name: CompleteGatewayRefund :one
+UPDATE giki_wallet.gateway_refunds
+SET status = sqlc.arg('status'),
+    transaction_id = sqlc.narg('transaction_id'),
+    completed_at = NOW()
+WHERE id = sqlc.arg('id') AND status = 'PROCESSING'
+RETURNING *;
+
+-- name: ListGatewayRefunds :many
+SELECT
+    r.id, r.topup_id, r.user_id, r.amount, r.reason, r.status,
+    r.requested_by, r.reviewed_by, r.review_note, r.gateway_txn_id, r.transaction_id,
+    r.created_at, r.reviewed_at, r.completed_at,
+    topup.txn_ref_no AS topup_txn_ref_no,
+    refund.txn_ref_no AS refund_txn_ref_no,
+    u.name AS user_name,
+    u.email AS user_email,
+    req.name AS requested_by_name,
+    rev.name AS reviewed_by_name,
+    COUNT(*) OVER() AS total_count
+FROM giki_wallet.gateway_refunds r
+         JOIN giki_wallet.gateway_transactions topup ON r.topup_id = topup.id
+         LEFT JOIN giki_wallet.gateway_transactions refund ON r.gateway_txn_id = refund.id
+         JOIN giki_wallet.users u ON r.user_id = u.id
+         JOIN giki_wallet.users req ON r.requested_by = req.id
+         LEFT JOIN giki_wallet.users rev ON r.reviewed_by = rev.id
+WHERE (sqlc.arg('status')::text = '' OR r.status = sqlc.arg('status')::text)
+ORDER BY r.created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: GetGatewayRefundByGatewayTxnForUpdate :one
+SELECT * FROM giki_wallet.gateway_refunds
+WHERE gateway_txn_id = $1
+    FOR UPDATE;
+
+-- =============================================================================
+-- FORCE RESOLUTION QUERIES
+-- =============================================================================
+
+-- name: CreateGatewayForceResolution :one
+INSERT INTO giki_wallet.gateway_force_resolutions (
+    gateway_txn_id, from_status, target_status, reason, evidence, bank_reference, requested_by
+) VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetGatewayForceResolutionForUpdate :one
+SELECT * FROM giki_wallet.gateway_force_resolutions
+WHERE id = $1
+    FOR UPDATE;
+
+-- name: ReviewGatewayForceResolution :one
+UPDATE giki_wallet.gateway_force_resolutions
+SET status = sqlc.arg('status'),
+    reviewed_by = sqlc.arg('reviewed_by'),
+    review_note = sqlc.narg('review_note'),
+    audit_id = sqlc.narg('audit_id'),
+    reviewed_at = NOW()
+WHERE id = sqlc.arg('id') AND status = 'PENDING'
+RETURNING *;
+
+-- name: ListGatewayForceResolutions :many
+SELECT
+    f.id, f.gateway_txn_id, f.from_status, f.target_status, f.reason, f.evidence, f.bank_reference,
+    f.status, f.requested_by, f.reviewed_by, f.review_note, f.audit_id, f.created_at, f.reviewed_at,
+    gt.txn_ref_no,
+    gt.kind,
+    gt.amount,
+    gt.user_id,
+    u.name AS user_name,
+    req.name AS requested_by_name,
+    rev.name AS reviewed_by_name,
+    COUNT(*) OVER() AS total_count
+FROM giki_wallet.gateway_force_resolutions f
+         JOIN giki_wallet.gateway_transactions gt ON f.gateway_txn_id = gt.id
+         JOIN giki_wallet.users u ON gt.user_id = u.id
+         JOIN giki_wallet.users req ON f.requested_by = req.id
+         LEFT JOIN giki_wallet.users rev ON f.reviewed_by = rev.id
+WHERE (sqlc.arg('status')::text = '' OR f.status = sqlc.arg('status')::text)
+ORDER BY f.created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
@@ -0,0 +1,177 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	commonerrors "github.com/hash-walker/giki-wallet/internal/common/errors"
+	"github.com/hash-walker/giki-wallet/internal/middleware"
+	payment "github.com/hash-walker/giki-wallet/internal/payment/payment_db"
+	"github.com/jackc/pgx/v5"
+)
+
+// statusChannel is the Postgres channel gateway transaction status changes
+// are announced on. Every API instance listens, so a browser streaming from
+// one instance hears about a callback another instance settled.
+const statusChannel = "gateway_transaction_status"
+
+// statusListenRetry is the wait before listening again after the connection drops
+const statusListenRetry = 5 * time.Second
+
+// StatusEvent is one status transition of a top-up, as pushed to the browser
+type StatusEvent struct {
+	TxnRefNo string        `json:"txn_ref_no"`
+	Status   PaymentStatus `json:"status"`
+	Message  string        `json:"message,omitempty"`
+}
+
+// statusBroker hands status events from the listener to the streams
+// subscribed to that transaction on this instance
+type statusBroker struct {
+	mu   sync.Mutex
+	subs map[string]map[chan StatusEvent]struct{}
+}
+
+func newStatusBroker() *statusBroker {
+	return &statusBroker{subs: make(map[string]map[chan StatusEvent]struct{})}
+}
+
+func (b *statusBroker) subscribe(txnRefNo string) (chan StatusEvent, func()) {
+	ch := make(chan StatusEvent, 4)
+
+	b.mu.Lock()
+	if b.subs[txnRefNo] == nil {
+		b.subs[txnRefNo] = make(map[chan StatusEvent]struct{})
+	}
+	b.subs[txnRefNo][ch] = struct{}{}
+	b.mu.Unlock()
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subs[txnRefNo], ch)
+		if len(b.subs[txnRefNo]) == 0 {
+			delete(b.subs, txnRefNo)
+		}
+	}
+
+	return ch, unsubscribe
+}
+
+func (b *statusBroker) publish(event StatusEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs[event.TxnRefNo] {
+		// a top-up has only a few transitions, so the buffer holds them all;
+		// a stream that is somehow behind misses one rather than holding up
+		// every other stream
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// SubscribeStatus returns the status events for txnRefNo and a function that
+// stops them. Subscribe before reading the current status so no transition
+// committed in between is missed.
+func (s *Service) SubscribeStatus(txnRefNo string) (<-chan StatusEvent, func()) {
+	return s.statusBroker.subscribe(txnRefNo)
+}
+
+// CurrentStatus reads a top-up's stored status for its owner without asking
+// the gateway; the reconciliation worker keeps it moving
+func (s *Service) CurrentStatus(ctx context.Context, userID uuid.UUID, txnRefNo string) (*StatusEvent, error) {
+	txn, err := s.q.GetTransactionByTxnRefNo(ctx, txnRefNo)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, commonerrors.Wrap(ErrDatabaseQuery, err)
+	}
+
+	// someone else's top-up looks the same as one that does not exist
+	if txn.UserID != userID || txn.Kind != GatewayTxnKindTopUp {
+		return nil, ErrTransactionNotFound
+	}
+
+	return &StatusEvent{
+		TxnRefNo: txn.TxnRefNo,
+		Status:   PaymentStatus(txn.Status),
+		Message:  txn.GatewayMessage.String,
+	}, nil
+}
+
+// ListenForStatusUpdates listens for status changes committed by any
+// instance and passes them to this instance's streams until ctx ends
+func (s *Service) ListenForStatusUpdates(ctx context.Context) {
+	for {
+		err := s.listenForStatusUpdates(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		middleware.LogAppError(commonerrors.Wrap(commonerrors.ErrDatabase, fmt.Errorf("status listener stopped: %w", err)), "payment-status-listener")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(statusListenRetry):
+		}
+	}
+}
+
+func (s *Service) listenForStatusUpdates(ctx context.Context) error {
+	poolConn, err := s.dbPool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	// a listening connection must not go back to the pool
+	conn := poolConn.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+statusChannel); err != nil {
+		return err
+	}
+
+	log.Printf("[Payment] Listening for status updates on %s", statusChannel)
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var event StatusEvent
+		if err := json.Unmarshal([]byte(notification.Payload), &event); err != nil {
+			middleware.LogAppError(commonerrors.Wrap(commonerrors.ErrInvalidJSON, err), "payment-status-listener")
+			continue
+		}
+
+		s.statusBroker.publish(event)
+	}
+}
+
+// notifyStatus announces a status change. Postgres delivers it only when the
+// surrounding transaction commits, so a stream never sees a status that was
+// rolled back, and a SUCCESS is only heard once the wallet is credited.
+func (s *Service) notifyStatus(ctx context.Context, paymentQ *payment.Queries, txnRefNo string, status PaymentStatus, message string) error {
+	payload, err := json.Marshal(StatusEvent{
+		TxnRefNo: txnRefNo,
+		Status:   status,
+		Message:  message,
+	})
+	if err != nil {
+		return err
+	}
+
+	return paymentQ.NotifyGatewayTransactionStatus(ctx, string(payload))
+}
@@ -0,0 +1,28 @@
+package promo
+
+import (
+	"net/http"
+
+	"github.com/hash-walker/giki-wallet/internal/common/errors"
+)
+
+var (
+	// Voucher Admin Errors
+	ErrInvalidVoucher        = errors.New("INVALID_VOUCHER", http.StatusBadRequest, "Voucher request is invalid")
+	ErrVoucherNotFound       = errors.New("VOUCHER_NOT_FOUND", http.StatusNotFound, "Voucher not found")
+	ErrDuplicateCode         = errors.New("DUPLICATE_VOUCHER_CODE", http.StatusConflict, "A voucher with this code already exists")
+	ErrVoucherHasRedemptions = errors.New("VOUCHER_HAS_REDEMPTIONS", http.StatusConflict, "Voucher has been redeemed; deactivate it instead")
+
+	// Redemption Errors
+	ErrInvalidCode         = errors.New("INVALID_PROMO_CODE", http.StatusNotFound, "This promo code is not valid")
+	ErrVoucherInactive     = errors.New("VOUCHER_INACTIVE", http.StatusConflict, "This promo code is no longer active")
+	ErrVoucherNotStarted   = errors.New("VOUCHER_NOT_STARTED", http.StatusConflict, "This promo code cannot be used yet")
+	ErrVoucherExpired      = errors.New("VOUCHER_EXPIRED", http.StatusConflict, "This promo code has expired")
+	ErrVoucherExhausted    = errors.New("VOUCHER_EXHAUSTED", http.StatusConflict, "This promo code has been fully redeemed")
+	ErrUserLimitReached    = errors.New("VOUCHER_USER_LIMIT_REACHED", http.StatusConflict, "You have already redeemed this promo code")
+	ErrUserTypeNotEligible = errors.New("VOUCHER_NOT_ELIGIBLE", http.StatusForbidden, "This promo code is not available for your account type")
+	ErrAccountInactive     = errors.New("ACCOUNT_INACTIVE", http.StatusForbidden, "Your account must be active to redeem promo codes")
+
+	// Database Errors
+	ErrDatabase = errors.New("PROMO_DATABASE_ERROR", http.StatusInternalServerError, "Promo database operation failed")
+)
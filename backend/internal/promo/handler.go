@@ -0,0 +1,209 @@
+package promo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/hash-walker/giki-wallet/internal/audit"
+	"github.com/hash-walker/giki-wallet/internal/auth"
+	"github.com/hash-walker/giki-wallet/internal/common"
+	commonerrors "github.com/hash-walker/giki-wallet/internal/common/errors"
+	"github.com/hash-walker/giki-wallet/internal/middleware"
+)
+
+type Handler struct {
+	service *Service
+	audit   *audit.Service
+}
+
+func NewHandler(service *Service, audit *audit.Service) *Handler {
+	return &Handler{
+		service: service,
+		audit:   audit,
+	}
+}
+
+func (h *Handler) Redeem(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		middleware.HandleError(w, commonerrors.ErrUnauthorized, requestID)
+		return
+	}
+
+	var params RedeemRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidJSON, err), requestID)
+		return
+	}
+
+	res, err := h.service.RedeemVoucher(r.Context(), userID, params.Code)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	common.ResponseWithJSON(w, http.StatusOK, res, requestID)
+}
+
+// =============================================================================
+// ADMIN HANDLERS
+// =============================================================================
+
+func (h *Handler) AdminListVouchers(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	var params common.PaginationParams
+	if err := params.Bind(r); err != nil {
+		appErr := commonerrors.New("INVALID_REQUEST", http.StatusBadRequest, err.Error())
+		middleware.HandleError(w, appErr, requestID)
+		return
+	}
+
+	var isActive *bool
+	if raw := r.URL.Query().Get("is_active"); raw != "" {
+		active, err := strconv.ParseBool(raw)
+		if err != nil {
+			middleware.HandleError(w, commonerrors.New("INVALID_REQUEST", http.StatusBadRequest, "is_active must be true or false"), requestID)
+			return
+		}
+		isActive = &active
+	}
+
+	vouchers, total, err := h.service.ListVouchers(r.Context(), r.URL.Query().Get("search"), isActive, params.Page, params.PageSize)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	response := map[string]interface{}{
+		"data": vouchers,
+		"meta": map[string]interface{}{
+			"page":          params.Page,
+			"page_size":     params.PageSize,
+			"total_records": total,
+		},
+	}
+
+	common.ResponseWithJSON(w, http.StatusOK, response, requestID)
+}
+
+func (h *Handler) AdminCreateVoucher(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	actorID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		middleware.HandleError(w, commonerrors.ErrUnauthorized, requestID)
+		return
+	}
+
+	var params VoucherRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidJSON, err), requestID)
+		return
+	}
+
+	res, err := h.service.CreateVoucher(r.Context(), actorID, params)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	h.logAdminAction(r.Context(), r, audit.ActionAdminCreateVoucher, &res.ID, map[string]interface{}{
+		"code":            res.Code,
+		"amount":          res.Amount,
+		"max_redemptions": res.MaxRedemptions,
+		"per_user_limit":  res.PerUserLimit,
+	})
+
+	common.ResponseWithJSON(w, http.StatusCreated, res, requestID)
+}
+
+func (h *Handler) AdminGetVoucher(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	voucherID, err := uuid.Parse(chi.URLParam(r, "voucher_id"))
+	if err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidUUID, err), requestID)
+		return
+	}
+
+	res, err := h.service.GetVoucher(r.Context(), voucherID)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	common.ResponseWithJSON(w, http.StatusOK, res, requestID)
+}
+
+func (h *Handler) AdminUpdateVoucher(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	voucherID, err := uuid.Parse(chi.URLParam(r, "voucher_id"))
+	if err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidUUID, err), requestID)
+		return
+	}
+
+	var params VoucherRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidJSON, err), requestID)
+		return
+	}
+
+	res, err := h.service.UpdateVoucher(r.Context(), voucherID, params)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	h.logAdminAction(r.Context(), r, audit.ActionAdminUpdateVoucher, &voucherID, map[string]interface{}{
+		"code":            res.Code,
+		"is_active":       res.IsActive,
+		"max_redemptions": res.MaxRedemptions,
+		"expires_at":      res.ExpiresAt,
+	})
+
+	common.ResponseWithJSON(w, http.StatusOK, res, requestID)
+}
+
+func (h *Handler) AdminDeleteVoucher(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	voucherID, err := uuid.Parse(chi.URLParam(r, "voucher_id"))
+	if err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidUUID, err), requestID)
+		return
+	}
+
+	if err := h.service.DeleteVoucher(r.Context(), voucherID); err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	h.logAdminAction(r.Context(), r, audit.ActionAdminDeleteVoucher, &voucherID, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) logAdminAction(ctx context.Context, r *http.Request, action string, targetID *uuid.UUID, details map[string]interface{}) {
+	ip := common.GetClientIP(r)
+	userAgent := r.UserAgent()
+	actorID, _ := auth.GetUserIDFromContext(ctx)
+
+	_ = h.audit.LogSecurityEvent(ctx, audit.Event{
+		ActorID:   &actorID,
+		Action:    action,
+		TargetID:  targetID,
+		IPAddress: ip,
+		UserAgent: userAgent,
+		Status:    audit.StatusSuccess,
+		Details:   details,
+	})
+}
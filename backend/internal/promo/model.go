@@ -0,0 +1,98 @@
+package promo
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hash-walker/giki-wallet/internal/common"
+	promodb "github.com/hash-walker/giki-wallet/internal/promo/promo_db"
+)
+
+// TransactionTypePromoCredit is the ledger type of a voucher redemption
+const TransactionTypePromoCredit = "PROMO_CREDIT"
+
+// VoucherRequest creates or replaces a voucher's settings.
+// Amount is in Rupees; the code cannot be changed after creation.
+type VoucherRequest struct {
+	Code            string     `json:"code"`
+	Description     string     `json:"description"`
+	Amount          float64    `json:"amount"`
+	MaxRedemptions  *int32     `json:"max_redemptions"` // nil means unlimited
+	PerUserLimit    int32      `json:"per_user_limit"`
+	TargetUserTypes []string   `json:"target_user_types"` // empty means everyone
+	StartsAt        *time.Time `json:"starts_at"`
+	ExpiresAt       *time.Time `json:"expires_at"`
+	IsActive        *bool      `json:"is_active"`
+}
+
+type Voucher struct {
+	ID              uuid.UUID    `json:"id"`
+	Code            string       `json:"code"`
+	Description     string       `json:"description"`
+	Amount          common.Money `json:"amount"`
+	MaxRedemptions  *int32       `json:"max_redemptions"`
+	PerUserLimit    int32        `json:"per_user_limit"`
+	RedemptionCount int32        `json:"redemption_count"`
+	TargetUserTypes []string     `json:"target_user_types"`
+	StartsAt        time.Time    `json:"starts_at"`
+	ExpiresAt       *time.Time   `json:"expires_at"`
+	IsActive        bool         `json:"is_active"`
+	CreatedBy       uuid.UUID    `json:"created_by"`
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+}
+
+type VoucherRedemption struct {
+	ID        uuid.UUID    `json:"id"`
+	UserID    uuid.UUID    `json:"user_id"`
+	UserName  string       `json:"user_name"`
+	UserEmail string       `json:"user_email"`
+	Amount    common.Money `json:"amount"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type VoucherDetail struct {
+	Voucher
+	RecentRedemptions []VoucherRedemption `json:"recent_redemptions"`
+}
+
+type RedeemRequest struct {
+	Code string `json:"code"`
+}
+
+type RedeemResult struct {
+	RedemptionID uuid.UUID    `json:"redemption_id"`
+	Code         string       `json:"code"`
+	Amount       common.Money `json:"amount"`
+	NewBalance   common.Money `json:"new_balance"`
+	RedeemedAt   time.Time    `json:"redeemed_at"`
+}
+
+func mapDBVoucher(v promodb.GikiWalletPromoVoucher) *Voucher {
+	var maxRedemptions *int32
+	if v.MaxRedemptions.Valid {
+		maxRedemptions = &v.MaxRedemptions.Int32
+	}
+
+	targets := v.TargetUserTypes
+	if targets == nil {
+		targets = []string{}
+	}
+
+	return &Voucher{
+		ID:              v.ID,
+		Code:            v.Code,
+		Description:     common.TextToString(v.Description),
+		Amount:          common.Money(v.Amount) / 100.0,
+		MaxRedemptions:  maxRedemptions,
+		PerUserLimit:    v.PerUserLimit,
+		RedemptionCount: v.RedemptionCount,
+		TargetUserTypes: targets,
+		StartsAt:        v.StartsAt,
+		ExpiresAt:       common.TimestamptzToTimePointer(v.ExpiresAt),
+		IsActive:        v.IsActive,
+		CreatedBy:       v.CreatedBy,
+		CreatedAt:       v.CreatedAt,
+		UpdatedAt:       v.UpdatedAt,
+	}
+}
@@ -0,0 +1,416 @@
+package promo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hash-walker/giki-wallet/internal/auth"
+	"github.com/hash-walker/giki-wallet/internal/common"
+	commonerrors "github.com/hash-walker/giki-wallet/internal/common/errors"
+	promodb "github.com/hash-walker/giki-wallet/internal/promo/promo_db"
+	"github.com/hash-walker/giki-wallet/internal/wallet"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var codePattern = regexp.MustCompile(`^[A-Z0-9_-]{4,32}$`)
+
+type Service struct {
+	q       *promodb.Queries
+	dbPool  *pgxpool.Pool
+	walletS *wallet.Service
+}
+
+func NewService(dbPool *pgxpool.Pool, walletS *wallet.Service) *Service {
+	return &Service{
+		q:       promodb.New(dbPool),
+		dbPool:  dbPool,
+		walletS: walletS,
+	}
+}
+
+// =============================================================================
+// REDEMPTION
+// =============================================================================
+
+// RedeemVoucher credits a voucher's amount from the promo system wallet to the
+// user's wallet. The voucher row is locked for the whole redemption, so the
+// global and per-user limits cannot be raced past by parallel requests.
+func (s *Service) RedeemVoucher(ctx context.Context, userID uuid.UUID, code string) (*RedeemResult, error) {
+	code = normalizeCode(code)
+	if !codePattern.MatchString(code) {
+		return nil, ErrInvalidCode
+	}
+
+	user, err := s.q.GetRedeemingUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, commonerrors.ErrUnauthorized
+		}
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	if !user.IsActive {
+		return nil, ErrAccountInactive
+	}
+
+	promoWalletID, err := s.walletS.GetSystemWalletByName(ctx, wallet.PromoSystemWallet, wallet.SystemWalletPromo)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *RedeemResult
+
+	err = common.WithTransaction(ctx, s.dbPool, func(tx pgx.Tx) error {
+		promoQ := s.q.WithTx(tx)
+
+		v, err := promoQ.GetVoucherByCodeForUpdate(ctx, code)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrInvalidCode
+			}
+			return commonerrors.Wrap(ErrDatabase, err)
+		}
+
+		if err := checkRedeemable(v, user.UserType, time.Now()); err != nil {
+			return err
+		}
+
+		used, err := promoQ.CountUserRedemptions(ctx, promodb.CountUserRedemptionsParams{
+			VoucherID: v.ID,
+			UserID:    userID,
+		})
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabase, err)
+		}
+
+		if used >= int64(v.PerUserLimit) {
+			return ErrUserLimitReached
+		}
+
+		userWallet, err := s.walletS.GetOrCreateWallet(ctx, tx, userID)
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabase, err)
+		}
+
+		redemption, err := promoQ.CreateRedemption(ctx, promodb.CreateRedemptionParams{
+			VoucherID: v.ID,
+			UserID:    userID,
+			Amount:    v.Amount,
+		})
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabase, err)
+		}
+
+		err = s.walletS.ExecuteTransaction(
+			ctx,
+			tx,
+			promoWalletID,
+			userWallet.ID,
+			v.Amount,
+			TransactionTypePromoCredit,
+			redemption.ID.String(),
+			fmt.Sprintf("Promo code %s", v.Code),
+		)
+		if err != nil {
+			return err
+		}
+
+		if err := promoQ.IncrementVoucherRedemptions(ctx, v.ID); err != nil {
+			return commonerrors.Wrap(ErrDatabase, err)
+		}
+
+		result = &RedeemResult{
+			RedemptionID: redemption.ID,
+			Code:         v.Code,
+			Amount:       common.Money(v.Amount),
+			RedeemedAt:   redemption.CreatedAt,
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := s.walletS.GetUserBalance(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	result.NewBalance = balance.Balance
+
+	return result, nil
+}
+
+func checkRedeemable(v promodb.GikiWalletPromoVoucher, userType string, now time.Time) error {
+	if !v.IsActive {
+		return ErrVoucherInactive
+	}
+
+	if now.Before(v.StartsAt) {
+		return ErrVoucherNotStarted
+	}
+
+	if v.ExpiresAt.Valid && !now.Before(v.ExpiresAt.Time) {
+		return ErrVoucherExpired
+	}
+
+	if v.MaxRedemptions.Valid && v.RedemptionCount >= v.MaxRedemptions.Int32 {
+		return ErrVoucherExhausted
+	}
+
+	if len(v.TargetUserTypes) > 0 && !slices.Contains(v.TargetUserTypes, userType) {
+		return ErrUserTypeNotEligible
+	}
+
+	return nil
+}
+
+// =============================================================================
+// ADMIN CRUD
+// =============================================================================
+
+func (s *Service) CreateVoucher(ctx context.Context, createdBy uuid.UUID, req VoucherRequest) (*Voucher, error) {
+	code := normalizeCode(req.Code)
+	if !codePattern.MatchString(code) {
+		return nil, ErrInvalidVoucher.WithDetails("code", "4-32 characters of A-Z, 0-9, '-' or '_'")
+	}
+
+	amount := int64(common.AmountToLowestUnit(req.Amount))
+	if amount <= 0 {
+		return nil, ErrInvalidVoucher.WithDetails("amount", "must be greater than zero")
+	}
+
+	settings, err := validateSettings(req)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := s.q.CreateVoucher(ctx, promodb.CreateVoucherParams{
+		Code:            code,
+		Description:     settings.description,
+		Amount:          amount,
+		MaxRedemptions:  settings.maxRedemptions,
+		PerUserLimit:    settings.perUserLimit,
+		TargetUserTypes: settings.targetUserTypes,
+		StartsAt:        settings.startsAt,
+		ExpiresAt:       settings.expiresAt,
+		IsActive:        settings.isActive,
+		CreatedBy:       createdBy,
+	})
+	if err != nil {
+		if common.IsUniqueConstraintViolation(err) {
+			return nil, ErrDuplicateCode
+		}
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	return mapDBVoucher(v), nil
+}
+
+// UpdateVoucher replaces a voucher's limits, targeting, window and status.
+// The code and amount are fixed once created so past redemptions stay meaningful.
+func (s *Service) UpdateVoucher(ctx context.Context, voucherID uuid.UUID, req VoucherRequest) (*Voucher, error) {
+	settings, err := validateSettings(req)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.q.GetVoucherByID(ctx, voucherID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrVoucherNotFound
+		}
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	if settings.maxRedemptions.Valid && settings.maxRedemptions.Int32 < current.RedemptionCount {
+		return nil, ErrInvalidVoucher.WithDetails("max_redemptions", "cannot be below the redemptions already made")
+	}
+
+	v, err := s.q.UpdateVoucher(ctx, promodb.UpdateVoucherParams{
+		ID:              voucherID,
+		Description:     settings.description,
+		MaxRedemptions:  settings.maxRedemptions,
+		PerUserLimit:    settings.perUserLimit,
+		TargetUserTypes: settings.targetUserTypes,
+		StartsAt:        settings.startsAt,
+		ExpiresAt:       settings.expiresAt,
+		IsActive:        settings.isActive,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrVoucherNotFound
+		}
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	return mapDBVoucher(v), nil
+}
+
+func (s *Service) GetVoucher(ctx context.Context, voucherID uuid.UUID) (*VoucherDetail, error) {
+	v, err := s.q.GetVoucherByID(ctx, voucherID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrVoucherNotFound
+		}
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	rows, err := s.q.ListVoucherRedemptions(ctx, voucherID)
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	redemptions := make([]VoucherRedemption, 0, len(rows))
+	for _, r := range rows {
+		redemptions = append(redemptions, VoucherRedemption{
+			ID:        r.ID,
+			UserID:    r.UserID,
+			UserName:  r.UserName,
+			UserEmail: r.UserEmail,
+			Amount:    common.Money(r.Amount),
+			CreatedAt: r.CreatedAt,
+		})
+	}
+
+	return &VoucherDetail{
+		Voucher:           *mapDBVoucher(v),
+		RecentRedemptions: redemptions,
+	}, nil
+}
+
+func (s *Service) ListVouchers(ctx context.Context, search string, active *bool, page, pageSize int) ([]Voucher, int64, error) {
+	isActive := pgtype.Bool{}
+	if active != nil {
+		isActive = pgtype.Bool{Bool: *active, Valid: true}
+	}
+
+	rows, err := s.q.ListVouchers(ctx, promodb.ListVouchersParams{
+		Search:   strings.TrimSpace(search),
+		IsActive: isActive,
+		Limit:    int32(pageSize),
+		Offset:   int32((page - 1) * pageSize),
+	})
+	if err != nil {
+		return nil, 0, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	var totalCount int64
+	vouchers := make([]Voucher, 0, len(rows))
+	for _, r := range rows {
+		totalCount = r.TotalCount
+		vouchers = append(vouchers, *mapDBVoucher(promodb.GikiWalletPromoVoucher{
+			ID:              r.ID,
+			Code:            r.Code,
+			Description:     r.Description,
+			Amount:          r.Amount,
+			MaxRedemptions:  r.MaxRedemptions,
+			PerUserLimit:    r.PerUserLimit,
+			RedemptionCount: r.RedemptionCount,
+			TargetUserTypes: r.TargetUserTypes,
+			StartsAt:        r.StartsAt,
+			ExpiresAt:       r.ExpiresAt,
+			IsActive:        r.IsActive,
+			CreatedBy:       r.CreatedBy,
+			CreatedAt:       r.CreatedAt,
+			UpdatedAt:       r.UpdatedAt,
+		}))
+	}
+
+	return vouchers, totalCount, nil
+}
+
+// DeleteVoucher removes a voucher that was never redeemed. Redeemed vouchers
+// are referenced by the ledger and can only be deactivated.
+func (s *Service) DeleteVoucher(ctx context.Context, voucherID uuid.UUID) error {
+	if _, err := s.q.GetVoucherByID(ctx, voucherID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrVoucherNotFound
+		}
+		return commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	deleted, err := s.q.DeleteUnusedVoucher(ctx, voucherID)
+	if err != nil {
+		return commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	if deleted == 0 {
+		return ErrVoucherHasRedemptions
+	}
+
+	return nil
+}
+
+type voucherSettings struct {
+	description     pgtype.Text
+	maxRedemptions  pgtype.Int4
+	perUserLimit    int32
+	targetUserTypes []string
+	startsAt        time.Time
+	expiresAt       pgtype.Timestamptz
+	isActive        bool
+}
+
+func validateSettings(req VoucherRequest) (*voucherSettings, error) {
+	settings := &voucherSettings{
+		description:     common.StringToText(strings.TrimSpace(req.Description)),
+		perUserLimit:    req.PerUserLimit,
+		targetUserTypes: []string{},
+		startsAt:        time.Now(),
+		isActive:        true,
+	}
+
+	if settings.perUserLimit == 0 {
+		settings.perUserLimit = 1
+	}
+	if settings.perUserLimit < 0 {
+		return nil, ErrInvalidVoucher.WithDetails("per_user_limit", "must be positive")
+	}
+
+	if req.MaxRedemptions != nil {
+		if *req.MaxRedemptions <= 0 {
+			return nil, ErrInvalidVoucher.WithDetails("max_redemptions", "must be positive or omitted for unlimited")
+		}
+		settings.maxRedemptions = pgtype.Int4{Int32: *req.MaxRedemptions, Valid: true}
+	}
+
+	for _, t := range req.TargetUserTypes {
+		t = strings.ToUpper(strings.TrimSpace(t))
+		if !auth.AllowedRoles[t] {
+			return nil, ErrInvalidVoucher.WithDetails("target_user_types", fmt.Sprintf("unknown user type %q", t))
+		}
+		if !slices.Contains(settings.targetUserTypes, t) {
+			settings.targetUserTypes = append(settings.targetUserTypes, t)
+		}
+	}
+
+	if req.StartsAt != nil {
+		settings.startsAt = *req.StartsAt
+	}
+
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(settings.startsAt) {
+			return nil, ErrInvalidVoucher.WithDetails("expires_at", "must be after starts_at")
+		}
+		settings.expiresAt = pgtype.Timestamptz{Time: *req.ExpiresAt, Valid: true}
+	}
+
+	if req.IsActive != nil {
+		settings.isActive = *req.IsActive
+	}
+
+	return settings, nil
+}
+
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
@@ -0,0 +1,75 @@
+-- name: CreateVoucher :one
+INSERT INTO giki_wallet.promo_vouchers (
+    code, description, amount, max_redemptions, per_user_limit,
+    target_user_types, starts_at, expires_at, is_active, created_by
+)
+VALUES (
+    sqlc.arg('code'), sqlc.narg('description'), sqlc.arg('amount'), sqlc.narg('max_redemptions'),
+    sqlc.arg('per_user_limit'), sqlc.arg('target_user_types')::text[], sqlc.arg('starts_at'),
+    sqlc.narg('expires_at'), sqlc.arg('is_active'), sqlc.arg('created_by')
+)
+RETURNING *;
+
+-- name: GetVoucherByID :one
+SELECT * FROM giki_wallet.promo_vouchers
+WHERE id = $1;
+
+-- name: GetVoucherByCodeForUpdate :one
+SELECT * FROM giki_wallet.promo_vouchers
+WHERE code = $1
+    FOR UPDATE;
+
+-- name: ListVouchers :many
+SELECT *, COUNT(*) OVER() AS total_count
+FROM giki_wallet.promo_vouchers
+WHERE (sqlc.arg('search')::text = '' OR code ILIKE '%' || sqlc.arg('search')::text || '%')
+  AND (sqlc.narg('is_active')::boolean IS NULL OR is_active = sqlc.narg('is_active')::boolean)
+ORDER BY created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: UpdateVoucher :one
+UPDATE giki_wallet.promo_vouchers
+SET description = sqlc.narg('description'),
+    max_redemptions = sqlc.narg('max_redemptions'),
+    per_user_limit = sqlc.arg('per_user_limit'),
+    target_user_types = sqlc.arg('target_user_types')::text[],
+    starts_at = sqlc.arg('starts_at'),
+    expires_at = sqlc.narg('expires_at'),
+    is_active = sqlc.arg('is_active'),
+    updated_at = NOW()
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: DeleteUnusedVoucher :execrows
+DELETE FROM giki_wallet.promo_vouchers
+WHERE id = $1 AND redemption_count = 0;
+
+-- name: IncrementVoucherRedemptions :exec
+UPDATE giki_wallet.promo_vouchers
+SET redemption_count = redemption_count + 1,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: CountUserRedemptions :one
+SELECT COUNT(*) FROM giki_wallet.promo_redemptions
+WHERE voucher_id = $1 AND user_id = $2;
+
+-- name: CreateRedemption :one
+INSERT INTO giki_wallet.promo_redemptions (voucher_id, user_id, amount)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetRedeemingUser :one
+SELECT id, user_type, is_active FROM giki_wallet.users
+WHERE id = $1;
+
+-- name: ListVoucherRedemptions :many
+SELECT
+    r.id, r.user_id, r.amount, r.created_at,
+    u.name AS user_name,
+    u.email AS user_email
+FROM giki_wallet.promo_redemptions r
+         JOIN giki_wallet.users u ON r.user_id = u.id
+WHERE r.voucher_id = $1
+ORDER BY r.created_at DESC
+LIMIT 50;
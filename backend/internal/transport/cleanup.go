@@ -9,13 +9,14 @@ import (
 	commonerrors "github.com/hash-walker/giki-wallet/internal/common/errors"
 	"github.com/hash-walker/giki-wallet/internal/middleware"
 	"github.com/hash-walker/giki-wallet/internal/transport/transport_db"
+	"github.com/hash-walker/giki-wallet/internal/wallet"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// CleanupExpiredHolds runs periodically to reclaim seats from expired holds
+// CleanupExpiredHolds runs periodically to reclaim seats and reserved fares from expired holds
 // It processes each hold in a separate transaction to ensure resilience - one failure won't block others
-func CleanupExpiredHolds(dbPool *pgxpool.Pool, q *transport_db.Queries) {
+func CleanupExpiredHolds(dbPool *pgxpool.Pool, q *transport_db.Queries, walletS *wallet.Service) {
 	ctx := context.Background()
 
 	// Get list of expired holds (no transaction yet - just a query)
@@ -32,7 +33,7 @@ func CleanupExpiredHolds(dbPool *pgxpool.Pool, q *transport_db.Queries) {
 	// Process each hold in its own transaction
 	successCount := 0
 	for _, holdRef := range expiredHolds {
-		if err := cleanupSingleHold(ctx, dbPool, q, holdRef.ID, holdRef.TripID); err != nil {
+		if err := cleanupSingleHold(ctx, dbPool, q, walletS, holdRef.ID, holdRef.TripID); err != nil {
 			// Log error but continue processing other holds
 			middleware.LogAppError(err, fmt.Sprintf("cleanup-hold-failed-id-%s", holdRef.ID.String()))
 			continue
@@ -46,7 +47,7 @@ func CleanupExpiredHolds(dbPool *pgxpool.Pool, q *transport_db.Queries) {
 }
 
 // cleanupSingleHold processes a single expired hold in its own transaction
-func cleanupSingleHold(ctx context.Context, dbPool *pgxpool.Pool, q *transport_db.Queries, holdID, tripID uuid.UUID) error {
+func cleanupSingleHold(ctx context.Context, dbPool *pgxpool.Pool, q *transport_db.Queries, walletS *wallet.Service, holdID, tripID uuid.UUID) error {
 	tx, err := dbPool.Begin(ctx)
 	if err != nil {
 		return commonerrors.Wrap(commonerrors.ErrDatabase, err)
@@ -81,18 +82,23 @@ func cleanupSingleHold(ctx context.Context, dbPool *pgxpool.Pool, q *transport_d
 		return commonerrors.Wrap(commonerrors.ErrDatabase, err)
 	}
 
+	// Give the reserved fare back to the wallet
+	if err := walletS.ExpireReservations(ctx, tx, []string{holdID.String()}); err != nil {
+		return err
+	}
+
 	// Commit this individual transaction
 	return tx.Commit(ctx)
 }
 
 // StartCleanupWorker starts a background goroutine that runs cleanup periodically
-func StartCleanupWorker(dbPool *pgxpool.Pool, interval time.Duration) {
+func StartCleanupWorker(dbPool *pgxpool.Pool, walletS *wallet.Service, interval time.Duration) {
 	q := transport_db.New(dbPool)
 
 	ticker := time.NewTicker(interval)
 	go func() {
 		for range ticker.C {
-			CleanupExpiredHolds(dbPool, q)
+			CleanupExpiredHolds(dbPool, q, walletS)
 		}
 	}()
 }
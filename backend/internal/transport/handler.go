@@ -160,7 +160,7 @@ func (h *Handler) AdminGetRevenueTransactions(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	txns, err := h.service.GetRevenueTransactions(r.Context(), params.Page, params.PageSize)
+	txns, err := h.service.GetRevenueTransactions(r.Context(), params)
 	if err != nil {
 		middleware.HandleError(w, err, requestID)
 		return
@@ -86,11 +86,11 @@ type TripResponse struct {
 	BookingOpensAt  time.Time `json:"booking_opens_at"`
 	BookingClosesAt time.Time `json:"booking_closes_at"`
 
-	Status         string  `json:"status"`
-	ManualStatus   *string `json:"manual_status,omitempty"`
-	AvailableSeats int32   `json:"available_seats"`
-	TotalCapacity  int32   `json:"total_capacity"`
-	BasePrice      float64 `json:"base_price"`
+	Status         string       `json:"status"`
+	ManualStatus   *string      `json:"manual_status,omitempty"`
+	AvailableSeats int32        `json:"available_seats"`
+	TotalCapacity  int32        `json:"total_capacity"`
+	BasePrice      common.Money `json:"base_price"`
 
 	Stops []TripStopItem `json:"stops"`
 }
@@ -102,24 +102,24 @@ type TripStopItem struct {
 }
 
 type AdminTicketItem struct {
-	TicketID          uuid.UUID `json:"ticket_id"`
-	SerialNo          int32     `json:"serial_no"`
-	TicketCode        string    `json:"ticket_code"`
-	PassengerName     string    `json:"passenger_name"`
-	PassengerRelation string    `json:"passenger_relation"`
-	Status            string    `json:"status"`
-	BookingTime       time.Time `json:"booking_time"`
-	StatusUpdatedAt   time.Time `json:"status_updated_at,omitempty"`
-	UserName          string    `json:"user_name"`
-	UserEmail         string    `json:"user_email"`
-	TripID            uuid.UUID `json:"trip_id"`
-	DepartureTime     time.Time `json:"departure_time"`
-	BusType           string    `json:"bus_type"`
-	Direction         string    `json:"direction"`
-	RouteName         string    `json:"route_name"`
-	PickupLocation    string    `json:"pickup_location"`
-	DropoffLocation   string    `json:"dropoff_location"`
-	Price             int32     `json:"price"`
+	TicketID          uuid.UUID    `json:"ticket_id"`
+	SerialNo          int32        `json:"serial_no"`
+	TicketCode        string       `json:"ticket_code"`
+	PassengerName     string       `json:"passenger_name"`
+	PassengerRelation string       `json:"passenger_relation"`
+	Status            string       `json:"status"`
+	BookingTime       time.Time    `json:"booking_time"`
+	StatusUpdatedAt   time.Time    `json:"status_updated_at,omitempty"`
+	UserName          string       `json:"user_name"`
+	UserEmail         string       `json:"user_email"`
+	TripID            uuid.UUID    `json:"trip_id"`
+	DepartureTime     time.Time    `json:"departure_time"`
+	BusType           string       `json:"bus_type"`
+	Direction         string       `json:"direction"`
+	RouteName         string       `json:"route_name"`
+	PickupLocation    string       `json:"pickup_location"`
+	DropoffLocation   string       `json:"dropoff_location"`
+	Price             common.Money `json:"price"`
 }
 
 type AdminTicketPaginationResponse struct {
@@ -224,10 +224,10 @@ type MyTicketResponse struct {
 	PickupLocation  string `json:"pickup_location"`
 	DropoffLocation string `json:"dropoff_location"`
 
-	DepartureTime time.Time `json:"departure_time"`
-	BusType       string    `json:"bus_type"`
-	Price         float64   `json:"price"`
-	IsCancellable bool      `json:"is_cancellable"`
+	DepartureTime time.Time    `json:"departure_time"`
+	BusType       string       `json:"bus_type"`
+	Price         common.Money `json:"price"`
+	IsCancellable bool         `json:"is_cancellable"`
 }
 
 func mapDBTicketsToResponse(rows []transport_db.GetUserTicketsByIDRow) []MyTicketResponse {
@@ -255,7 +255,7 @@ func mapDBTicketsToResponse(rows []transport_db.GetUserTicketsByIDRow) []MyTicke
 
 			DepartureTime: row.DepartureTime,
 			BusType:       row.BusType,
-			Price:         common.LowestUnitToAmount(row.BasePrice),
+			Price:         common.Money(row.BasePrice),
 			IsCancellable: row.IsCancellable,
 		})
 	}
@@ -289,7 +289,7 @@ func mapDbTripsForWeekToResponse(rows []transport_db.GetTripsForWeekWithStopsRow
 			ManualStatus:   common.TextToStringPointer(row.ManualStatus),
 			AvailableSeats: row.AvailableSeats,
 			TotalCapacity:  row.TotalCapacity,
-			BasePrice:      common.LowestUnitToAmount(row.BasePrice),
+			BasePrice:      common.Money(row.BasePrice),
 
 			Stops: stops,
 		})
@@ -360,7 +360,7 @@ func mapAdminTicketsToItem(rows []transport_db.GetTicketsForAdminRow) []AdminTic
 			RouteName:         row.RouteName,
 			PickupLocation:    row.PickupLocation,
 			DropoffLocation:   row.DropoffLocation,
-			Price:             row.Price,
+			Price:             common.Money(row.Price),
 		})
 	}
 	return items
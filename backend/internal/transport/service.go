@@ -309,6 +309,18 @@ func (s *Service) HoldSeats(ctx context.Context, userID uuid.UUID, userRole stri
 		}
 		expiry := time.Now().Add(holdDuration)
 
+		// students pay at confirm time, so the fare is reserved on their wallet
+		// now and they cannot hold seats they can't afford
+		var userWalletID uuid.UUID
+		reserveFare := strings.ToUpper(userRole) == "STUDENT" && trip.BasePrice > 0
+		if reserveFare {
+			userWallet, walletErr := s.wallet.GetOrCreateWallet(ctx, tx, userID)
+			if walletErr != nil {
+				return walletErr
+			}
+			userWalletID = userWallet.ID
+		}
+
 		for i := 0; i < req.Count; i++ {
 			// Decrement seat
 			_, decErr := qtx.DecreaseTripSeat(ctx, req.TripID)
@@ -332,6 +344,21 @@ func (s *Service) HoldSeats(ctx context.Context, userID uuid.UUID, userRole stri
 				return commonerrors.Wrap(commonerrors.ErrDatabase, holdErr)
 			}
 
+			if reserveFare {
+				if err := s.wallet.ReserveFunds(
+					ctx,
+					tx,
+					userWalletID,
+					int64(trip.BasePrice),
+					"TRANSPORT_BOOKING",
+					hold.ID.String(),
+					"Seat hold",
+					expiry,
+				); err != nil {
+					return err
+				}
+			}
+
 			holds = append(holds, HoldTicketResponse{
 				HoldID:    hold.ID,
 				ExpiresAt: expiry,
@@ -490,6 +517,11 @@ func (s *Service) ConfirmBatch(ctx context.Context, userID uuid.UUID, userRole s
 				return commonerrors.Wrap(commonerrors.ErrDatabase, err)
 			}
 
+			// frees the fare reserved at hold time for the debit below
+			if _, err := s.wallet.CaptureReservation(ctx, tx, item.HoldID.String()); err != nil {
+				return err
+			}
+
 			if isStudent && price > 0 {
 				err := s.wallet.ExecuteTransaction(
 					ctx,
@@ -628,18 +660,20 @@ func (s *Service) ReleaseAllHolds(ctx context.Context, userID uuid.UUID) error {
 	err := common.WithTransaction(ctx, s.dbPool, func(tx pgx.Tx) error {
 		qtx := s.q.WithTx(tx)
 
-		tripIDs, err := qtx.DeleteAllActiveHoldsByUserID(ctx, userID)
+		released, err := qtx.DeleteAllActiveHoldsByUserID(ctx, userID)
 		if err != nil {
 			return commonerrors.Wrap(commonerrors.ErrDatabase, err)
 		}
 
-		for _, tripID := range tripIDs {
-			if err = qtx.IncrementTripSeat(ctx, tripID); err != nil {
+		holdIDs := make([]string, 0, len(released))
+		for _, hold := range released {
+			if err = qtx.IncrementTripSeat(ctx, hold.TripID); err != nil {
 				return commonerrors.Wrap(commonerrors.ErrDatabase, err)
 			}
+			holdIDs = append(holdIDs, hold.ID.String())
 		}
 
-		return nil
+		return s.wallet.ReleaseReservations(ctx, tx, holdIDs)
 	})
 
 	if err != nil {
@@ -749,13 +783,13 @@ func GenerateRandomCode() string {
 // 5. REVENUE & TRANSACTIONS
 // ═══════════════════════════════════════════════════════════════════════════
 
-func (s *Service) GetRevenueTransactions(ctx context.Context, page, pageSize int) (*wallet.LedgerHistoryWithPagination, error) {
+func (s *Service) GetRevenueTransactions(ctx context.Context, params common.PaginationParams) (*wallet.LedgerHistoryWithPagination, error) {
 	revenueWalletID, err := s.wallet.GetSystemWalletByName(ctx, wallet.TransportSystemWallet, wallet.SystemWalletRevenue)
 	if err != nil {
 		return nil, commonerrors.Wrap(commonerrors.ErrInternal, err)
 	}
 
-	return s.wallet.GetWalletHistory(ctx, revenueWalletID, page, pageSize)
+	return s.wallet.GetWalletHistory(ctx, revenueWalletID, params)
 }
 func (s *Service) ExportTripData(ctx context.Context, tripIDs []uuid.UUID) ([]byte, error) {
 	rows, err := s.q.GetTripsForExport(ctx, tripIDs)
@@ -269,7 +269,7 @@ WHERE h.user_id = $1 AND h.expires_at > NOW();
 -- name: DeleteAllActiveHoldsByUserID :many
 DELETE FROM giki_transport.trip_holds
 WHERE user_id = $1 AND expires_at > NOW()
-RETURNING trip_id;
+RETURNING id, trip_id;
 
 
 -- name: GetUserTicketsByID :many
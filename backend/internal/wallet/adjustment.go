@@ -0,0 +1,224 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/hash-walker/giki-wallet/internal/common"
+	commonerrors "github.com/hash-walker/giki-wallet/internal/common/errors"
+	wallet "github.com/hash-walker/giki-wallet/internal/wallet/wallet_db"
+	"github.com/jackc/pgx/v5"
+)
+
+// ProposeAdjustment records a pending manual credit or debit. Nothing touches
+// the ledger until a different admin approves it.
+func (s *Service) ProposeAdjustment(ctx context.Context, requestedBy uuid.UUID, req ProposeAdjustmentRequest) (*LedgerAdjustment, error) {
+	amount := int64(common.AmountToLowestUnit(req.Amount))
+	if amount == 0 {
+		return nil, ErrInvalidAdjustment.WithDetails("amount", "must not be zero")
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, ErrReasonRequired
+	}
+
+	if req.UserID == uuid.Nil {
+		return nil, ErrInvalidAdjustment.WithDetails("user_id", "required")
+	}
+
+	if _, err := s.q.GetUserContact(ctx, req.UserID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrWalletNotFound
+		}
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	adj, err := s.q.CreateLedgerAdjustment(ctx, wallet.CreateLedgerAdjustmentParams{
+		UserID:      req.UserID,
+		Amount:      amount,
+		Reason:      reason,
+		RequestedBy: requestedBy,
+	})
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	return mapDBAdjustment(adj), nil
+}
+
+// ApproveAdjustment posts a pending adjustment as an ADMIN_ADJUSTMENT against
+// the liability wallet and marks it approved in the same transaction.
+func (s *Service) ApproveAdjustment(ctx context.Context, adjustmentID, approverID uuid.UUID, note string) (*LedgerAdjustment, error) {
+	liabilityWalletID, err := s.GetSystemWalletByName(ctx, GikiWallet, SystemWalletLiability)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *LedgerAdjustment
+
+	err = common.WithTransaction(ctx, s.dbPool, func(tx pgx.Tx) error {
+		walletQ := s.q.WithTx(tx)
+
+		adj, err := s.lockPendingAdjustment(ctx, walletQ, adjustmentID)
+		if err != nil {
+			return err
+		}
+
+		if adj.RequestedBy == approverID {
+			return ErrSelfApproval
+		}
+
+		userWallet, err := s.GetOrCreateWallet(ctx, tx, adj.UserID)
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabase, err)
+		}
+
+		// positive adjustments move money out of the liability wallet into the user's
+		legs := []JournalLeg{
+			{WalletID: liabilityWalletID, Amount: -adj.Amount},
+			{WalletID: userWallet.ID, Amount: adj.Amount},
+		}
+
+		headerID, err := s.PostJournalEntry(ctx, tx, JournalEntry{
+			Type:        TransactionTypeAdminAdjustment,
+			ReferenceID: adj.ID.String(),
+			Description: fmt.Sprintf("Admin adjustment: %s", adj.Reason),
+			Legs:        legs,
+		})
+		if err != nil {
+			return err
+		}
+
+		approved, err := walletQ.ApproveLedgerAdjustment(ctx, wallet.ApproveLedgerAdjustmentParams{
+			ID:            adj.ID,
+			ReviewedBy:    common.GoogleUUIDtoPgUUID(approverID, true),
+			ReviewNote:    common.StringToText(strings.TrimSpace(note)),
+			TransactionID: common.GoogleUUIDtoPgUUID(headerID, true),
+		})
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabase, err)
+		}
+
+		result = mapDBAdjustment(approved)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// RejectAdjustment closes a pending adjustment without posting anything
+func (s *Service) RejectAdjustment(ctx context.Context, adjustmentID, reviewerID uuid.UUID, note string) (*LedgerAdjustment, error) {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return nil, ErrReasonRequired
+	}
+
+	var result *LedgerAdjustment
+
+	err := common.WithTransaction(ctx, s.dbPool, func(tx pgx.Tx) error {
+		walletQ := s.q.WithTx(tx)
+
+		adj, err := s.lockPendingAdjustment(ctx, walletQ, adjustmentID)
+		if err != nil {
+			return err
+		}
+
+		if adj.RequestedBy == reviewerID {
+			return ErrSelfApproval
+		}
+
+		rejected, err := walletQ.RejectLedgerAdjustment(ctx, wallet.RejectLedgerAdjustmentParams{
+			ID:         adj.ID,
+			ReviewedBy: common.GoogleUUIDtoPgUUID(reviewerID, true),
+			ReviewNote: common.StringToText(note),
+		})
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabase, err)
+		}
+
+		result = mapDBAdjustment(rejected)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *Service) ListAdjustments(ctx context.Context, status string, page, pageSize int) ([]LedgerAdjustment, int64, error) {
+	rows, err := s.q.ListLedgerAdjustments(ctx, wallet.ListLedgerAdjustmentsParams{
+		Status: strings.ToUpper(status),
+		Limit:  int32(pageSize),
+		Offset: int32((page - 1) * pageSize),
+	})
+	if err != nil {
+		return nil, 0, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	var totalCount int64
+	items := make([]LedgerAdjustment, 0, len(rows))
+	for _, r := range rows {
+		totalCount = r.TotalCount
+		items = append(items, LedgerAdjustment{
+			ID:              r.ID,
+			UserID:          r.UserID,
+			UserName:        r.UserName,
+			UserEmail:       r.UserEmail,
+			Amount:          common.Money(r.Amount),
+			Reason:          r.Reason,
+			Status:          r.Status,
+			RequestedBy:     r.RequestedBy,
+			RequestedByName: r.RequestedByName,
+			ReviewedBy:      common.PgUUIDToUUIDPointer(r.ReviewedBy),
+			ReviewedByName:  common.TextToStringPointer(r.ReviewedByName),
+			ReviewNote:      common.TextToString(r.ReviewNote),
+			TransactionID:   common.PgUUIDToUUIDPointer(r.TransactionID),
+			CreatedAt:       r.CreatedAt,
+			ReviewedAt:      common.TimestamptzToTimePointer(r.ReviewedAt),
+		})
+	}
+
+	return items, totalCount, nil
+}
+
+func (s *Service) lockPendingAdjustment(ctx context.Context, walletQ *wallet.Queries, adjustmentID uuid.UUID) (wallet.GikiWalletLedgerAdjustment, error) {
+	adj, err := walletQ.GetLedgerAdjustmentForUpdate(ctx, adjustmentID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return adj, ErrAdjustmentNotFound
+		}
+		return adj, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	if adj.Status != AdjustmentStatusPending {
+		return adj, ErrAdjustmentNotPending.WithDetails("status", adj.Status)
+	}
+
+	return adj, nil
+}
+
+func mapDBAdjustment(a wallet.GikiWalletLedgerAdjustment) *LedgerAdjustment {
+	return &LedgerAdjustment{
+		ID:            a.ID,
+		UserID:        a.UserID,
+		Amount:        common.Money(a.Amount),
+		Reason:        a.Reason,
+		Status:        a.Status,
+		RequestedBy:   a.RequestedBy,
+		ReviewedBy:    common.PgUUIDToUUIDPointer(a.ReviewedBy),
+		ReviewNote:    common.TextToString(a.ReviewNote),
+		TransactionID: common.PgUUIDToUUIDPointer(a.TransactionID),
+		CreatedAt:     a.CreatedAt,
+		ReviewedAt:    common.TimestamptzToTimePointer(a.ReviewedAt),
+	}
+}
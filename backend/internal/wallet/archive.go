@@ -0,0 +1,395 @@
+package wallet
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hash-walker/giki-wallet/internal/common"
+	commonerrors "github.com/hash-walker/giki-wallet/internal/common/errors"
+	wallet "github.com/hash-walker/giki-wallet/internal/wallet/wallet_db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	ledgerArchiveManifestRecord = "manifest"
+
+	// ledgerArchivePageSize is how many entries are read per query while a
+	// month is exported, so a month never has to fit in memory
+	ledgerArchivePageSize = 5000
+)
+
+// ledgerArchiveEntry is one line of an archive file. Amounts are in paisa and
+// row_hash is the entry's hash from the ledger chain, so the integrity of each
+// entry can still be checked against the ledger secret.
+type ledgerArchiveEntry struct {
+	ID                   uuid.UUID `json:"id"`
+	WalletID             uuid.UUID `json:"wallet_id"`
+	TransactionID        uuid.UUID `json:"transaction_id"`
+	TransactionType      string    `json:"transaction_type"`
+	ReferenceID          string    `json:"reference_id"`
+	Description          string    `json:"description,omitempty"`
+	AmountPaisa          int64     `json:"amount_paisa"`
+	BalanceAfterPaisa    int64     `json:"balance_after_paisa"`
+	RowHash              string    `json:"row_hash"`
+	CreatedAt            time.Time `json:"created_at"`
+	TransactionCreatedAt time.Time `json:"transaction_created_at"`
+}
+
+// ledgerArchiveManifest is the last line of an archive file. ContentSHA256
+// covers every line before it, so the file can be checked on its own.
+type ledgerArchiveManifest struct {
+	Record         string    `json:"record"`
+	Partition      string    `json:"partition"`
+	PeriodStart    time.Time `json:"period_start"`
+	PeriodEnd      time.Time `json:"period_end"`
+	RowCount       int64     `json:"row_count"`
+	AmountSumPaisa int64     `json:"amount_sum_paisa"`
+	ContentSHA256  string    `json:"content_sha256"`
+	GeneratedAt    time.Time `json:"generated_at"`
+}
+
+// ArchiveLedgerPartition exports one cold month of the ledger to a gzipped
+// JSON lines file in the archive directory, next to a sha256sum file, and
+// records the hashes. actorID is nil when the maintenance job archives the
+// month.
+//
+// The partition is left attached on purpose: the integrity check walks each
+// wallet's balance chain from its first entry, and statement opening balances
+// and balance checkpoints sum every earlier entry, so all of them would break
+// once a month is missing from the ledger.
+func (s *Service) ArchiveLedgerPartition(ctx context.Context, month time.Time, actorID *uuid.UUID) (*LedgerArchive, error) {
+	start := ledgerMonthStart(month)
+	end := start.AddDate(0, 1, 0)
+	partition := ledgerPartitionName(start)
+
+	if !isColdLedgerMonth(start, time.Now()) {
+		return nil, ErrLedgerPartitionNotCold.WithDetails("month", start.Format("2006-01"))
+	}
+
+	_, err := s.q.GetLedgerArchiveByPartition(ctx, partition)
+	if err == nil {
+		return nil, ErrLedgerAlreadyArchived.WithDetails("partition", partition)
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	if err := os.MkdirAll(s.archiveDir, 0o750); err != nil {
+		return nil, commonerrors.Wrap(ErrLedgerArchiveIO, err)
+	}
+
+	fileName := partition + ".jsonl.gz"
+	manifest, fileSHA, err := s.writeLedgerArchive(ctx, filepath.Join(s.archiveDir, fileName), partition, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	var archivedBy uuid.UUID
+	if actorID != nil {
+		archivedBy = *actorID
+	}
+
+	archive, err := s.q.CreateLedgerArchive(ctx, wallet.CreateLedgerArchiveParams{
+		PartitionName: partition,
+		PeriodStart:   start,
+		PeriodEnd:     end,
+		FileName:      fileName,
+		RowCount:      manifest.RowCount,
+		AmountSum:     manifest.AmountSumPaisa,
+		ContentSha256: manifest.ContentSHA256,
+		FileSha256:    fileSHA,
+		ArchivedBy:    common.GoogleUUIDtoPgUUID(archivedBy, actorID != nil),
+	})
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	return mapDBLedgerArchive(archive), nil
+}
+
+// writeLedgerArchive streams the month into the archive through a temporary
+// file so a crash never leaves a truncated archive under the final name, and
+// returns the manifest and the SHA-256 of the compressed file
+func (s *Service) writeLedgerArchive(ctx context.Context, path, partition string, start, end time.Time) (*ledgerArchiveManifest, string, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, "", commonerrors.Wrap(ErrLedgerArchiveIO, err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	fileHash := sha256.New()
+	gz := gzip.NewWriter(io.MultiWriter(tmp, fileHash))
+
+	contentHash := sha256.New()
+	count, sum, err := s.writeLedgerMonth(ctx, io.MultiWriter(gz, contentHash), start, end)
+	if err != nil {
+		return nil, "", err
+	}
+	if count == 0 {
+		return nil, "", ErrLedgerPartitionEmpty.WithDetails("partition", partition)
+	}
+
+	manifest := &ledgerArchiveManifest{
+		Record:         ledgerArchiveManifestRecord,
+		Partition:      partition,
+		PeriodStart:    start,
+		PeriodEnd:      end,
+		RowCount:       count,
+		AmountSumPaisa: sum,
+		ContentSHA256:  hex.EncodeToString(contentHash.Sum(nil)),
+		GeneratedAt:    time.Now().UTC(),
+	}
+
+	if err := json.NewEncoder(gz).Encode(manifest); err != nil {
+		return nil, "", commonerrors.Wrap(ErrLedgerArchiveIO, err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, "", commonerrors.Wrap(ErrLedgerArchiveIO, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return nil, "", commonerrors.Wrap(ErrLedgerArchiveIO, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, "", commonerrors.Wrap(ErrLedgerArchiveIO, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, "", commonerrors.Wrap(ErrLedgerArchiveIO, err)
+	}
+
+	fileSHA := hex.EncodeToString(fileHash.Sum(nil))
+
+	// sha256sum -c format, for checking a copy without the database
+	checksum := fmt.Sprintf("%s  %s\n", fileSHA, filepath.Base(path))
+	if err := os.WriteFile(path+".sha256", []byte(checksum), 0o640); err != nil {
+		return nil, "", commonerrors.Wrap(ErrLedgerArchiveIO, err)
+	}
+
+	return manifest, fileSHA, nil
+}
+
+// writeLedgerMonth writes every ledger entry in [start, end) to w a keyset page
+// at a time and returns the number of entries and the sum of their amounts
+func (s *Service) writeLedgerMonth(ctx context.Context, w io.Writer, start, end time.Time) (int64, int64, error) {
+	var cursorAt pgtype.Timestamptz
+	var cursorID pgtype.UUID
+	var count, sum int64
+
+	for {
+		rows, err := s.q.GetLedgerArchiveEntries(ctx, wallet.GetLedgerArchiveEntriesParams{
+			StartDate:       start,
+			EndDate:         end,
+			CursorCreatedAt: cursorAt,
+			CursorID:        cursorID,
+			Limit:           ledgerArchivePageSize,
+		})
+		if err != nil {
+			return 0, 0, commonerrors.Wrap(ErrDatabase, err)
+		}
+
+		pageCount, pageSum, err := writeLedgerArchiveEntries(w, rows)
+		if err != nil {
+			return 0, 0, commonerrors.Wrap(ErrLedgerArchiveIO, err)
+		}
+		count += pageCount
+		sum += pageSum
+
+		if len(rows) < ledgerArchivePageSize {
+			return count, sum, nil
+		}
+		last := rows[len(rows)-1]
+		cursorAt = pgtype.Timestamptz{Time: last.CreatedAt, Valid: true}
+		cursorID = common.GoogleUUIDtoPgUUID(last.ID, true)
+	}
+}
+
+// writeLedgerArchiveEntries writes one JSON line per entry and returns the
+// number of entries and the sum of their amounts. The output only depends on
+// the rows, so exporting the same month again gives the same content hash.
+func writeLedgerArchiveEntries(w io.Writer, rows []wallet.GetLedgerArchiveEntriesRow) (int64, int64, error) {
+	enc := json.NewEncoder(w)
+
+	var count, sum int64
+	for _, r := range rows {
+		entry := ledgerArchiveEntry{
+			ID:                   r.ID,
+			WalletID:             r.WalletID,
+			TransactionID:        r.TransactionID,
+			TransactionType:      r.Type,
+			ReferenceID:          r.ReferenceID,
+			Description:          r.Description.String,
+			AmountPaisa:          r.Amount,
+			BalanceAfterPaisa:    r.BalanceAfter,
+			RowHash:              r.RowHash,
+			CreatedAt:            r.CreatedAt.UTC(),
+			TransactionCreatedAt: r.TransactionCreatedAt.UTC(),
+		}
+
+		if err := enc.Encode(entry); err != nil {
+			return 0, 0, err
+		}
+
+		count++
+		sum += r.Amount
+	}
+
+	return count, sum, nil
+}
+
+// VerifyLedgerArchive checks an archive file against the hashes recorded when
+// it was written, against its own manifest, and against the ledger partition it
+// was exported from. The archive is marked verified only if all three agree.
+func (s *Service) VerifyLedgerArchive(ctx context.Context, archiveID uuid.UUID) (*LedgerArchiveVerification, error) {
+	archive, err := s.q.GetLedgerArchive(ctx, archiveID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrLedgerArchiveNotFound
+		}
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	res := &LedgerArchiveVerification{
+		Archive:  mapDBLedgerArchive(archive),
+		Findings: []string{},
+	}
+
+	f, err := os.Open(filepath.Join(s.archiveDir, archive.FileName))
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrLedgerArchiveIO, err)
+	}
+	defer f.Close()
+
+	// hash the compressed bytes as they are read, then whatever is left after
+	// the gzip stream, so the file is only read once and never held in memory
+	fileHash := sha256.New()
+	contentSHA, archivedRows, manifest, readErr := readLedgerArchive(io.TeeReader(f, fileHash))
+	if _, err := io.Copy(fileHash, f); err != nil {
+		return nil, commonerrors.Wrap(ErrLedgerArchiveIO, err)
+	}
+
+	res.FileIntact = hex.EncodeToString(fileHash.Sum(nil)) == archive.FileSha256
+	if !res.FileIntact {
+		res.Findings = append(res.Findings, "compressed file does not match the recorded file hash")
+	}
+
+	if readErr != nil {
+		res.Findings = append(res.Findings, fmt.Sprintf("archive cannot be read: %v", readErr))
+	} else {
+		res.ContentIntact = contentSHA == archive.ContentSha256 && manifest.ContentSHA256 == archive.ContentSha256
+		res.ArchivedRows = archivedRows
+
+		if !res.ContentIntact {
+			res.Findings = append(res.Findings, "archived entries do not match the recorded content hash")
+		}
+		if res.ArchivedRows != archive.RowCount || manifest.RowCount != archive.RowCount {
+			res.Findings = append(res.Findings, fmt.Sprintf("archive holds %d entries, %d were recorded", res.ArchivedRows, archive.RowCount))
+		}
+	}
+
+	// export the month again and compare, which catches both a bad archive
+	// and ledger entries that changed after the month was archived
+	ledgerHash := sha256.New()
+	ledgerRows, _, err := s.writeLedgerMonth(ctx, ledgerHash, archive.PeriodStart, archive.PeriodEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	res.LedgerRows = ledgerRows
+	res.MatchesLedger = hex.EncodeToString(ledgerHash.Sum(nil)) == archive.ContentSha256
+	if !res.MatchesLedger {
+		res.Findings = append(res.Findings, fmt.Sprintf("ledger partition %s no longer matches the archive", archive.PartitionName))
+	}
+
+	res.Verified = res.FileIntact && res.ContentIntact && res.MatchesLedger && len(res.Findings) == 0
+	if res.Verified {
+		verified, err := s.q.MarkLedgerArchiveVerified(ctx, archiveID)
+		if err != nil {
+			return nil, commonerrors.Wrap(ErrDatabase, err)
+		}
+		res.Archive = mapDBLedgerArchive(verified)
+	}
+
+	return res, nil
+}
+
+// OpenLedgerArchive opens an archive file for download
+func (s *Service) OpenLedgerArchive(ctx context.Context, archiveID uuid.UUID) (*LedgerArchive, *os.File, error) {
+	archive, err := s.q.GetLedgerArchive(ctx, archiveID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, ErrLedgerArchiveNotFound
+		}
+		return nil, nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	f, err := os.Open(filepath.Join(s.archiveDir, archive.FileName))
+	if err != nil {
+		return nil, nil, commonerrors.Wrap(ErrLedgerArchiveIO, err)
+	}
+
+	return mapDBLedgerArchive(archive), f, nil
+}
+
+// readLedgerArchive decompresses an archive a line at a time and returns the
+// SHA-256 and count of the entry lines along with the trailing manifest
+func readLedgerArchive(r io.Reader) (string, int64, *ledgerArchiveManifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	defer gz.Close()
+
+	contentHash := sha256.New()
+	var rows int64
+	var last []byte
+
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if last != nil {
+			contentHash.Write(last)
+			contentHash.Write([]byte{'\n'})
+			rows++
+		}
+		last = append(last[:0], scanner.Bytes()...)
+	}
+	if err := scanner.Err(); err != nil {
+		return "", 0, nil, err
+	}
+
+	var manifest ledgerArchiveManifest
+	if last == nil || json.Unmarshal(last, &manifest) != nil || manifest.Record != ledgerArchiveManifestRecord {
+		return "", 0, nil, fmt.Errorf("manifest line is missing")
+	}
+
+	return hex.EncodeToString(contentHash.Sum(nil)), rows, &manifest, nil
+}
+
+func mapDBLedgerArchive(a wallet.GikiWalletLedgerArchive) *LedgerArchive {
+	return &LedgerArchive{
+		ID:            a.ID,
+		PartitionName: a.PartitionName,
+		PeriodStart:   a.PeriodStart,
+		PeriodEnd:     a.PeriodEnd,
+		FileName:      a.FileName,
+		RowCount:      a.RowCount,
+		AmountSum:     common.Money(a.AmountSum),
+		ContentSHA256: a.ContentSha256,
+		FileSHA256:    a.FileSha256,
+		ArchivedBy:    common.PgUUIDToUUIDPointer(a.ArchivedBy),
+		CreatedAt:     a.CreatedAt,
+		VerifiedAt:    common.TimestamptzToTimePointer(a.VerifiedAt),
+	}
+}
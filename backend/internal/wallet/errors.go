@@ -13,8 +13,69 @@ var (
 	ErrInsufficientBalance = errors.New("INSUFFICIENT_BALANCE", http.StatusConflict, "Insufficient wallet balance")
 	ErrInsufficientFunds   = errors.New("INSUFFICIENT_FUNDS", http.StatusConflict, "Insufficient funds")
 
+	// Wallet Status Errors
+	ErrWalletFrozen              = errors.New("WALLET_FROZEN", http.StatusForbidden, "Wallet is frozen and cannot be used for payments")
+	ErrWalletSuspended           = errors.New("WALLET_SUSPENDED", http.StatusForbidden, "Wallet is suspended and cannot be used for payments")
+	ErrWalletClosed              = errors.New("WALLET_CLOSED", http.StatusForbidden, "Wallet is closed")
+	ErrRecipientWalletRestricted = errors.New("RECIPIENT_WALLET_RESTRICTED", http.StatusForbidden, "Wallet cannot receive this transaction in its current status")
+	ErrInvalidWalletStatus       = errors.New("INVALID_WALLET_STATUS", http.StatusBadRequest, "Invalid wallet status change")
+	ErrWalletStatusForbidden     = errors.New("WALLET_STATUS_FORBIDDEN", http.StatusForbidden, "Only a super admin can lift a wallet suspension")
+	ErrWalletNotEmpty            = errors.New("WALLET_NOT_EMPTY", http.StatusConflict, "Wallet must have a zero balance before it can be closed")
+
 	// Ledger Errors
-	ErrDuplicateLedgerEntry = errors.New("DUPLICATE_LEDGER_ENTRY", http.StatusConflict, "Duplicate ledger entry")
+	ErrDuplicateLedgerEntry    = errors.New("DUPLICATE_LEDGER_ENTRY", http.StatusConflict, "Duplicate ledger entry")
+	ErrLedgerIntegrityViolated = errors.New("LEDGER_INTEGRITY_VIOLATED", http.StatusInternalServerError, "Ledger integrity check failed")
+	ErrInvalidJournalEntry     = errors.New("INVALID_JOURNAL_ENTRY", http.StatusBadRequest, "Journal entry is invalid")
+	ErrBalanceDrift            = errors.New("BALANCE_DRIFT", http.StatusInternalServerError, "Wallet balance does not match the ledger")
+
+	// Integrity Errors
+	ErrIntegrityRunNotFound = errors.New("INTEGRITY_RUN_NOT_FOUND", http.StatusNotFound, "Ledger integrity run not found")
+
+	// Transfer Errors
+	ErrRecipientNotFound     = errors.New("RECIPIENT_NOT_FOUND", http.StatusNotFound, "No user found with that email or registration number")
+	ErrRecipientInactive     = errors.New("RECIPIENT_INACTIVE", http.StatusUnprocessableEntity, "Recipient account cannot receive transfers")
+	ErrSelfTransfer          = errors.New("SELF_TRANSFER", http.StatusBadRequest, "You cannot transfer to your own wallet")
+	ErrInvalidTransfer       = errors.New("INVALID_TRANSFER", http.StatusBadRequest, "Transfer request is invalid")
+	ErrTransferLimitExceeded = errors.New("TRANSFER_LIMIT_EXCEEDED", http.StatusUnprocessableEntity, "Daily transfer limit exceeded")
+	ErrDuplicateTransfer     = errors.New("DUPLICATE_TRANSFER", http.StatusConflict, "This idempotency key was already used for a different transfer")
+
+	// Reversal Errors
+	ErrTransactionNotFound   = errors.New("TRANSACTION_NOT_FOUND", http.StatusNotFound, "Transaction not found")
+	ErrAlreadyReversed       = errors.New("TRANSACTION_ALREADY_REVERSED", http.StatusConflict, "Transaction has already been reversed")
+	ErrCannotReverseReversal = errors.New("CANNOT_REVERSE_REVERSAL", http.StatusBadRequest, "A reversal cannot itself be reversed")
+	ErrReasonRequired        = errors.New("REASON_REQUIRED", http.StatusBadRequest, "A reason is required")
+
+	// Adjustment Errors
+	ErrInvalidAdjustment    = errors.New("INVALID_ADJUSTMENT", http.StatusBadRequest, "Adjustment request is invalid")
+	ErrAdjustmentNotFound   = errors.New("ADJUSTMENT_NOT_FOUND", http.StatusNotFound, "Adjustment request not found")
+	ErrAdjustmentNotPending = errors.New("ADJUSTMENT_NOT_PENDING", http.StatusConflict, "Adjustment request has already been reviewed")
+	ErrSelfApproval         = errors.New("SELF_APPROVAL", http.StatusForbidden, "You cannot review a request you proposed")
+
+	// Payout Errors
+	ErrInvalidPayout        = errors.New("INVALID_PAYOUT", http.StatusBadRequest, "Payout request is invalid")
+	ErrPayoutNotFound       = errors.New("PAYOUT_NOT_FOUND", http.StatusNotFound, "Payout request not found")
+	ErrPayoutAlreadyPending = errors.New("PAYOUT_ALREADY_PENDING", http.StatusConflict, "You already have a payout request waiting for review")
+	ErrPayoutNotPending     = errors.New("PAYOUT_NOT_PENDING", http.StatusConflict, "Payout request has already been reviewed")
+	ErrNothingToPayout      = errors.New("NOTHING_TO_PAYOUT", http.StatusConflict, "Wallet has no balance to pay out")
+	ErrNoPayoutsToBatch     = errors.New("NO_PAYOUTS_TO_BATCH", http.StatusConflict, "There are no approved payouts waiting to be exported")
+	ErrPayoutBatchNotFound  = errors.New("PAYOUT_BATCH_NOT_FOUND", http.StatusNotFound, "Payout batch not found")
+	ErrPayoutBatchCompleted = errors.New("PAYOUT_BATCH_COMPLETED", http.StatusConflict, "Payout batch has already been marked as paid")
+
+	// Spend Limit Errors
+	ErrSpendLimitExceeded = errors.New("SPEND_LIMIT_EXCEEDED", http.StatusUnprocessableEntity, "This payment exceeds your wallet spending limit")
+	ErrInvalidSpendLimit  = errors.New("INVALID_SPEND_LIMIT", http.StatusBadRequest, "Spend limit request is invalid")
+
+	// Ledger Archive Errors
+	ErrInvalidLedgerMonth     = errors.New("INVALID_LEDGER_MONTH", http.StatusBadRequest, "Month must be in YYYY-MM format")
+	ErrLedgerPartitionNotCold = errors.New("LEDGER_PARTITION_NOT_COLD", http.StatusConflict, "Only months older than the last three can be archived")
+	ErrLedgerPartitionEmpty   = errors.New("LEDGER_PARTITION_EMPTY", http.StatusConflict, "There are no ledger entries in that month")
+	ErrLedgerAlreadyArchived  = errors.New("LEDGER_ALREADY_ARCHIVED", http.StatusConflict, "That month has already been archived")
+	ErrLedgerArchiveNotFound  = errors.New("LEDGER_ARCHIVE_NOT_FOUND", http.StatusNotFound, "Ledger archive not found")
+	ErrLedgerArchiveIO        = errors.New("LEDGER_ARCHIVE_IO_ERROR", http.StatusInternalServerError, "Ledger archive file could not be read or written")
+
+	// Statement Errors
+	ErrInvalidStatementRange  = errors.New("INVALID_STATEMENT_RANGE", http.StatusBadRequest, "Statement date range is invalid")
+	ErrInvalidStatementFormat = errors.New("INVALID_STATEMENT_FORMAT", http.StatusBadRequest, "Statement format must be json, csv or pdf")
 
 	// System Wallet Errors
 	ErrSystemWalletNotFound = errors.New("SYSTEM_WALLET_NOT_FOUND", http.StatusInternalServerError, "System wallet not found")
@@ -1,8 +1,16 @@
 package wallet
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/hash-walker/giki-wallet/internal/audit"
 	"github.com/hash-walker/giki-wallet/internal/auth"
 	"github.com/hash-walker/giki-wallet/internal/common"
 	commonerrors "github.com/hash-walker/giki-wallet/internal/common/errors"
@@ -11,11 +19,13 @@ import (
 
 type Handler struct {
 	service *Service
+	audit   *audit.Service
 }
 
-func NewHandler(service *Service) *Handler {
+func NewHandler(service *Service, audit *audit.Service) *Handler {
 	return &Handler{
 		service: service,
+		audit:   audit,
 	}
 }
 
@@ -46,7 +56,175 @@ func (h *Handler) GetHistory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res, err := h.service.GetUserHistory(r.Context(), userID)
+	var params common.PaginationParams
+	if err := params.Bind(r); err != nil {
+		appErr := commonerrors.New("INVALID_REQUEST", http.StatusBadRequest, err.Error())
+		middleware.HandleError(w, appErr, requestID)
+		return
+	}
+
+	res, err := h.service.GetUserHistory(r.Context(), userID, params)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	common.ResponseWithJSON(w, http.StatusOK, res, requestID)
+}
+
+// GetStatement returns the wallet statement for a date range as JSON, CSV or PDF.
+// The range is either ?month=YYYY-MM or start_date/end_date in RFC3339, and
+// defaults to the current month so far.
+func (h *Handler) GetStatement(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		middleware.HandleError(w, commonerrors.ErrUnauthorized, requestID)
+		return
+	}
+
+	start, end, err := parseStatementRange(r, h.service.loc)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "csv" && format != "pdf" {
+		middleware.HandleError(w, ErrInvalidStatementFormat, requestID)
+		return
+	}
+
+	statement, err := h.service.GetStatement(r.Context(), userID, start, end)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	var (
+		data        []byte
+		contentType string
+	)
+
+	switch format {
+	case "json":
+		common.ResponseWithJSON(w, http.StatusOK, statement, requestID)
+		return
+	case "csv":
+		data, err = RenderStatementCSV(statement)
+		contentType = "text/csv"
+	case "pdf":
+		data, err = RenderStatementPDF(statement)
+		contentType = "application/pdf"
+	}
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", StatementFileName(statement, format)))
+	w.Write(data)
+}
+
+func (h *Handler) GetStatementSubscription(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		middleware.HandleError(w, commonerrors.ErrUnauthorized, requestID)
+		return
+	}
+
+	res, err := h.service.GetStatementSubscription(r.Context(), userID)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	common.ResponseWithJSON(w, http.StatusOK, res, requestID)
+}
+
+func (h *Handler) UpdateStatementSubscription(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		middleware.HandleError(w, commonerrors.ErrUnauthorized, requestID)
+		return
+	}
+
+	var params StatementSubscription
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidJSON, err), requestID)
+		return
+	}
+
+	res, err := h.service.SetStatementSubscription(r.Context(), userID, params.Enabled)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	common.ResponseWithJSON(w, http.StatusOK, res, requestID)
+}
+
+// parseStatementRange reads the statement period; months start at midnight in
+// the app's timezone, not the server's
+func parseStatementRange(r *http.Request, loc *time.Location) (time.Time, time.Time, error) {
+	query := r.URL.Query()
+	now := time.Now().In(loc)
+
+	if month := query.Get("month"); month != "" {
+		start, err := time.ParseInLocation("2006-01", month, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidStatementRange.WithDetails("month", "expected YYYY-MM")
+		}
+		return start, start.AddDate(0, 1, 0), nil
+	}
+
+	start := monthStart(now)
+	end := now
+
+	if startStr := query.Get("start_date"); startStr != "" {
+		t, err := time.Parse(time.RFC3339, startStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidStatementRange.WithDetails("start_date", "expected ISO8601")
+		}
+		start = t
+	}
+
+	if endStr := query.Get("end_date"); endStr != "" {
+		t, err := time.Parse(time.RFC3339, endStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidStatementRange.WithDetails("end_date", "expected ISO8601")
+		}
+		end = t
+	}
+
+	return start, end, nil
+}
+
+func (h *Handler) Transfer(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		middleware.HandleError(w, commonerrors.ErrUnauthorized, requestID)
+		return
+	}
+
+	var params TransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidJSON, err), requestID)
+		return
+	}
+
+	res, err := h.service.TransferFunds(r.Context(), userID, params)
 	if err != nil {
 		middleware.HandleError(w, err, requestID)
 		return
@@ -65,14 +243,7 @@ func (h *Handler) GetAdminTransactions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	history, total, err := h.service.GetAdminRevenueTransactions(
-		r.Context(),
-		params.Page,
-		params.PageSize,
-		params.StartDate,
-		params.EndDate,
-		params.Search,
-	)
+	history, nextCursor, err := h.service.GetAdminRevenueTransactions(r.Context(), params)
 	if err != nil {
 		middleware.HandleError(w, err, requestID)
 		return
@@ -91,12 +262,746 @@ func (h *Handler) GetAdminTransactions(w http.ResponseWriter, r *http.Request) {
 	response := map[string]interface{}{
 		"data": history,
 		"meta": map[string]interface{}{
-			"page":          params.Page,
-			"page_size":     params.PageSize,
-			"total_records": total,
-			"weekly_stats":  stats,
+			"page_size":    params.PageSize,
+			"next_cursor":  nextCursor,
+			"has_more":     nextCursor != "",
+			"weekly_stats": stats,
 		},
 	}
 
 	common.ResponseWithJSON(w, http.StatusOK, response, requestID)
 }
+
+// AdminGetLedgerIntegrity returns the latest completed full-ledger run, or
+// checks a single wallet on the spot when ?wallet_id is given
+func (h *Handler) AdminGetLedgerIntegrity(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	if walletIDStr := r.URL.Query().Get("wallet_id"); walletIDStr != "" {
+		walletID, err := uuid.Parse(walletIDStr)
+		if err != nil {
+			middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidUUID, err), requestID)
+			return
+		}
+
+		report, err := h.service.VerifyWalletIntegrity(r.Context(), walletID)
+		if err != nil {
+			middleware.HandleError(w, err, requestID)
+			return
+		}
+
+		common.ResponseWithJSON(w, http.StatusOK, report, requestID)
+		return
+	}
+
+	run, err := h.service.GetLatestIntegrityRun(r.Context())
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	common.ResponseWithJSON(w, http.StatusOK, run, requestID)
+}
+
+// AdminRequestLedgerIntegrityCheck queues a full-ledger run for the worker;
+// poll the returned run for its result
+func (h *Handler) AdminRequestLedgerIntegrityCheck(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	actorID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		middleware.HandleError(w, commonerrors.ErrUnauthorized, requestID)
+		return
+	}
+
+	run, err := h.service.RequestLedgerIntegrityCheck(r.Context(), actorID)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	common.ResponseWithJSON(w, http.StatusAccepted, run, requestID)
+}
+
+func (h *Handler) AdminGetLedgerIntegrityRun(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	runID, err := uuid.Parse(chi.URLParam(r, "run_id"))
+	if err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidUUID, err), requestID)
+		return
+	}
+
+	run, err := h.service.GetIntegrityRun(r.Context(), runID)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	common.ResponseWithJSON(w, http.StatusOK, run, requestID)
+}
+
+// AdminGetTrialBalance returns the trial balance now, or at ?as_of when given
+func (h *Handler) AdminGetTrialBalance(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	var asOf *time.Time
+	if asOfStr := r.URL.Query().Get("as_of"); asOfStr != "" {
+		t, err := time.Parse(time.RFC3339, asOfStr)
+		if err != nil {
+			appErr := commonerrors.New("INVALID_REQUEST", http.StatusBadRequest, "invalid as_of format, expected ISO8601")
+			middleware.HandleError(w, appErr, requestID)
+			return
+		}
+		asOf = &t
+	}
+
+	report, err := h.service.GetTrialBalance(r.Context(), asOf)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	common.ResponseWithJSON(w, http.StatusOK, report, requestID)
+}
+
+func (h *Handler) AdminGetUserWallet(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	userID, err := uuid.Parse(chi.URLParam(r, "user_id"))
+	if err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidUUID, err), requestID)
+		return
+	}
+
+	res, err := h.service.GetUserWalletOverview(r.Context(), userID)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	common.ResponseWithJSON(w, http.StatusOK, res, requestID)
+}
+
+func (h *Handler) AdminUpdateWalletStatus(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	userID, err := uuid.Parse(chi.URLParam(r, "user_id"))
+	if err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidUUID, err), requestID)
+		return
+	}
+
+	actorRole, ok := auth.GetUserRoleFromContext(r.Context())
+	if !ok {
+		middleware.HandleError(w, commonerrors.ErrUnauthorized, requestID)
+		return
+	}
+
+	var params UpdateWalletStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidJSON, err), requestID)
+		return
+	}
+
+	res, err := h.service.UpdateWalletStatus(r.Context(), userID, params, actorRole)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	h.logAdminAction(r.Context(), r, audit.ActionAdminUpdateWalletStatus, &userID, map[string]interface{}{
+		"wallet_id":       res.ID,
+		"previous_status": res.PreviousStatus,
+		"new_status":      res.Status,
+		"reason":          res.Reason,
+	})
+
+	common.ResponseWithJSON(w, http.StatusOK, res, requestID)
+}
+
+func (h *Handler) AdminGetSpendLimits(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	userID, err := uuid.Parse(chi.URLParam(r, "user_id"))
+	if err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidUUID, err), requestID)
+		return
+	}
+
+	res, err := h.service.GetSpendLimits(r.Context(), userID)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	common.ResponseWithJSON(w, http.StatusOK, res, requestID)
+}
+
+func (h *Handler) AdminSetSpendLimits(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	userID, err := uuid.Parse(chi.URLParam(r, "user_id"))
+	if err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidUUID, err), requestID)
+		return
+	}
+
+	actorID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		middleware.HandleError(w, commonerrors.ErrUnauthorized, requestID)
+		return
+	}
+
+	var params SpendLimitOverrideRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidJSON, err), requestID)
+		return
+	}
+
+	res, err := h.service.SetSpendLimitOverride(r.Context(), userID, actorID, params)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	h.logAdminAction(r.Context(), r, audit.ActionAdminSetSpendLimits, &userID, map[string]interface{}{
+		"per_transaction_limit": params.PerTransactionLimit,
+		"daily_limit":           params.DailyLimit,
+		"weekly_limit":          params.WeeklyLimit,
+		"reason":                params.Reason,
+	})
+
+	common.ResponseWithJSON(w, http.StatusOK, res, requestID)
+}
+
+func (h *Handler) AdminClearSpendLimits(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	userID, err := uuid.Parse(chi.URLParam(r, "user_id"))
+	if err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidUUID, err), requestID)
+		return
+	}
+
+	res, err := h.service.ClearSpendLimitOverride(r.Context(), userID)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	h.logAdminAction(r.Context(), r, audit.ActionAdminClearSpendLimits, &userID, nil)
+
+	common.ResponseWithJSON(w, http.StatusOK, res, requestID)
+}
+
+func (h *Handler) AdminReverseTransaction(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	transactionID, err := uuid.Parse(chi.URLParam(r, "transaction_id"))
+	if err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidUUID, err), requestID)
+		return
+	}
+
+	var params ReverseTransactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidJSON, err), requestID)
+		return
+	}
+
+	res, err := h.service.ReverseTransaction(r.Context(), transactionID, params.Reason)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	h.logAdminAction(r.Context(), r, audit.ActionAdminReverseTransaction, &transactionID, map[string]interface{}{
+		"reversal_id":   res.ReversalID,
+		"original_type": res.OriginalType,
+		"original_ref":  res.OriginalReferenceID,
+		"amount":        res.Amount,
+		"reason":        res.Reason,
+	})
+
+	common.ResponseWithJSON(w, http.StatusOK, res, requestID)
+}
+
+func (h *Handler) AdminListAdjustments(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	var params common.PaginationParams
+	if err := params.Bind(r); err != nil {
+		appErr := commonerrors.New("INVALID_REQUEST", http.StatusBadRequest, err.Error())
+		middleware.HandleError(w, appErr, requestID)
+		return
+	}
+
+	items, total, err := h.service.ListAdjustments(r.Context(), r.URL.Query().Get("status"), params.Page, params.PageSize)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	response := map[string]interface{}{
+		"data": items,
+		"meta": map[string]interface{}{
+			"page":          params.Page,
+			"page_size":     params.PageSize,
+			"total_records": total,
+		},
+	}
+
+	common.ResponseWithJSON(w, http.StatusOK, response, requestID)
+}
+
+func (h *Handler) AdminProposeAdjustment(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	actorID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		middleware.HandleError(w, commonerrors.ErrUnauthorized, requestID)
+		return
+	}
+
+	var params ProposeAdjustmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidJSON, err), requestID)
+		return
+	}
+
+	res, err := h.service.ProposeAdjustment(r.Context(), actorID, params)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	h.logAdminAction(r.Context(), r, audit.ActionAdminProposeAdjustment, &res.UserID, map[string]interface{}{
+		"adjustment_id": res.ID,
+		"amount":        res.Amount,
+		"reason":        res.Reason,
+	})
+
+	common.ResponseWithJSON(w, http.StatusCreated, res, requestID)
+}
+
+func (h *Handler) AdminApproveAdjustment(w http.ResponseWriter, r *http.Request) {
+	h.reviewAdjustment(w, r, true)
+}
+
+func (h *Handler) AdminRejectAdjustment(w http.ResponseWriter, r *http.Request) {
+	h.reviewAdjustment(w, r, false)
+}
+
+func (h *Handler) reviewAdjustment(w http.ResponseWriter, r *http.Request, approve bool) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	adjustmentID, err := uuid.Parse(chi.URLParam(r, "adjustment_id"))
+	if err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidUUID, err), requestID)
+		return
+	}
+
+	actorID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		middleware.HandleError(w, commonerrors.ErrUnauthorized, requestID)
+		return
+	}
+
+	var params ReviewAdjustmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidJSON, err), requestID)
+		return
+	}
+
+	var (
+		res    *LedgerAdjustment
+		action string
+	)
+
+	if approve {
+		action = audit.ActionAdminApproveAdjustment
+		res, err = h.service.ApproveAdjustment(r.Context(), adjustmentID, actorID, params.Note)
+	} else {
+		action = audit.ActionAdminRejectAdjustment
+		res, err = h.service.RejectAdjustment(r.Context(), adjustmentID, actorID, params.Note)
+	}
+
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	h.logAdminAction(r.Context(), r, action, &res.UserID, map[string]interface{}{
+		"adjustment_id":  res.ID,
+		"amount":         res.Amount,
+		"requested_by":   res.RequestedBy,
+		"note":           res.ReviewNote,
+		"transaction_id": res.TransactionID,
+	})
+
+	common.ResponseWithJSON(w, http.StatusOK, res, requestID)
+}
+
+func (h *Handler) RequestPayout(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		middleware.HandleError(w, commonerrors.ErrUnauthorized, requestID)
+		return
+	}
+
+	var params PayoutRequestInput
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidJSON, err), requestID)
+		return
+	}
+
+	res, err := h.service.RequestPayout(r.Context(), userID, params)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	common.ResponseWithJSON(w, http.StatusCreated, res, requestID)
+}
+
+func (h *Handler) GetPayout(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		middleware.HandleError(w, commonerrors.ErrUnauthorized, requestID)
+		return
+	}
+
+	res, err := h.service.GetLatestPayout(r.Context(), userID)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	common.ResponseWithJSON(w, http.StatusOK, res, requestID)
+}
+
+func (h *Handler) CancelPayout(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		middleware.HandleError(w, commonerrors.ErrUnauthorized, requestID)
+		return
+	}
+
+	res, err := h.service.CancelPayout(r.Context(), userID)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	common.ResponseWithJSON(w, http.StatusOK, res, requestID)
+}
+
+func (h *Handler) AdminListPayouts(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	var params common.PaginationParams
+	if err := params.Bind(r); err != nil {
+		appErr := commonerrors.New("INVALID_REQUEST", http.StatusBadRequest, err.Error())
+		middleware.HandleError(w, appErr, requestID)
+		return
+	}
+
+	items, total, err := h.service.ListPayouts(r.Context(), r.URL.Query().Get("status"), params.Page, params.PageSize)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	response := map[string]interface{}{
+		"data": items,
+		"meta": map[string]interface{}{
+			"page":          params.Page,
+			"page_size":     params.PageSize,
+			"total_records": total,
+		},
+	}
+
+	common.ResponseWithJSON(w, http.StatusOK, response, requestID)
+}
+
+func (h *Handler) AdminApprovePayout(w http.ResponseWriter, r *http.Request) {
+	h.reviewPayout(w, r, true)
+}
+
+func (h *Handler) AdminRejectPayout(w http.ResponseWriter, r *http.Request) {
+	h.reviewPayout(w, r, false)
+}
+
+func (h *Handler) reviewPayout(w http.ResponseWriter, r *http.Request, approve bool) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	payoutID, err := uuid.Parse(chi.URLParam(r, "payout_id"))
+	if err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidUUID, err), requestID)
+		return
+	}
+
+	actorID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		middleware.HandleError(w, commonerrors.ErrUnauthorized, requestID)
+		return
+	}
+
+	var params ReviewPayoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidJSON, err), requestID)
+		return
+	}
+
+	var (
+		res    *PayoutRequest
+		action string
+	)
+
+	if approve {
+		action = audit.ActionAdminApprovePayout
+		res, err = h.service.ApprovePayout(r.Context(), payoutID, actorID, params.Note)
+	} else {
+		action = audit.ActionAdminRejectPayout
+		res, err = h.service.RejectPayout(r.Context(), payoutID, actorID, params.Note)
+	}
+
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	h.logAdminAction(r.Context(), r, action, &res.UserID, map[string]interface{}{
+		"payout_id":      res.ID,
+		"amount":         res.Amount,
+		"mobile_number":  res.MobileNumber,
+		"note":           res.ReviewNote,
+		"transaction_id": res.TransactionID,
+	})
+
+	common.ResponseWithJSON(w, http.StatusOK, res, requestID)
+}
+
+func (h *Handler) AdminListPayoutBatches(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	var params common.PaginationParams
+	if err := params.Bind(r); err != nil {
+		appErr := commonerrors.New("INVALID_REQUEST", http.StatusBadRequest, err.Error())
+		middleware.HandleError(w, appErr, requestID)
+		return
+	}
+
+	batches, total, err := h.service.ListPayoutBatches(r.Context(), params.Page, params.PageSize)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	response := map[string]interface{}{
+		"data": batches,
+		"meta": map[string]interface{}{
+			"page":          params.Page,
+			"page_size":     params.PageSize,
+			"total_records": total,
+		},
+	}
+
+	common.ResponseWithJSON(w, http.StatusOK, response, requestID)
+}
+
+func (h *Handler) AdminCreatePayoutBatch(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	actorID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		middleware.HandleError(w, commonerrors.ErrUnauthorized, requestID)
+		return
+	}
+
+	batch, items, err := h.service.CreatePayoutBatch(r.Context(), actorID)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	h.logAdminAction(r.Context(), r, audit.ActionAdminCreatePayoutBatch, &batch.ID, map[string]interface{}{
+		"payout_count": batch.PayoutCount,
+		"total_amount": batch.TotalAmount,
+	})
+
+	response := map[string]interface{}{
+		"batch":   batch,
+		"payouts": items,
+	}
+
+	common.ResponseWithJSON(w, http.StatusCreated, response, requestID)
+}
+
+func (h *Handler) AdminExportPayoutBatch(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	batchID, err := uuid.Parse(chi.URLParam(r, "batch_id"))
+	if err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidUUID, err), requestID)
+		return
+	}
+
+	batch, items, err := h.service.GetPayoutBatch(r.Context(), batchID)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	data, err := RenderPayoutBatchCSV(batch, items)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", PayoutBatchFileName(batch)))
+	w.Write(data)
+}
+
+func (h *Handler) AdminCompletePayoutBatch(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	batchID, err := uuid.Parse(chi.URLParam(r, "batch_id"))
+	if err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidUUID, err), requestID)
+		return
+	}
+
+	actorID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		middleware.HandleError(w, commonerrors.ErrUnauthorized, requestID)
+		return
+	}
+
+	res, err := h.service.CompletePayoutBatch(r.Context(), batchID, actorID)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	h.logAdminAction(r.Context(), r, audit.ActionAdminCompletePayoutBatch, &batchID, map[string]interface{}{
+		"payout_count": res.PayoutCount,
+		"total_amount": res.TotalAmount,
+	})
+
+	common.ResponseWithJSON(w, http.StatusOK, res, requestID)
+}
+
+func (h *Handler) AdminListLedgerPartitions(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	res, err := h.service.ListLedgerPartitions(r.Context())
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	common.ResponseWithJSON(w, http.StatusOK, res, requestID)
+}
+
+func (h *Handler) AdminArchiveLedgerPartition(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	actorID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		middleware.HandleError(w, commonerrors.ErrUnauthorized, requestID)
+		return
+	}
+
+	month, err := time.Parse("2006-01", chi.URLParam(r, "month"))
+	if err != nil {
+		middleware.HandleError(w, ErrInvalidLedgerMonth, requestID)
+		return
+	}
+
+	res, err := h.service.ArchiveLedgerPartition(r.Context(), month, &actorID)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	h.logAdminAction(r.Context(), r, audit.ActionAdminArchiveLedger, &res.ID, map[string]interface{}{
+		"partition":      res.PartitionName,
+		"row_count":      res.RowCount,
+		"content_sha256": res.ContentSHA256,
+		"file_sha256":    res.FileSHA256,
+	})
+
+	common.ResponseWithJSON(w, http.StatusCreated, res, requestID)
+}
+
+func (h *Handler) AdminVerifyLedgerArchive(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	archiveID, err := uuid.Parse(chi.URLParam(r, "archive_id"))
+	if err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidUUID, err), requestID)
+		return
+	}
+
+	res, err := h.service.VerifyLedgerArchive(r.Context(), archiveID)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+
+	h.logAdminAction(r.Context(), r, audit.ActionAdminVerifyLedger, &archiveID, map[string]interface{}{
+		"partition": res.Archive.PartitionName,
+		"verified":  res.Verified,
+		"findings":  res.Findings,
+	})
+
+	common.ResponseWithJSON(w, http.StatusOK, res, requestID)
+}
+
+func (h *Handler) AdminDownloadLedgerArchive(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	archiveID, err := uuid.Parse(chi.URLParam(r, "archive_id"))
+	if err != nil {
+		middleware.HandleError(w, commonerrors.Wrap(commonerrors.ErrInvalidUUID, err), requestID)
+		return
+	}
+
+	archive, f, err := h.service.OpenLedgerArchive(r.Context(), archiveID)
+	if err != nil {
+		middleware.HandleError(w, err, requestID)
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", archive.FileName))
+	w.Header().Set("X-Content-SHA256", archive.ContentSHA256)
+	w.Header().Set("X-File-SHA256", archive.FileSHA256)
+	http.ServeContent(w, r, archive.FileName, archive.CreatedAt, f)
+}
+
+func (h *Handler) logAdminAction(ctx context.Context, r *http.Request, action string, targetID *uuid.UUID, details map[string]interface{}) {
+	ip := common.GetClientIP(r)
+	userAgent := r.UserAgent()
+	actorID, _ := auth.GetUserIDFromContext(ctx)
+
+	_ = h.audit.LogSecurityEvent(ctx, audit.Event{
+		ActorID:   &actorID,
+		Action:    action,
+		TargetID:  targetID,
+		IPAddress: ip,
+		UserAgent: userAgent,
+		Status:    audit.StatusSuccess,
+		Details:   details,
+	})
+}
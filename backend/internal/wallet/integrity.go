@@ -0,0 +1,506 @@
+package wallet
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hash-walker/giki-wallet/internal/common"
+	commonerrors "github.com/hash-walker/giki-wallet/internal/common/errors"
+	"github.com/hash-walker/giki-wallet/internal/middleware"
+	wallet "github.com/hash-walker/giki-wallet/internal/wallet/wallet_db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	JobScheduleLedgerIntegrityCheck = "SCHEDULE_LEDGER_INTEGRITY_CHECK"
+	JobRunLedgerIntegrityCheck      = "RUN_LEDGER_INTEGRITY_CHECK"
+
+	// integrityPageSize is how many wallets, ledger entries or transactions
+	// the verifier reads per query, so memory stays flat however large the
+	// ledger grows
+	integrityPageSize = 1000
+
+	// maxStoredIntegrityFindings caps the findings kept on a report; the
+	// count still covers every one
+	maxStoredIntegrityFindings = 500
+)
+
+// RegisterIntegrityJobs hands ledger verification to the worker and schedules
+// the first nightly run for the coming midnight.
+func (s *Service) RegisterIntegrityJobs(ctx context.Context) error {
+	s.worker.RegisterHandler(JobScheduleLedgerIntegrityCheck, s.handleScheduleLedgerIntegrityCheck)
+	s.worker.RegisterHandler(JobRunLedgerIntegrityCheck, s.handleRunLedgerIntegrityCheck)
+
+	return s.worker.EnsureScheduled(ctx, JobScheduleLedgerIntegrityCheck, struct{}{}, nextMidnight(time.Now()))
+}
+
+// RequestLedgerIntegrityCheck queues a full verification for the worker. A run
+// that is queued but not yet started already covers the request, so it is
+// returned instead of queueing another.
+func (s *Service) RequestLedgerIntegrityCheck(ctx context.Context, requestedBy uuid.UUID) (*IntegrityRun, error) {
+	queued, err := s.q.GetQueuedLedgerIntegrityRun(ctx)
+	if err == nil {
+		return mapDBIntegrityRun(queued)
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	return s.queueIntegrityRun(ctx, common.GoogleUUIDtoPgUUID(requestedBy, true))
+}
+
+// GetIntegrityRun returns a stored run, finished or not
+func (s *Service) GetIntegrityRun(ctx context.Context, runID uuid.UUID) (*IntegrityRun, error) {
+	run, err := s.q.GetLedgerIntegrityRun(ctx, runID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrIntegrityRunNotFound
+		}
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	return mapDBIntegrityRun(run)
+}
+
+// GetLatestIntegrityRun returns the most recently completed run
+func (s *Service) GetLatestIntegrityRun(ctx context.Context) (*IntegrityRun, error) {
+	run, err := s.q.GetLatestCompletedLedgerIntegrityRun(ctx)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrIntegrityRunNotFound
+		}
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	return mapDBIntegrityRun(run)
+}
+
+func (s *Service) queueIntegrityRun(ctx context.Context, requestedBy pgtype.UUID) (*IntegrityRun, error) {
+	run, err := s.q.CreateLedgerIntegrityRun(ctx, requestedBy)
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	if err := s.worker.Enqueue(ctx, JobRunLedgerIntegrityCheck, IntegrityRunJob{RunID: run.ID}); err != nil {
+		// a run no job will pick up must not sit QUEUED and absorb later requests
+		s.failIntegrityRun(ctx, run.ID, err)
+		return nil, err
+	}
+
+	return mapDBIntegrityRun(run)
+}
+
+// handleScheduleLedgerIntegrityCheck queues tonight's verification and
+// schedules tomorrow's.
+func (s *Service) handleScheduleLedgerIntegrityCheck(ctx context.Context, _ json.RawMessage) error {
+	if err := s.worker.EnsureScheduled(ctx, JobScheduleLedgerIntegrityCheck, struct{}{}, nextMidnight(time.Now())); err != nil {
+		return err
+	}
+
+	run, err := s.queueIntegrityRun(ctx, pgtype.UUID{})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[Integrity] queued nightly run %s", run.ID)
+	return nil
+}
+
+// handleRunLedgerIntegrityCheck verifies the ledger and stores the report on
+// the run. A failed attempt is recorded on the run and retried by the worker;
+// a run that already completed is left alone.
+func (s *Service) handleRunLedgerIntegrityCheck(ctx context.Context, payload json.RawMessage) error {
+	var job IntegrityRunJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return err
+	}
+
+	started, err := s.q.StartLedgerIntegrityRun(ctx, job.RunID)
+	if err != nil {
+		return commonerrors.Wrap(ErrDatabase, err)
+	}
+	if started == 0 {
+		return nil
+	}
+
+	report, err := s.VerifyLedgerIntegrity(ctx)
+	if err != nil {
+		s.failIntegrityRun(ctx, job.RunID, err)
+		return err
+	}
+
+	findings, err := json.Marshal(report.Findings)
+	if err != nil {
+		return err
+	}
+
+	err = s.q.CompleteLedgerIntegrityRun(ctx, wallet.CompleteLedgerIntegrityRunParams{
+		ID:                  job.RunID,
+		WalletsChecked:      int32(report.WalletsChecked),
+		EntriesChecked:      report.EntriesChecked,
+		TransactionsChecked: report.TransactionsChecked,
+		FindingsCount:       int32(report.FindingsCount),
+		Findings:            findings,
+		Healthy:             report.Healthy,
+	})
+	if err != nil {
+		return commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	if !report.Healthy {
+		err := fmt.Errorf("run %s found %d issues across %d wallets", job.RunID, report.FindingsCount, report.WalletsChecked)
+		middleware.LogAppError(commonerrors.Wrap(ErrLedgerIntegrityViolated, err), "ledger-integrity-check")
+	}
+
+	log.Printf("[Integrity] run %s: %d wallets, %d entries, %d transactions, %d findings",
+		job.RunID, report.WalletsChecked, report.EntriesChecked, report.TransactionsChecked, report.FindingsCount)
+	return nil
+}
+
+func (s *Service) failIntegrityRun(ctx context.Context, runID uuid.UUID, cause error) {
+	err := s.q.FailLedgerIntegrityRun(ctx, wallet.FailLedgerIntegrityRunParams{
+		ID:        runID,
+		LastError: common.StringToText(cause.Error()),
+	})
+	if err != nil {
+		middleware.LogAppError(commonerrors.Wrap(ErrDatabase, err), "ledger-integrity-check")
+	}
+}
+
+// VerifyLedgerIntegrity walks every wallet's ledger and every transaction header.
+// It recomputes row hashes, checks that each balance_after follows from the previous
+// row, and checks that the legs of each header sum to zero. Wallets and entries are
+// read a page at a time; run it from the worker rather than a request.
+func (s *Service) VerifyLedgerIntegrity(ctx context.Context) (*IntegrityReport, error) {
+	report := &IntegrityReport{
+		StartedAt: time.Now(),
+		Findings:  []IntegrityFinding{},
+	}
+
+	var after pgtype.UUID
+	for {
+		walletIDs, err := s.q.ListWalletIDs(ctx, wallet.ListWalletIDsParams{
+			AfterID: after,
+			Limit:   integrityPageSize,
+		})
+		if err != nil {
+			return nil, commonerrors.Wrap(ErrDatabase, err)
+		}
+
+		for _, walletID := range walletIDs {
+			if err := s.verifyWalletLedger(ctx, walletID, report); err != nil {
+				return nil, err
+			}
+			report.WalletsChecked++
+		}
+
+		if len(walletIDs) < integrityPageSize {
+			break
+		}
+		after = common.GoogleUUIDtoPgUUID(walletIDs[len(walletIDs)-1], true)
+	}
+
+	if err := s.verifyTransactionHeaders(ctx, report); err != nil {
+		return nil, err
+	}
+
+	report.Healthy = report.FindingsCount == 0
+	report.CompletedAt = time.Now()
+
+	return report, nil
+}
+
+// VerifyWalletIntegrity checks the hash and balance chain of a single wallet.
+// Used by admins to spot-check a wallet without scanning the whole ledger.
+func (s *Service) VerifyWalletIntegrity(ctx context.Context, walletID uuid.UUID) (*IntegrityReport, error) {
+	report := &IntegrityReport{
+		StartedAt: time.Now(),
+		Findings:  []IntegrityFinding{},
+	}
+
+	if _, err := s.q.GetWalletByID(ctx, walletID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrWalletNotFound
+		}
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	if err := s.verifyWalletLedger(ctx, walletID, report); err != nil {
+		return nil, err
+	}
+
+	report.WalletsChecked = 1
+	report.Healthy = report.FindingsCount == 0
+	report.CompletedAt = time.Now()
+
+	return report, nil
+}
+
+// addFinding counts every finding but keeps only the first few hundred
+func (r *IntegrityReport) addFinding(f IntegrityFinding) {
+	r.FindingsCount++
+	if len(r.Findings) < maxStoredIntegrityFindings {
+		r.Findings = append(r.Findings, f)
+	}
+}
+
+func (s *Service) verifyWalletLedger(ctx context.Context, walletID uuid.UUID, report *IntegrityReport) error {
+	var (
+		pending   []wallet.GetLedgerEntriesForVerificationRow
+		running   int64
+		cursorAt  pgtype.Timestamptz
+		cursorID  pgtype.UUID
+		exhausted bool
+	)
+
+	for !exhausted {
+		page, err := s.q.GetLedgerEntriesForVerification(ctx, wallet.GetLedgerEntriesForVerificationParams{
+			WalletID:        walletID,
+			CursorCreatedAt: cursorAt,
+			CursorID:        cursorID,
+			Limit:           integrityPageSize,
+		})
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabase, err)
+		}
+
+		report.EntriesChecked += int64(len(page))
+		exhausted = len(page) < integrityPageSize
+		pending = append(pending, page...)
+
+		// rows sharing the last timestamp may continue on the next page, and
+		// they can only be ordered together, so hold them back until then
+		ready := len(pending)
+		if !exhausted {
+			last := page[len(page)-1]
+			cursorAt = pgtype.Timestamptz{Time: last.CreatedAt, Valid: true}
+			cursorID = common.GoogleUUIDtoPgUUID(last.ID, true)
+
+			for ready > 0 && pending[ready-1].CreatedAt.Equal(last.CreatedAt) {
+				ready--
+			}
+		}
+
+		running = s.verifyLedgerRows(pending[:ready], running, report)
+		pending = append([]wallet.GetLedgerEntriesForVerificationRow(nil), pending[ready:]...)
+	}
+
+	materialized, err := s.getWalletBalance(ctx, s.q, walletID)
+	if err != nil {
+		return err
+	}
+	if materialized != running {
+		report.addFinding(IntegrityFinding{
+			Kind:     FindingMaterializedDrift,
+			WalletID: &walletID,
+			Expected: strconv.FormatInt(running, 10),
+			Actual:   strconv.FormatInt(materialized, 10),
+			Detail:   "wallet_balances does not match the last ledger balance",
+		})
+	}
+
+	return nil
+}
+
+// verifyLedgerRows checks a run of one wallet's entries, in ledger order,
+// against the balance before them and returns the balance after them
+func (s *Service) verifyLedgerRows(entries []wallet.GetLedgerEntriesForVerificationRow, running int64, report *IntegrityReport) int64 {
+	for i := 0; i < len(entries); {
+		// Rows written before created_at moved to clock_timestamp() can share a
+		// timestamp with their siblings, so id order says nothing about write order.
+		j := i + 1
+		for j < len(entries) && entries[j].CreatedAt.Equal(entries[i].CreatedAt) {
+			j++
+		}
+
+		for _, e := range orderByBalanceChain(entries[i:j], running) {
+			entryID := e.ID
+			entryWalletID := e.WalletID
+			transactionID := e.TransactionID
+
+			if expected := running + e.Amount; e.BalanceAfter != expected {
+				report.addFinding(IntegrityFinding{
+					Kind:          FindingBalanceDiscontinuity,
+					WalletID:      &entryWalletID,
+					LedgerEntryID: &entryID,
+					TransactionID: &transactionID,
+					Expected:      strconv.FormatInt(expected, 10),
+					Actual:        strconv.FormatInt(e.BalanceAfter, 10),
+					Detail:        fmt.Sprintf("previous balance %d plus amount %d does not match balance_after", running, e.Amount),
+				})
+			}
+
+			expectedHash := s.CalculateRowHash(e.WalletID, e.Amount, e.TransactionID, e.BalanceAfter, e.TransactionCreatedAt)
+			if !hmac.Equal([]byte(expectedHash), []byte(e.RowHash)) {
+				report.addFinding(IntegrityFinding{
+					Kind:          FindingHashMismatch,
+					WalletID:      &entryWalletID,
+					LedgerEntryID: &entryID,
+					TransactionID: &transactionID,
+					Expected:      expectedHash,
+					Actual:        e.RowHash,
+					Detail:        "row hash does not match recomputed HMAC",
+				})
+			}
+
+			// Continue from the stored balance so one bad row yields one finding
+			running = e.BalanceAfter
+		}
+
+		i = j
+	}
+
+	return running
+}
+
+// verifyTransactionHeaders checks that every transaction has at least two legs
+// summing to zero, a keyset page of headers at a time
+func (s *Service) verifyTransactionHeaders(ctx context.Context, report *IntegrityReport) error {
+	var cursorAt pgtype.Timestamptz
+	var cursorID pgtype.UUID
+
+	for {
+		page, err := s.q.GetTransactionLegTotals(ctx, wallet.GetTransactionLegTotalsParams{
+			CursorCreatedAt: cursorAt,
+			CursorID:        cursorID,
+			Limit:           integrityPageSize,
+		})
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabase, err)
+		}
+
+		for _, t := range page {
+			if t.LegSum == 0 && t.LegCount >= 2 {
+				continue
+			}
+
+			transactionID := t.ID
+			report.addFinding(IntegrityFinding{
+				Kind:          FindingUnbalancedTransaction,
+				TransactionID: &transactionID,
+				Expected:      "0",
+				Actual:        strconv.FormatInt(t.LegSum, 10),
+				Detail:        fmt.Sprintf("%s %s has %d legs summing to %d", t.Type, t.ReferenceID, t.LegCount, t.LegSum),
+			})
+		}
+		report.TransactionsChecked += int64(len(page))
+
+		if len(page) < integrityPageSize {
+			return nil
+		}
+		last := page[len(page)-1]
+		cursorAt = pgtype.Timestamptz{Time: last.CreatedAt, Valid: true}
+		cursorID = common.GoogleUUIDtoPgUUID(last.ID, true)
+	}
+}
+
+// orderByBalanceChain orders rows that share a timestamp by following the
+// balance chain from the opening balance. Rows that do not fit the chain keep
+// their original relative order and surface as discontinuities.
+func orderByBalanceChain(rows []wallet.GetLedgerEntriesForVerificationRow, opening int64) []wallet.GetLedgerEntriesForVerificationRow {
+	if len(rows) < 2 {
+		return rows
+	}
+
+	remaining := append([]wallet.GetLedgerEntriesForVerificationRow(nil), rows...)
+	ordered := make([]wallet.GetLedgerEntriesForVerificationRow, 0, len(rows))
+	balance := opening
+
+	for len(remaining) > 0 {
+		next := 0
+		for k, r := range remaining {
+			if r.BalanceAfter-r.Amount == balance {
+				next = k
+				break
+			}
+		}
+		ordered = append(ordered, remaining[next])
+		balance = remaining[next].BalanceAfter
+		remaining = append(remaining[:next], remaining[next+1:]...)
+	}
+
+	return ordered
+}
+
+// RunBalanceCheckpoint snapshots every wallet's materialized balance next to the
+// sum of its ledger amounts in a single statement, so both sides see the same data.
+func (s *Service) RunBalanceCheckpoint(ctx context.Context) (*CheckpointSummary, error) {
+	runID := uuid.New()
+
+	checked, err := s.q.CreateBalanceCheckpoints(ctx, runID)
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	drifted, err := s.q.GetCheckpointDrift(ctx, runID)
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	for _, d := range drifted {
+		err := fmt.Errorf("wallet %s: materialized %d, ledger %d", d.WalletID, d.MaterializedBalance, d.LedgerBalance)
+		middleware.LogAppError(commonerrors.Wrap(ErrBalanceDrift, err), "balance-checkpoint")
+	}
+
+	if err := s.q.PruneBalanceCheckpoints(ctx); err != nil {
+		middleware.LogAppError(commonerrors.Wrap(ErrDatabase, err), "balance-checkpoint-prune")
+	}
+
+	return &CheckpointSummary{
+		RunID:          runID,
+		WalletsChecked: checked,
+		DriftedWallets: len(drifted),
+		CreatedAt:      time.Now(),
+	}, nil
+}
+
+// StartBalanceCheckpointWorker starts a background goroutine that checkpoints balances periodically
+func StartBalanceCheckpointWorker(s *Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			summary, err := s.RunBalanceCheckpoint(context.Background())
+			if err != nil {
+				middleware.LogAppError(err, "balance-checkpoint")
+				continue
+			}
+			log.Printf("[Checkpoint] %d wallets checked, %d drifted", summary.WalletsChecked, summary.DriftedWallets)
+		}
+	}()
+}
+
+func mapDBIntegrityRun(r wallet.GikiWalletLedgerIntegrityRun) (*IntegrityRun, error) {
+	run := &IntegrityRun{
+		ID:                  r.ID,
+		Status:              r.Status,
+		RequestedBy:         common.PgUUIDToUUIDPointer(r.RequestedBy),
+		WalletsChecked:      int(r.WalletsChecked),
+		EntriesChecked:      r.EntriesChecked,
+		TransactionsChecked: r.TransactionsChecked,
+		FindingsCount:       int(r.FindingsCount),
+		Findings:            []IntegrityFinding{},
+		LastError:           common.TextToString(r.LastError),
+		CreatedAt:           r.CreatedAt,
+		StartedAt:           common.TimestamptzToTimePointer(r.StartedAt),
+		CompletedAt:         common.TimestamptzToTimePointer(r.CompletedAt),
+	}
+
+	if r.Healthy.Valid {
+		healthy := r.Healthy.Bool
+		run.Healthy = &healthy
+	}
+
+	if err := json.Unmarshal(r.Findings, &run.Findings); err != nil {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	return run, nil
+}
@@ -0,0 +1,175 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/hash-walker/giki-wallet/internal/common"
+	commonerrors "github.com/hash-walker/giki-wallet/internal/common/errors"
+	wallet "github.com/hash-walker/giki-wallet/internal/wallet/wallet_db"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostJournalEntry posts any number of legs under a single transactions header.
+// Legs must sum to zero. Wallets are locked in sorted ID order to avoid
+// deadlocks with concurrent postings, and every leg gets its own row hash.
+func (s *Service) PostJournalEntry(ctx context.Context, tx pgx.Tx, entry JournalEntry) (uuid.UUID, error) {
+	walletQ := s.q
+	if tx != nil {
+		walletQ = s.q.WithTx(tx)
+	}
+
+	if err := validateJournalEntry(entry); err != nil {
+		return uuid.Nil, err
+	}
+
+	// Net movement per wallet, and the distinct wallets in lock order
+	net := make(map[uuid.UUID]int64, len(entry.Legs))
+	walletIDs := make([]uuid.UUID, 0, len(entry.Legs))
+	for _, leg := range entry.Legs {
+		if _, seen := net[leg.WalletID]; !seen {
+			walletIDs = append(walletIDs, leg.WalletID)
+		}
+		net[leg.WalletID] += leg.Amount
+	}
+	common.SortUUIDs(walletIDs)
+
+	locked := make(map[uuid.UUID]wallet.GikiWalletWallet, len(walletIDs))
+	balances := make(map[uuid.UUID]int64, len(walletIDs))
+	for _, id := range walletIDs {
+		w, err := walletQ.GetWalletForUpdate(ctx, id)
+		if err != nil {
+			return uuid.Nil, commonerrors.Wrap(ErrDatabase, err)
+		}
+		locked[id] = w
+	}
+
+	// enforce wallet status and balances now that every row is locked
+	for _, leg := range entry.Legs {
+		w := locked[leg.WalletID]
+		if leg.Amount < 0 {
+			if err := checkCanSend(w, entry.Type); err != nil {
+				return uuid.Nil, err
+			}
+		} else {
+			if err := checkCanReceive(w, entry.Type); err != nil {
+				return uuid.Nil, err
+			}
+		}
+	}
+
+	for _, id := range walletIDs {
+		balance, err := s.getWalletBalance(ctx, walletQ, id)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		balances[id] = balance
+
+		if net[id] < 0 && !isSystemWallet(locked[id]) {
+			// funds held by active reservations are not spendable
+			reserved, err := walletQ.GetReservedAmount(ctx, id)
+			if err != nil {
+				return uuid.Nil, commonerrors.Wrap(ErrDatabase, err)
+			}
+
+			if !checkBalance(balance-reserved, -net[id]) {
+				return uuid.Nil, ErrInsufficientFunds
+			}
+
+			if err := s.checkSpendLimits(ctx, walletQ, id, -net[id], entry.Type); err != nil {
+				return uuid.Nil, err
+			}
+		}
+	}
+
+	txnHeader, err := walletQ.CreateTransactionHeader(ctx, wallet.CreateTransactionHeaderParams{
+		Type:        entry.Type,
+		ReferenceID: entry.ReferenceID,
+		Description: common.StringToText(entry.Description),
+		ReversesTransactionID: common.GoogleUUIDtoPgUUID(
+			entry.ReversesTransactionID,
+			entry.ReversesTransactionID != uuid.Nil,
+		),
+	})
+	if err != nil {
+		return uuid.Nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	for _, leg := range entry.Legs {
+		balanceAfter := balances[leg.WalletID] + leg.Amount
+		balances[leg.WalletID] = balanceAfter
+
+		rowHash := s.CalculateRowHash(
+			leg.WalletID,
+			leg.Amount,
+			txnHeader.ID,
+			balanceAfter,
+			txnHeader.CreatedAt,
+		)
+
+		ledgerEntry, err := walletQ.CreateLedgerEntry(ctx, wallet.CreateLedgerEntryParams{
+			WalletID:      leg.WalletID,
+			Amount:        leg.Amount,
+			TransactionID: txnHeader.ID,
+			BalanceAfter:  balanceAfter,
+			RowHash:       rowHash,
+		})
+		if err != nil {
+			return uuid.Nil, commonerrors.Wrap(ErrDatabase, err)
+		}
+
+		if err := applyBalance(ctx, walletQ, ledgerEntry); err != nil {
+			return uuid.Nil, err
+		}
+	}
+
+	return txnHeader.ID, nil
+}
+
+func validateJournalEntry(entry JournalEntry) error {
+	if entry.Type == "" || entry.ReferenceID == "" {
+		return commonerrors.Wrap(ErrInvalidJournalEntry, fmt.Errorf("type and reference are required"))
+	}
+
+	if len(entry.Legs) < 2 {
+		return commonerrors.Wrap(ErrInvalidJournalEntry, fmt.Errorf("journal entry needs at least two legs, got %d", len(entry.Legs)))
+	}
+
+	var sum int64
+	for i, leg := range entry.Legs {
+		if leg.WalletID == uuid.Nil {
+			return commonerrors.Wrap(ErrInvalidJournalEntry, fmt.Errorf("leg %d has no wallet", i))
+		}
+		if leg.Amount == 0 {
+			return commonerrors.Wrap(ErrInvalidJournalEntry, fmt.Errorf("leg %d has a zero amount", i))
+		}
+		sum += leg.Amount
+	}
+
+	if sum != 0 {
+		return commonerrors.Wrap(ErrInvalidJournalEntry, fmt.Errorf("legs sum to %d, expected 0", sum))
+	}
+
+	return nil
+}
+
+// applyBalance moves the materialized balance by the entry amount and refuses
+// to commit if the result disagrees with the balance_after just written.
+// Callers must hold the wallet row lock.
+func applyBalance(ctx context.Context, walletQ *wallet.Queries, entry wallet.GikiWalletLedger) error {
+	balance, err := walletQ.ApplyWalletBalanceDelta(ctx, wallet.ApplyWalletBalanceDeltaParams{
+		WalletID:    entry.WalletID,
+		Balance:     entry.Amount,
+		LastEntryID: common.GoogleUUIDtoPgUUID(entry.ID, true),
+	})
+	if err != nil {
+		return commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	if balance != entry.BalanceAfter {
+		return commonerrors.Wrap(ErrBalanceDrift, fmt.Errorf("wallet %s: materialized %d, ledger %d", entry.WalletID, balance, entry.BalanceAfter))
+	}
+
+	return nil
+}
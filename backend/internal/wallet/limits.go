@@ -0,0 +1,256 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hash-walker/giki-wallet/internal/common"
+	commonerrors "github.com/hash-walker/giki-wallet/internal/common/errors"
+	"github.com/hash-walker/giki-wallet/internal/config_management"
+	wallet "github.com/hash-walker/giki-wallet/internal/wallet/wallet_db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// spendLimitExemptTypes are debits that are not the user spending: finance
+// corrections, paying out the balance of a wallet being closed, and refunding
+// a top-up back through its gateway
+var spendLimitExemptTypes = []string{
+	TransactionTypeReversal,
+	TransactionTypeAdminAdjustment,
+	TransactionTypePayout,
+	TransactionTypeGatewayRefund,
+}
+
+// checkSpendLimits enforces the per-transaction, rolling 24 hour and rolling
+// 7 day caps of a personal wallet's owner. Wallets without an owner, such as
+// system and merchant wallets, are not limited. Callers must hold the wallet
+// row lock so concurrent debits cannot both fit under a cap.
+func (s *Service) checkSpendLimits(ctx context.Context, walletQ *wallet.Queries, walletID uuid.UUID, amount int64, txnType string) error {
+	if slices.Contains(spendLimitExemptTypes, txnType) {
+		return nil
+	}
+
+	policy, err := walletQ.GetWalletSpendPolicy(ctx, walletID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	limits := s.effectiveSpendLimits(ctx, policy)
+
+	if limits.PerTransaction > 0 && amount > limits.PerTransaction {
+		return ErrSpendLimitExceeded.
+			WithDetails("limit", "per_transaction").
+			WithDetails("max_amount", common.Money(limits.PerTransaction))
+	}
+
+	windows := []struct {
+		name   string
+		cap    int64
+		period time.Duration
+	}{
+		{"daily", limits.Daily, 24 * time.Hour},
+		{"weekly", limits.Weekly, 7 * 24 * time.Hour},
+	}
+
+	for _, window := range windows {
+		if window.cap <= 0 {
+			continue
+		}
+
+		spent, err := s.spentSince(ctx, walletQ, walletID, time.Now().Add(-window.period))
+		if err != nil {
+			return err
+		}
+
+		if spent+amount > window.cap {
+			return ErrSpendLimitExceeded.
+				WithDetails("limit", window.name).
+				WithDetails("max_amount", common.Money(window.cap)).
+				WithDetails("remaining", common.Money(max(window.cap-spent, 0)))
+		}
+	}
+
+	return nil
+}
+
+// effectiveSpendLimits layers the user's override over their type's defaults
+func (s *Service) effectiveSpendLimits(ctx context.Context, policy wallet.GetWalletSpendPolicyRow) config_management.SpendLimits {
+	limits := s.configS.GetSpendLimits(ctx, policy.UserType)
+
+	if policy.PerTransactionLimit.Valid {
+		limits.PerTransaction = policy.PerTransactionLimit.Int64
+	}
+	if policy.DailyLimit.Valid {
+		limits.Daily = policy.DailyLimit.Int64
+	}
+	if policy.WeeklyLimit.Valid {
+		limits.Weekly = policy.WeeklyLimit.Int64
+	}
+
+	return limits
+}
+
+func (s *Service) spentSince(ctx context.Context, walletQ *wallet.Queries, walletID uuid.UUID, since time.Time) (int64, error) {
+	spent, err := walletQ.GetWalletSpendSince(ctx, wallet.GetWalletSpendSinceParams{
+		WalletID:    walletID,
+		Since:       since,
+		ExemptTypes: spendLimitExemptTypes,
+	})
+	if err != nil {
+		return 0, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	return spent, nil
+}
+
+// GetSpendLimits reports the caps that apply to a user's wallet and how much
+// of each window has been used
+func (s *Service) GetSpendLimits(ctx context.Context, userID uuid.UUID) (*SpendLimits, error) {
+	w, err := s.GetOrCreateWallet(ctx, nil, userID)
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	policy, err := s.q.GetWalletSpendPolicy(ctx, w.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrWalletNotFound
+		}
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	res := &SpendLimits{
+		UserID:    userID,
+		UserType:  policy.UserType,
+		Defaults:  mapSpendLimitSet(s.configS.GetSpendLimits(ctx, policy.UserType)),
+		Effective: mapSpendLimitSet(s.effectiveSpendLimits(ctx, policy)),
+	}
+
+	override, err := s.q.GetSpendLimitOverride(ctx, userID)
+	if err == nil {
+		res.Override = &SpendLimitOverride{
+			SpendLimitSet: SpendLimitSet{
+				PerTransaction: int8ToMoney(override.PerTransactionLimit),
+				Daily:          int8ToMoney(override.DailyLimit),
+				Weekly:         int8ToMoney(override.WeeklyLimit),
+			},
+			Reason:    override.Reason,
+			SetBy:     override.SetBy,
+			UpdatedAt: override.UpdatedAt,
+		}
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	day, err := s.spentSince(ctx, s.q, w.ID, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		return nil, err
+	}
+
+	week, err := s.spentSince(ctx, s.q, w.ID, time.Now().Add(-7*24*time.Hour))
+	if err != nil {
+		return nil, err
+	}
+
+	res.SpentLast24h = common.Money(day)
+	res.SpentLast7d = common.Money(week)
+
+	return res, nil
+}
+
+// SetSpendLimitOverride replaces the user's override. Fields left out fall
+// back to the user type's defaults.
+func (s *Service) SetSpendLimitOverride(ctx context.Context, userID, actorID uuid.UUID, req SpendLimitOverrideRequest) (*SpendLimits, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, ErrInvalidSpendLimit.WithDetails("reason", "required")
+	}
+
+	if req.PerTransactionLimit == nil && req.DailyLimit == nil && req.WeeklyLimit == nil {
+		return nil, ErrInvalidSpendLimit.WithDetails("limits", "set at least one of per_transaction_limit, daily_limit or weekly_limit")
+	}
+
+	perTxn, err := rupeesToInt8("per_transaction_limit", req.PerTransactionLimit)
+	if err != nil {
+		return nil, err
+	}
+	daily, err := rupeesToInt8("daily_limit", req.DailyLimit)
+	if err != nil {
+		return nil, err
+	}
+	weekly, err := rupeesToInt8("weekly_limit", req.WeeklyLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.GetOrCreateWallet(ctx, nil, userID); err != nil {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	_, err = s.q.UpsertSpendLimitOverride(ctx, wallet.UpsertSpendLimitOverrideParams{
+		UserID:              userID,
+		PerTransactionLimit: perTxn,
+		DailyLimit:          daily,
+		WeeklyLimit:         weekly,
+		Reason:              reason,
+		SetBy:               actorID,
+	})
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	return s.GetSpendLimits(ctx, userID)
+}
+
+// ClearSpendLimitOverride puts the user back on their type's default caps
+func (s *Service) ClearSpendLimitOverride(ctx context.Context, userID uuid.UUID) (*SpendLimits, error) {
+	if _, err := s.q.DeleteSpendLimitOverride(ctx, userID); err != nil {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	return s.GetSpendLimits(ctx, userID)
+}
+
+func rupeesToInt8(field string, amount *float64) (pgtype.Int8, error) {
+	if amount == nil {
+		return pgtype.Int8{}, nil
+	}
+
+	paisa := int64(common.AmountToLowestUnit(*amount))
+	if paisa <= 0 {
+		return pgtype.Int8{}, ErrInvalidSpendLimit.WithDetails(field, "must be greater than zero")
+	}
+
+	return pgtype.Int8{Int64: paisa, Valid: true}, nil
+}
+
+func int8ToMoney(v pgtype.Int8) *common.Money {
+	if !v.Valid {
+		return nil
+	}
+
+	return common.MoneyPtr(v.Int64)
+}
+
+func mapSpendLimitSet(limits config_management.SpendLimits) SpendLimitSet {
+	capOrNil := func(paisa int64) *common.Money {
+		if paisa <= 0 {
+			return nil
+		}
+		return common.MoneyPtr(paisa)
+	}
+
+	return SpendLimitSet{
+		PerTransaction: capOrNil(limits.PerTransaction),
+		Daily:          capOrNil(limits.Daily),
+		Weekly:         capOrNil(limits.Weekly),
+	}
+}
@@ -13,6 +13,44 @@ type SystemWalletType string
 const (
 	SystemWalletRevenue   SystemWalletType = "SYS_REVENUE"   // Where ticket money goes
 	SystemWalletLiability SystemWalletType = "SYS_LIABILITY" // Where top-up money comes from
+	SystemWalletPromo     SystemWalletType = "SYS_PROMO"     // Where promo voucher credits come from
+)
+
+// WalletTypeMerchant marks a campus merchant's wallet. Merchant wallets have no
+// user and are subject to the same status and balance rules as personal wallets.
+const WalletTypeMerchant = "MERCHANT"
+
+// Transaction types posted by the wallet package itself
+const (
+	TransactionTypeP2PTransfer     = "P2P_TRANSFER"
+	TransactionTypeReversal        = "REVERSAL"
+	TransactionTypeAdminAdjustment = "ADMIN_ADJUSTMENT"
+	TransactionTypePayout          = "PAYOUT"
+)
+
+// TransactionTypeGatewayRefund is posted by the payment package when a top-up
+// is refunded through the gateway it came in on
+const TransactionTypeGatewayRefund = "GATEWAY_REFUND"
+
+const (
+	ReservationStatusActive   = "ACTIVE"
+	ReservationStatusCaptured = "CAPTURED"
+	ReservationStatusReleased = "RELEASED"
+	ReservationStatusExpired  = "EXPIRED"
+)
+
+const (
+	AdjustmentStatusPending  = "PENDING"
+	AdjustmentStatusApproved = "APPROVED"
+	AdjustmentStatusRejected = "REJECTED"
+)
+
+const (
+	PayoutStatusPending   = "PENDING"
+	PayoutStatusApproved  = "APPROVED"
+	PayoutStatusRejected  = "REJECTED"
+	PayoutStatusCancelled = "CANCELLED"
+	PayoutStatusPaid      = "PAID"
 )
 
 type SystemWalletName string
@@ -20,8 +58,26 @@ type SystemWalletName string
 const (
 	TransportSystemWallet SystemWalletName = "Transport Revenue"
 	GikiWallet            SystemWalletName = "GIKI Wallet"
+	PromoSystemWallet     SystemWalletName = "Promotions"
 )
 
+// JournalLeg is one side of a journal entry. Negative amounts debit the
+// wallet and positive amounts credit it.
+type JournalLeg struct {
+	WalletID uuid.UUID
+	Amount   int64
+}
+
+type JournalEntry struct {
+	Type        string
+	ReferenceID string
+	Description string
+	Legs        []JournalLeg
+
+	// ReversesTransactionID links a reversal to the header it mirrors
+	ReversesTransactionID uuid.UUID
+}
+
 type Wallet struct {
 	ID        uuid.UUID `json:"id"`
 	UserID    uuid.UUID `json:"user_id"`
@@ -44,24 +100,358 @@ func MapDBWalletToWallet(dbWallet walletdb.GikiWalletWallet) *Wallet {
 	}
 }
 
+type WalletOverview struct {
+	Wallet
+	Balance common.Money `json:"balance"`
+}
+
+type UpdateWalletStatusRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+type WalletStatusChange struct {
+	Wallet
+	PreviousStatus string `json:"previous_status"`
+	Reason         string `json:"reason"`
+}
+
 type BalanceResponse struct {
-	Balance  float64 `json:"balance"`
-	Currency string  `json:"currency"`
+	Balance   common.Money `json:"balance"`
+	Reserved  common.Money `json:"reserved"`
+	Available common.Money `json:"available"`
+	Currency  string       `json:"currency"`
 }
 
 type TransactionHistoryItem struct {
-	ID           uuid.UUID `json:"id"`
-	Amount       float64   `json:"amount"`
-	BalanceAfter float64   `json:"balance_after"`
-	Type         string    `json:"type"`
-	ReferenceID  string    `json:"reference_id"`
-	Description  string    `json:"description"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID           uuid.UUID    `json:"id"`
+	Amount       common.Money `json:"amount"`
+	BalanceAfter common.Money `json:"balance_after"`
+	Type         string       `json:"type"`
+	ReferenceID  string       `json:"reference_id"`
+	Description  string       `json:"description"`
+	CreatedAt    time.Time    `json:"created_at"`
 }
 
 type LedgerHistoryWithPagination struct {
 	Data       []TransactionHistoryItem `json:"data"`
-	TotalCount int64                    `json:"total_count"`
-	Page       int                      `json:"page"`
 	PageSize   int                      `json:"page_size"`
+	NextCursor string                   `json:"next_cursor,omitempty"`
+	HasMore    bool                     `json:"has_more"`
+}
+
+// Statement is a wallet's ledger for a period, bracketed by its opening and closing balances.
+// PeriodEnd is exclusive.
+type Statement struct {
+	WalletID       uuid.UUID       `json:"wallet_id"`
+	AccountHolder  string          `json:"account_holder"`
+	Email          string          `json:"email"`
+	PeriodStart    time.Time       `json:"period_start"`
+	PeriodEnd      time.Time       `json:"period_end"`
+	OpeningBalance common.Money    `json:"opening_balance"`
+	ClosingBalance common.Money    `json:"closing_balance"`
+	TotalCredits   common.Money    `json:"total_credits"`
+	TotalDebits    common.Money    `json:"total_debits"`
+	Lines          []StatementLine `json:"lines"`
+	GeneratedAt    time.Time       `json:"generated_at"`
+}
+
+type StatementLine struct {
+	ID          uuid.UUID    `json:"id"`
+	Date        time.Time    `json:"date"`
+	Type        string       `json:"type"`
+	ReferenceID string       `json:"reference_id"`
+	Description string       `json:"description"`
+	Amount      common.Money `json:"amount"`
+	Balance     common.Money `json:"balance"`
+}
+
+type StatementSubscription struct {
+	Enabled bool `json:"enabled"`
+}
+
+// MonthlyStatementJob is the payload of a SEND_MONTHLY_STATEMENT job
+type MonthlyStatementJob struct {
+	UserID      uuid.UUID `json:"user_id"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+}
+
+// TransferRequest Frontend → backend
+type TransferRequest struct {
+	IdempotencyKey uuid.UUID `json:"idempotency_key"`
+	Recipient      string    `json:"recipient"` // email or registration number
+	Amount         float64   `json:"amount"`    // in Rupees
+	Note           string    `json:"note,omitempty"`
+}
+
+type TransferResult struct {
+	TransactionID  uuid.UUID    `json:"transaction_id"`
+	ReferenceID    string       `json:"reference_id"`
+	Amount         common.Money `json:"amount"`
+	RecipientName  string       `json:"recipient_name"`
+	RecipientEmail string       `json:"recipient_email"`
+	Description    string       `json:"description"`
+	CreatedAt      time.Time    `json:"created_at"`
+}
+
+type ReverseTransactionRequest struct {
+	Reason string `json:"reason"`
+}
+
+type ReversalResult struct {
+	ReversalID          uuid.UUID    `json:"reversal_id"`
+	OriginalID          uuid.UUID    `json:"original_id"`
+	OriginalType        string       `json:"original_type"`
+	OriginalReferenceID string       `json:"original_reference_id"`
+	Amount              common.Money `json:"amount"`
+	Reason              string       `json:"reason"`
+}
+
+type ProposeAdjustmentRequest struct {
+	UserID uuid.UUID `json:"user_id"`
+	Amount float64   `json:"amount"` // in Rupees; positive credits the user, negative debits
+	Reason string    `json:"reason"`
+}
+
+type ReviewAdjustmentRequest struct {
+	Note string `json:"note"`
+}
+
+type LedgerAdjustment struct {
+	ID              uuid.UUID    `json:"id"`
+	UserID          uuid.UUID    `json:"user_id"`
+	UserName        string       `json:"user_name,omitempty"`
+	UserEmail       string       `json:"user_email,omitempty"`
+	Amount          common.Money `json:"amount"`
+	Reason          string       `json:"reason"`
+	Status          string       `json:"status"`
+	RequestedBy     uuid.UUID    `json:"requested_by"`
+	RequestedByName string       `json:"requested_by_name,omitempty"`
+	ReviewedBy      *uuid.UUID   `json:"reviewed_by"`
+	ReviewedByName  *string      `json:"reviewed_by_name,omitempty"`
+	ReviewNote      string       `json:"review_note,omitempty"`
+	TransactionID   *uuid.UUID   `json:"transaction_id"`
+	CreatedAt       time.Time    `json:"created_at"`
+	ReviewedAt      *time.Time   `json:"reviewed_at"`
+}
+
+type PayoutRequestInput struct {
+	MobileNumber string `json:"mobile_number"`
+	AccountTitle string `json:"account_title"`
+	Note         string `json:"note,omitempty"`
+}
+
+type ReviewPayoutRequest struct {
+	Note string `json:"note"`
+}
+
+// PayoutRequest is a user's request to close their wallet and have the balance
+// sent to a mobile wallet. Amount is only set once finance approves it.
+type PayoutRequest struct {
+	ID               uuid.UUID     `json:"id"`
+	UserID           uuid.UUID     `json:"user_id"`
+	UserName         string        `json:"user_name,omitempty"`
+	UserEmail        string        `json:"user_email,omitempty"`
+	WalletID         uuid.UUID     `json:"wallet_id"`
+	MobileNumber     string        `json:"mobile_number"`
+	AccountTitle     string        `json:"account_title"`
+	Note             string        `json:"note,omitempty"`
+	RequestedBalance common.Money  `json:"requested_balance"`
+	Amount           *common.Money `json:"amount"`
+	Status           string        `json:"status"`
+	ReviewedBy       *uuid.UUID    `json:"reviewed_by"`
+	ReviewedByName   *string       `json:"reviewed_by_name,omitempty"`
+	ReviewNote       string        `json:"review_note,omitempty"`
+	TransactionID    *uuid.UUID    `json:"transaction_id"`
+	BatchID          *uuid.UUID    `json:"batch_id"`
+	CreatedAt        time.Time     `json:"created_at"`
+	ReviewedAt       *time.Time    `json:"reviewed_at"`
+	PaidAt           *time.Time    `json:"paid_at"`
+}
+
+type PayoutBatch struct {
+	ID          uuid.UUID    `json:"id"`
+	PayoutCount int32        `json:"payout_count"`
+	TotalAmount common.Money `json:"total_amount"`
+	CreatedBy   uuid.UUID    `json:"created_by"`
+	CreatedAt   time.Time    `json:"created_at"`
+	CompletedBy *uuid.UUID   `json:"completed_by"`
+	CompletedAt *time.Time   `json:"completed_at"`
+}
+
+type PayoutBatchItem struct {
+	PayoutID     uuid.UUID    `json:"payout_id"`
+	UserID       uuid.UUID    `json:"user_id"`
+	UserName     string       `json:"user_name"`
+	UserEmail    string       `json:"user_email"`
+	MobileNumber string       `json:"mobile_number"`
+	AccountTitle string       `json:"account_title"`
+	Amount       common.Money `json:"amount"`
+}
+
+// SpendLimitOverrideRequest sets per-user caps in Rupees; a nil field keeps
+// the user type's default
+type SpendLimitOverrideRequest struct {
+	PerTransactionLimit *float64 `json:"per_transaction_limit"`
+	DailyLimit          *float64 `json:"daily_limit"`
+	WeeklyLimit         *float64 `json:"weekly_limit"`
+	Reason              string   `json:"reason"`
+}
+
+// SpendLimitSet holds caps in Rupees; nil means no cap
+type SpendLimitSet struct {
+	PerTransaction *common.Money `json:"per_transaction"`
+	Daily          *common.Money `json:"daily"`
+	Weekly         *common.Money `json:"weekly"`
+}
+
+type SpendLimitOverride struct {
+	SpendLimitSet
+	Reason    string    `json:"reason"`
+	SetBy     uuid.UUID `json:"set_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SpendLimits shows a user's default caps, any override, the caps actually
+// enforced and how much of the rolling windows is used up
+type SpendLimits struct {
+	UserID       uuid.UUID           `json:"user_id"`
+	UserType     string              `json:"user_type"`
+	Defaults     SpendLimitSet       `json:"defaults"`
+	Override     *SpendLimitOverride `json:"override"`
+	Effective    SpendLimitSet       `json:"effective"`
+	SpentLast24h common.Money        `json:"spent_last_24h"`
+	SpentLast7d  common.Money        `json:"spent_last_7d"`
+}
+
+// Integrity finding kinds reported by the ledger verifier
+const (
+	FindingHashMismatch          = "HASH_MISMATCH"
+	FindingBalanceDiscontinuity  = "BALANCE_DISCONTINUITY"
+	FindingUnbalancedTransaction = "UNBALANCED_TRANSACTION"
+	FindingMaterializedDrift     = "MATERIALIZED_BALANCE_DRIFT"
+
+	// trial balance findings
+	FindingLedgerNotBalanced = "LEDGER_NOT_BALANCED"
+	FindingLiabilityMismatch = "LIABILITY_MISMATCH"
+	FindingNegativeBalance   = "NEGATIVE_WALLET_BALANCE"
+)
+
+type IntegrityFinding struct {
+	Kind          string     `json:"kind"`
+	WalletID      *uuid.UUID `json:"wallet_id,omitempty"`
+	LedgerEntryID *uuid.UUID `json:"ledger_entry_id,omitempty"`
+	TransactionID *uuid.UUID `json:"transaction_id,omitempty"`
+	Expected      string     `json:"expected"`
+	Actual        string     `json:"actual"`
+	Detail        string     `json:"detail"`
+}
+
+type IntegrityReport struct {
+	StartedAt           time.Time          `json:"started_at"`
+	CompletedAt         time.Time          `json:"completed_at"`
+	WalletsChecked      int                `json:"wallets_checked"`
+	EntriesChecked      int64              `json:"entries_checked"`
+	TransactionsChecked int64              `json:"transactions_checked"`
+	FindingsCount       int                `json:"findings_count"`
+	Healthy             bool               `json:"healthy"`
+	Findings            []IntegrityFinding `json:"findings"`
+}
+
+// IntegrityRun is a stored full-ledger verification. Counts and findings are
+// filled in once it completes; Findings holds at most the first
+// maxStoredIntegrityFindings of FindingsCount.
+type IntegrityRun struct {
+	ID                  uuid.UUID          `json:"id"`
+	Status              string             `json:"status"`
+	RequestedBy         *uuid.UUID         `json:"requested_by,omitempty"`
+	WalletsChecked      int                `json:"wallets_checked"`
+	EntriesChecked      int64              `json:"entries_checked"`
+	TransactionsChecked int64              `json:"transactions_checked"`
+	FindingsCount       int                `json:"findings_count"`
+	Healthy             *bool              `json:"healthy"`
+	Findings            []IntegrityFinding `json:"findings"`
+	LastError           string             `json:"last_error,omitempty"`
+	CreatedAt           time.Time          `json:"created_at"`
+	StartedAt           *time.Time         `json:"started_at,omitempty"`
+	CompletedAt         *time.Time         `json:"completed_at,omitempty"`
+}
+
+// IntegrityRunJob is the payload of a RUN_LEDGER_INTEGRITY_CHECK job
+type IntegrityRunJob struct {
+	RunID uuid.UUID `json:"run_id"`
+}
+
+type CheckpointSummary struct {
+	RunID          uuid.UUID `json:"run_id"`
+	WalletsChecked int64     `json:"wallets_checked"`
+	DriftedWallets int       `json:"drifted_wallets"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// TrialBalanceLine totals one wallet type; system wallets get a line per name
+type TrialBalanceLine struct {
+	WalletType          string       `json:"wallet_type"`
+	WalletName          string       `json:"wallet_name,omitempty"`
+	WalletCount         int64        `json:"wallet_count"`
+	TotalCredits        common.Money `json:"total_credits"`
+	TotalDebits         common.Money `json:"total_debits"`
+	Balance             common.Money `json:"balance"`
+	MaterializedBalance common.Money `json:"materialized_balance,omitempty"`
+}
+
+// TrialBalanceReport is the trial balance at AsOf. A nil AsOf means "now",
+// which is also the only case where materialized balances are compared.
+type TrialBalanceReport struct {
+	AsOf             *time.Time         `json:"as_of"`
+	GeneratedAt      time.Time          `json:"generated_at"`
+	Lines            []TrialBalanceLine `json:"lines"`
+	TotalCredits     common.Money       `json:"total_credits"`
+	TotalDebits      common.Money       `json:"total_debits"`
+	LiabilityBalance common.Money       `json:"liability_balance"`
+	PromoBalance     common.Money       `json:"promo_balance"`
+	PersonalBalance  common.Money       `json:"personal_balance"`
+	RevenueBalance   common.Money       `json:"revenue_balance"`
+	MerchantBalance  common.Money       `json:"merchant_balance"`
+	LiabilityDrift   common.Money       `json:"liability_drift"`
+	Healthy          bool               `json:"healthy"`
+	Findings         []IntegrityFinding `json:"findings"`
+}
+
+// LedgerPartition is one monthly partition of the ledger. Row counts are the
+// planner's estimate, which is cheap enough to list every partition.
+type LedgerPartition struct {
+	Name          string         `json:"name"`
+	Month         string         `json:"month,omitempty"` // YYYY-MM, empty for the default partition
+	EstimatedRows int64          `json:"estimated_rows"`
+	SizeBytes     int64          `json:"size_bytes"`
+	Cold          bool           `json:"cold"`
+	Archive       *LedgerArchive `json:"archive"`
+}
+
+type LedgerArchive struct {
+	ID            uuid.UUID    `json:"id"`
+	PartitionName string       `json:"partition_name"`
+	PeriodStart   time.Time    `json:"period_start"`
+	PeriodEnd     time.Time    `json:"period_end"`
+	FileName      string       `json:"file_name"`
+	RowCount      int64        `json:"row_count"`
+	AmountSum     common.Money `json:"amount_sum"`
+	ContentSHA256 string       `json:"content_sha256"`
+	FileSHA256    string       `json:"file_sha256"`
+	ArchivedBy    *uuid.UUID   `json:"archived_by"`
+	CreatedAt     time.Time    `json:"created_at"`
+	VerifiedAt    *time.Time   `json:"verified_at"`
+}
+
+type LedgerArchiveVerification struct {
+	Archive       *LedgerArchive `json:"archive"`
+	FileIntact    bool           `json:"file_intact"`
+	ContentIntact bool           `json:"content_intact"`
+	MatchesLedger bool           `json:"matches_ledger"`
+	ArchivedRows  int64          `json:"archived_rows"`
+	LedgerRows    int64          `json:"ledger_rows"`
+	Verified      bool           `json:"verified"`
+	Findings      []string       `json:"findings"`
 }
@@ -0,0 +1,188 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	commonerrors "github.com/hash-walker/giki-wallet/internal/common/errors"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	JobLedgerPartitionMaintenance = "LEDGER_PARTITION_MAINTENANCE"
+
+	// ledgerPartitionsAhead is how many months past the current one always
+	// have a partition, so inserts never fall into the default partition
+	ledgerPartitionsAhead = 3
+
+	// ledgerHotMonths is how many months, counting the current one, stay out
+	// of the archive because entries may still be reversed or reported on
+	ledgerHotMonths = 3
+
+	ledgerDefaultPartition = "ledger_default"
+	ledgerPartitionPrefix  = "ledger_"
+	ledgerPartitionLayout  = "2006_01"
+)
+
+// RegisterLedgerPartitionJobs makes sure the upcoming months have partitions
+// right away and hands the nightly maintenance to the worker.
+func (s *Service) RegisterLedgerPartitionJobs(ctx context.Context) error {
+	s.worker.RegisterHandler(JobLedgerPartitionMaintenance, s.handleLedgerPartitionMaintenance)
+
+	if err := s.EnsureLedgerPartitions(ctx, time.Now()); err != nil {
+		return err
+	}
+
+	return s.worker.EnsureScheduled(ctx, JobLedgerPartitionMaintenance, struct{}{}, nextMidnight(time.Now()))
+}
+
+// handleLedgerPartitionMaintenance creates the coming months' partitions,
+// archives months that have gone cold and schedules tomorrow's run.
+func (s *Service) handleLedgerPartitionMaintenance(ctx context.Context, _ json.RawMessage) error {
+	now := time.Now()
+
+	if err := s.worker.EnsureScheduled(ctx, JobLedgerPartitionMaintenance, struct{}{}, nextMidnight(now)); err != nil {
+		return err
+	}
+
+	if err := s.EnsureLedgerPartitions(ctx, now); err != nil {
+		return err
+	}
+
+	archived, err := s.ArchiveColdLedgerPartitions(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[LedgerPartitions] partitions ready through %s, archived %d cold months",
+		ledgerMonthStart(now).AddDate(0, ledgerPartitionsAhead, 0).Format("2006-01"), archived)
+	return nil
+}
+
+// EnsureLedgerPartitions creates the partitions for the current month and the
+// next few, then moves anything that landed in the default partition into a
+// partition of its own.
+func (s *Service) EnsureLedgerPartitions(ctx context.Context, now time.Time) error {
+	month := ledgerMonthStart(now)
+
+	for i := 0; i <= ledgerPartitionsAhead; i++ {
+		_, err := s.q.EnsureLedgerPartition(ctx, pgtype.Date{Time: month.AddDate(0, i, 0), Valid: true})
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabase, err)
+		}
+	}
+
+	drained, err := s.q.DrainLedgerDefaultPartition(ctx)
+	if err != nil {
+		return commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	if drained > 0 {
+		log.Printf("[LedgerPartitions] moved entries for %d months out of the default partition", drained)
+	}
+
+	return nil
+}
+
+// ListLedgerPartitions lists the ledger's partitions, oldest month first, with
+// their archive if they have one
+func (s *Service) ListLedgerPartitions(ctx context.Context) ([]LedgerPartition, error) {
+	rows, err := s.q.ListLedgerPartitions(ctx)
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	archives, err := s.q.ListLedgerArchives(ctx)
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	byPartition := make(map[string]*LedgerArchive, len(archives))
+	for _, a := range archives {
+		byPartition[a.PartitionName] = mapDBLedgerArchive(a)
+	}
+
+	now := time.Now()
+	partitions := make([]LedgerPartition, 0, len(rows))
+	for _, r := range rows {
+		p := LedgerPartition{
+			Name:          r.PartitionName,
+			EstimatedRows: r.EstimatedRows,
+			SizeBytes:     r.SizeBytes,
+			Archive:       byPartition[r.PartitionName],
+		}
+
+		if month, ok := parseLedgerPartitionName(r.PartitionName); ok {
+			p.Month = month.Format("2006-01")
+			p.Cold = isColdLedgerMonth(month, now)
+		}
+
+		partitions = append(partitions, p)
+	}
+
+	return partitions, nil
+}
+
+// ArchiveColdLedgerPartitions archives every cold month that has entries and
+// no archive yet, and returns how many it archived
+func (s *Service) ArchiveColdLedgerPartitions(ctx context.Context, now time.Time) (int, error) {
+	partitions, err := s.ListLedgerPartitions(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	archived := 0
+	for _, p := range partitions {
+		if !p.Cold || p.Archive != nil {
+			continue
+		}
+
+		month, _ := parseLedgerPartitionName(p.Name)
+
+		_, err := s.ArchiveLedgerPartition(ctx, month, nil)
+		if err != nil {
+			if errors.Is(err, ErrLedgerPartitionEmpty) {
+				continue
+			}
+			return archived, err
+		}
+
+		archived++
+	}
+
+	return archived, nil
+}
+
+// ledgerMonthStart is the first instant of t's month in UTC, which is how the
+// partitions are bounded
+func ledgerMonthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func ledgerPartitionName(month time.Time) string {
+	return ledgerPartitionPrefix + ledgerMonthStart(month).Format(ledgerPartitionLayout)
+}
+
+// parseLedgerPartitionName returns the month a ledger_YYYY_MM partition holds;
+// the default partition has none
+func parseLedgerPartitionName(name string) (time.Time, bool) {
+	if name == ledgerDefaultPartition || !strings.HasPrefix(name, ledgerPartitionPrefix) {
+		return time.Time{}, false
+	}
+
+	month, err := time.Parse(ledgerPartitionLayout, strings.TrimPrefix(name, ledgerPartitionPrefix))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return month, true
+}
+
+func isColdLedgerMonth(month, now time.Time) bool {
+	return ledgerMonthStart(month).Before(ledgerMonthStart(now).AddDate(0, -(ledgerHotMonths - 1), 0))
+}
@@ -0,0 +1,519 @@
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/hash-walker/giki-wallet/internal/common"
+	commonerrors "github.com/hash-walker/giki-wallet/internal/common/errors"
+	wallet "github.com/hash-walker/giki-wallet/internal/wallet/wallet_db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var nonDigits = regexp.MustCompile(`\D`)
+
+// RequestPayout opens a closure request for the user's wallet. The balance is
+// only recorded for reference here; whatever is left when finance approves is
+// what gets paid out.
+func (s *Service) RequestPayout(ctx context.Context, userID uuid.UUID, req PayoutRequestInput) (*PayoutRequest, error) {
+	mobile, ok := normalizeMobileNumber(req.MobileNumber)
+	if !ok {
+		return nil, ErrInvalidPayout.WithDetails("mobile_number", "must be a Pakistani mobile number, e.g. 03001234567")
+	}
+
+	title := strings.TrimSpace(req.AccountTitle)
+	if title == "" || len(title) > 100 {
+		return nil, ErrInvalidPayout.WithDetails("account_title", "required, at most 100 characters")
+	}
+
+	w, err := s.GetOrCreateWallet(ctx, nil, userID)
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	if err := checkCanSend(w, TransactionTypePayout); err != nil {
+		return nil, err
+	}
+
+	balance, err := s.getWalletBalance(ctx, s.q, w.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if balance <= 0 {
+		return nil, ErrNothingToPayout
+	}
+
+	p, err := s.q.CreatePayoutRequest(ctx, wallet.CreatePayoutRequestParams{
+		UserID:           userID,
+		WalletID:         w.ID,
+		MobileNumber:     mobile,
+		AccountTitle:     title,
+		UserNote:         common.StringToText(strings.TrimSpace(req.Note)),
+		RequestedBalance: balance,
+	})
+	if err != nil {
+		if CheckUniqueConstraintViolation(err) {
+			return nil, ErrPayoutAlreadyPending
+		}
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	return mapDBPayout(p), nil
+}
+
+// GetLatestPayout returns the user's most recent payout request
+func (s *Service) GetLatestPayout(ctx context.Context, userID uuid.UUID) (*PayoutRequest, error) {
+	p, err := s.q.GetLatestPayoutRequest(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrPayoutNotFound
+		}
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	return mapDBPayout(p), nil
+}
+
+// CancelPayout withdraws the user's pending payout request
+func (s *Service) CancelPayout(ctx context.Context, userID uuid.UUID) (*PayoutRequest, error) {
+	p, err := s.q.CancelPayoutRequest(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrPayoutNotFound
+		}
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	return mapDBPayout(p), nil
+}
+
+// ApprovePayout debits the wallet's whole balance into the liability wallet as a
+// PAYOUT and closes the wallet, in one transaction. The money then leaves the
+// system when finance executes the exported batch.
+func (s *Service) ApprovePayout(ctx context.Context, payoutID, approverID uuid.UUID, note string) (*PayoutRequest, error) {
+	liabilityWalletID, err := s.GetSystemWalletByName(ctx, GikiWallet, SystemWalletLiability)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *PayoutRequest
+
+	err = common.WithTransaction(ctx, s.dbPool, func(tx pgx.Tx) error {
+		walletQ := s.q.WithTx(tx)
+
+		p, err := s.lockPendingPayout(ctx, walletQ, payoutID)
+		if err != nil {
+			return err
+		}
+
+		if p.UserID == approverID {
+			return ErrSelfApproval
+		}
+
+		locked, err := walletQ.GetWalletForUpdate(ctx, p.WalletID)
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabase, err)
+		}
+
+		balance, err := s.getWalletBalance(ctx, walletQ, locked.ID)
+		if err != nil {
+			return err
+		}
+
+		if balance <= 0 {
+			return ErrNothingToPayout
+		}
+
+		headerID, err := s.PostJournalEntry(ctx, tx, JournalEntry{
+			Type:        TransactionTypePayout,
+			ReferenceID: p.ID.String(),
+			Description: fmt.Sprintf("Payout to %s (%s)", p.MobileNumber, p.AccountTitle),
+			Legs: []JournalLeg{
+				{WalletID: locked.ID, Amount: -balance},
+				{WalletID: liabilityWalletID, Amount: balance},
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		if _, err := walletQ.UpdateWalletStatus(ctx, wallet.UpdateWalletStatusParams{
+			ID:     locked.ID,
+			Status: WalletStatusClosed,
+		}); err != nil {
+			return commonerrors.Wrap(ErrDatabase, err)
+		}
+
+		approved, err := walletQ.ApprovePayoutRequest(ctx, wallet.ApprovePayoutRequestParams{
+			ID:            p.ID,
+			Amount:        pgtype.Int8{Int64: balance, Valid: true},
+			ReviewedBy:    common.GoogleUUIDtoPgUUID(approverID, true),
+			ReviewNote:    common.StringToText(strings.TrimSpace(note)),
+			TransactionID: common.GoogleUUIDtoPgUUID(headerID, true),
+		})
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabase, err)
+		}
+
+		result = mapDBPayout(approved)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// RejectPayout closes a pending payout without touching the wallet
+func (s *Service) RejectPayout(ctx context.Context, payoutID, reviewerID uuid.UUID, note string) (*PayoutRequest, error) {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return nil, ErrReasonRequired
+	}
+
+	var result *PayoutRequest
+
+	err := common.WithTransaction(ctx, s.dbPool, func(tx pgx.Tx) error {
+		walletQ := s.q.WithTx(tx)
+
+		p, err := s.lockPendingPayout(ctx, walletQ, payoutID)
+		if err != nil {
+			return err
+		}
+
+		if p.UserID == reviewerID {
+			return ErrSelfApproval
+		}
+
+		rejected, err := walletQ.RejectPayoutRequest(ctx, wallet.RejectPayoutRequestParams{
+			ID:         p.ID,
+			ReviewedBy: common.GoogleUUIDtoPgUUID(reviewerID, true),
+			ReviewNote: common.StringToText(note),
+		})
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabase, err)
+		}
+
+		result = mapDBPayout(rejected)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *Service) ListPayouts(ctx context.Context, status string, page, pageSize int) ([]PayoutRequest, int64, error) {
+	rows, err := s.q.ListPayoutRequests(ctx, wallet.ListPayoutRequestsParams{
+		Status: strings.ToUpper(status),
+		Limit:  int32(pageSize),
+		Offset: int32((page - 1) * pageSize),
+	})
+	if err != nil {
+		return nil, 0, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	var totalCount int64
+	items := make([]PayoutRequest, 0, len(rows))
+	for _, r := range rows {
+		totalCount = r.TotalCount
+		p := mapDBPayout(wallet.GikiWalletPayoutRequest{
+			ID:               r.ID,
+			UserID:           r.UserID,
+			WalletID:         r.WalletID,
+			MobileNumber:     r.MobileNumber,
+			AccountTitle:     r.AccountTitle,
+			UserNote:         r.UserNote,
+			RequestedBalance: r.RequestedBalance,
+			Amount:           r.Amount,
+			Status:           r.Status,
+			ReviewedBy:       r.ReviewedBy,
+			ReviewNote:       r.ReviewNote,
+			TransactionID:    r.TransactionID,
+			BatchID:          r.BatchID,
+			CreatedAt:        r.CreatedAt,
+			ReviewedAt:       r.ReviewedAt,
+			PaidAt:           r.PaidAt,
+		})
+		p.UserName = r.UserName
+		p.UserEmail = r.UserEmail
+		p.ReviewedByName = common.TextToStringPointer(r.ReviewedByName)
+		items = append(items, *p)
+	}
+
+	return items, totalCount, nil
+}
+
+// =============================================================================
+// PAYOUT BATCHES
+// =============================================================================
+
+// CreatePayoutBatch claims every approved payout that has not been exported yet
+// into a new batch, so each payout appears in exactly one batch file.
+func (s *Service) CreatePayoutBatch(ctx context.Context, createdBy uuid.UUID) (*PayoutBatch, []PayoutBatchItem, error) {
+	var (
+		batch *PayoutBatch
+		items []PayoutBatchItem
+	)
+
+	err := common.WithTransaction(ctx, s.dbPool, func(tx pgx.Tx) error {
+		walletQ := s.q.WithTx(tx)
+
+		created, err := walletQ.CreatePayoutBatch(ctx, createdBy)
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabase, err)
+		}
+
+		rows, err := walletQ.AssignApprovedPayoutsToBatch(ctx, common.GoogleUUIDtoPgUUID(created.ID, true))
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabase, err)
+		}
+
+		if len(rows) == 0 {
+			return ErrNoPayoutsToBatch
+		}
+
+		var total int64
+		items = make([]PayoutBatchItem, 0, len(rows))
+		for _, r := range rows {
+			total += r.Amount.Int64
+			items = append(items, PayoutBatchItem{
+				PayoutID:     r.ID,
+				UserID:       r.UserID,
+				UserName:     r.UserName,
+				UserEmail:    r.UserEmail,
+				MobileNumber: r.MobileNumber,
+				AccountTitle: r.AccountTitle,
+				Amount:       common.Money(r.Amount.Int64),
+			})
+		}
+
+		updated, err := walletQ.SetPayoutBatchTotals(ctx, wallet.SetPayoutBatchTotalsParams{
+			ID:          created.ID,
+			PayoutCount: int32(len(rows)),
+			TotalAmount: total,
+		})
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabase, err)
+		}
+
+		batch = mapDBPayoutBatch(updated)
+		return nil
+	})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return batch, items, nil
+}
+
+// GetPayoutBatch returns a batch with its payouts, e.g. to download the file again
+func (s *Service) GetPayoutBatch(ctx context.Context, batchID uuid.UUID) (*PayoutBatch, []PayoutBatchItem, error) {
+	b, err := s.q.GetPayoutBatch(ctx, batchID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, ErrPayoutBatchNotFound
+		}
+		return nil, nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	rows, err := s.q.ListPayoutBatchItems(ctx, common.GoogleUUIDtoPgUUID(batchID, true))
+	if err != nil {
+		return nil, nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	items := make([]PayoutBatchItem, 0, len(rows))
+	for _, r := range rows {
+		items = append(items, PayoutBatchItem{
+			PayoutID:     r.ID,
+			UserID:       r.UserID,
+			UserName:     r.UserName,
+			UserEmail:    r.UserEmail,
+			MobileNumber: r.MobileNumber,
+			AccountTitle: r.AccountTitle,
+			Amount:       common.Money(r.Amount.Int64),
+		})
+	}
+
+	return mapDBPayoutBatch(b), items, nil
+}
+
+func (s *Service) ListPayoutBatches(ctx context.Context, page, pageSize int) ([]PayoutBatch, int64, error) {
+	rows, err := s.q.ListPayoutBatches(ctx, wallet.ListPayoutBatchesParams{
+		Limit:  int32(pageSize),
+		Offset: int32((page - 1) * pageSize),
+	})
+	if err != nil {
+		return nil, 0, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	var totalCount int64
+	batches := make([]PayoutBatch, 0, len(rows))
+	for _, r := range rows {
+		totalCount = r.TotalCount
+		batches = append(batches, *mapDBPayoutBatch(wallet.GikiWalletPayoutBatch{
+			ID:          r.ID,
+			PayoutCount: r.PayoutCount,
+			TotalAmount: r.TotalAmount,
+			CreatedBy:   r.CreatedBy,
+			CreatedAt:   r.CreatedAt,
+			CompletedBy: r.CompletedBy,
+			CompletedAt: r.CompletedAt,
+		}))
+	}
+
+	return batches, totalCount, nil
+}
+
+// CompletePayoutBatch records that finance has sent every payout in the batch
+func (s *Service) CompletePayoutBatch(ctx context.Context, batchID, actorID uuid.UUID) (*PayoutBatch, error) {
+	var result *PayoutBatch
+
+	err := common.WithTransaction(ctx, s.dbPool, func(tx pgx.Tx) error {
+		walletQ := s.q.WithTx(tx)
+
+		completed, err := walletQ.CompletePayoutBatch(ctx, wallet.CompletePayoutBatchParams{
+			ID:          batchID,
+			CompletedBy: common.GoogleUUIDtoPgUUID(actorID, true),
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				if _, getErr := walletQ.GetPayoutBatch(ctx, batchID); errors.Is(getErr, pgx.ErrNoRows) {
+					return ErrPayoutBatchNotFound
+				}
+				return ErrPayoutBatchCompleted
+			}
+			return commonerrors.Wrap(ErrDatabase, err)
+		}
+
+		if _, err := walletQ.MarkBatchPayoutsPaid(ctx, common.GoogleUUIDtoPgUUID(batchID, true)); err != nil {
+			return commonerrors.Wrap(ErrDatabase, err)
+		}
+
+		result = mapDBPayoutBatch(completed)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// RenderPayoutBatchCSV writes the batch in the column order finance uploads to
+// the bulk disbursement portal, with a trailing total row.
+func RenderPayoutBatchCSV(batch *PayoutBatch, items []PayoutBatchItem) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	w := csv.NewWriter(buf)
+
+	rows := [][]string{{"Payout ID", "Mobile Number", "Account Title", "Amount", "User Email"}}
+	for _, item := range items {
+		rows = append(rows, []string{
+			item.PayoutID.String(),
+			item.MobileNumber,
+			item.AccountTitle,
+			item.Amount.Decimal(),
+			item.UserEmail,
+		})
+	}
+
+	rows = append(rows, []string{"TOTAL", "", fmt.Sprintf("%d payouts", batch.PayoutCount), batch.TotalAmount.Decimal(), ""})
+
+	if err := w.WriteAll(rows); err != nil {
+		return nil, commonerrors.Wrap(commonerrors.ErrInternal, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// PayoutBatchFileName is the download name of a batch file
+func PayoutBatchFileName(batch *PayoutBatch) string {
+	return fmt.Sprintf("payout_batch_%s_%s.csv", batch.CreatedAt.Format("2006-01-02"), batch.ID.String()[:8])
+}
+
+func (s *Service) lockPendingPayout(ctx context.Context, walletQ *wallet.Queries, payoutID uuid.UUID) (wallet.GikiWalletPayoutRequest, error) {
+	p, err := walletQ.GetPayoutRequestForUpdate(ctx, payoutID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return p, ErrPayoutNotFound
+		}
+		return p, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	if p.Status != PayoutStatusPending {
+		return p, ErrPayoutNotPending.WithDetails("status", p.Status)
+	}
+
+	return p, nil
+}
+
+// normalizeMobileNumber accepts 03XXXXXXXXX, 923XXXXXXXXX or +92 3XX XXXXXXX
+// and returns the 11-digit local form mobile wallets are registered under.
+func normalizeMobileNumber(mobile string) (string, bool) {
+	digits := nonDigits.ReplaceAllString(mobile, "")
+
+	switch {
+	case len(digits) == 12 && strings.HasPrefix(digits, "92"):
+		digits = "0" + digits[2:]
+	case len(digits) == 10 && strings.HasPrefix(digits, "3"):
+		digits = "0" + digits
+	}
+
+	if len(digits) != 11 || !strings.HasPrefix(digits, "03") {
+		return "", false
+	}
+
+	return digits, true
+}
+
+func mapDBPayout(p wallet.GikiWalletPayoutRequest) *PayoutRequest {
+	var amount *common.Money
+	if p.Amount.Valid {
+		amount = common.MoneyPtr(p.Amount.Int64)
+	}
+
+	return &PayoutRequest{
+		ID:               p.ID,
+		UserID:           p.UserID,
+		WalletID:         p.WalletID,
+		MobileNumber:     p.MobileNumber,
+		AccountTitle:     p.AccountTitle,
+		Note:             common.TextToString(p.UserNote),
+		RequestedBalance: common.Money(p.RequestedBalance),
+		Amount:           amount,
+		Status:           p.Status,
+		ReviewedBy:       common.PgUUIDToUUIDPointer(p.ReviewedBy),
+		ReviewNote:       common.TextToString(p.ReviewNote),
+		TransactionID:    common.PgUUIDToUUIDPointer(p.TransactionID),
+		BatchID:          common.PgUUIDToUUIDPointer(p.BatchID),
+		CreatedAt:        p.CreatedAt,
+		ReviewedAt:       common.TimestamptzToTimePointer(p.ReviewedAt),
+		PaidAt:           common.TimestamptzToTimePointer(p.PaidAt),
+	}
+}
+
+func mapDBPayoutBatch(b wallet.GikiWalletPayoutBatch) *PayoutBatch {
+	return &PayoutBatch{
+		ID:          b.ID,
+		PayoutCount: b.PayoutCount,
+		TotalAmount: common.Money(b.TotalAmount),
+		CreatedBy:   b.CreatedBy,
+		CreatedAt:   b.CreatedAt,
+		CompletedBy: common.PgUUIDToUUIDPointer(b.CompletedBy),
+		CompletedAt: common.TimestamptzToTimePointer(b.CompletedAt),
+	}
+}
@@ -0,0 +1,34 @@
+package wallet
+
+import "testing"
+
+func TestNormalizeMobileNumber(t *testing.T) {
+	tests := []struct {
+		name   string
+		mobile string
+		want   string
+		wantOK bool
+	}{
+		{"local", "03001234567", "03001234567", true},
+		{"international", "923001234567", "03001234567", true},
+		{"international with plus and spaces", "+92 300 1234567", "03001234567", true},
+		{"dashes", "0300-1234567", "03001234567", true},
+		{"without leading zero", "3001234567", "03001234567", true},
+		{"double zero prefix", "00923001234567", "", false},
+		{"landline", "0512345678", "", false},
+		{"international landline", "92512345678", "", false},
+		{"too short", "0300123456", "", false},
+		{"too long", "030012345678", "", false},
+		{"empty", "", "", false},
+		{"letters", "03OO1234567", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := normalizeMobileNumber(tt.mobile)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("normalizeMobileNumber(%q) = %q, %v, want %q, %v", tt.mobile, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
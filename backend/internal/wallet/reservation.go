@@ -0,0 +1,123 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hash-walker/giki-wallet/internal/common"
+	commonerrors "github.com/hash-walker/giki-wallet/internal/common/errors"
+	wallet "github.com/hash-walker/giki-wallet/internal/wallet/wallet_db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ReserveFunds puts an authorization hold of amount paisa on a wallet for the
+// given reference. Nothing is posted to the ledger; the reservation only lowers
+// the spendable balance until it is captured, released or expires; a zero
+// expiresAt holds the funds until the reservation is captured or released. It
+// must run in the caller's transaction so the reservation lives and dies with
+// whatever it was made for.
+func (s *Service) ReserveFunds(ctx context.Context, tx pgx.Tx, walletID uuid.UUID, amount int64, txnType, referenceID, description string, expiresAt time.Time) error {
+	if amount <= 0 {
+		return commonerrors.Wrap(commonerrors.ErrInvalidInput, fmt.Errorf("reservation amount must be positive"))
+	}
+
+	walletQ := s.q.WithTx(tx)
+
+	// the same row lock PostJournalEntry takes, so a concurrent debit cannot
+	// spend the funds between the check and the insert
+	w, err := walletQ.GetWalletForUpdate(ctx, walletID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrWalletNotFound
+		}
+		return commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	if err := checkCanSend(w, txnType); err != nil {
+		return err
+	}
+
+	balance, err := s.getWalletBalance(ctx, walletQ, walletID)
+	if err != nil {
+		return err
+	}
+
+	reserved, err := walletQ.GetReservedAmount(ctx, walletID)
+	if err != nil {
+		return commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	if !checkBalance(balance-reserved, amount) {
+		return ErrInsufficientFunds
+	}
+
+	// fail now rather than when the reservation is captured
+	if err := s.checkSpendLimits(ctx, walletQ, walletID, amount, txnType); err != nil {
+		return err
+	}
+
+	_, err = walletQ.CreateWalletReservation(ctx, wallet.CreateWalletReservationParams{
+		WalletID:    walletID,
+		Amount:      amount,
+		ReferenceID: referenceID,
+		Description: common.StringToText(description),
+		ExpiresAt:   pgtype.Timestamptz{Time: expiresAt, Valid: !expiresAt.IsZero()},
+	})
+	if err != nil {
+		return commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	return nil
+}
+
+// CaptureReservation marks the active reservation for a reference as captured
+// so its funds become spendable by the debit the caller posts next in the same
+// transaction. It reports whether there was a reservation to capture; a missing
+// one is not an error since the purchase may predate reservations.
+func (s *Service) CaptureReservation(ctx context.Context, tx pgx.Tx, referenceID string) (bool, error) {
+	_, err := s.q.WithTx(tx).CaptureWalletReservation(ctx, referenceID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	return true, nil
+}
+
+// ReleaseReservations gives the funds of the active reservations for the given
+// references back to their wallets
+func (s *Service) ReleaseReservations(ctx context.Context, tx pgx.Tx, referenceIDs []string) error {
+	return s.resolveReservations(ctx, tx, referenceIDs, ReservationStatusReleased)
+}
+
+// ExpireReservations is ReleaseReservations for reservations whose hold ran out
+func (s *Service) ExpireReservations(ctx context.Context, tx pgx.Tx, referenceIDs []string) error {
+	return s.resolveReservations(ctx, tx, referenceIDs, ReservationStatusExpired)
+}
+
+func (s *Service) resolveReservations(ctx context.Context, tx pgx.Tx, referenceIDs []string, status string) error {
+	if len(referenceIDs) == 0 {
+		return nil
+	}
+
+	walletQ := s.q
+	if tx != nil {
+		walletQ = s.q.WithTx(tx)
+	}
+
+	_, err := walletQ.ResolveWalletReservations(ctx, wallet.ResolveWalletReservationsParams{
+		Status:       status,
+		ReferenceIds: referenceIDs,
+	})
+	if err != nil {
+		return commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	return nil
+}
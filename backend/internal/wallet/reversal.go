@@ -0,0 +1,95 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/hash-walker/giki-wallet/internal/common"
+	commonerrors "github.com/hash-walker/giki-wallet/internal/common/errors"
+	"github.com/jackc/pgx/v5"
+)
+
+// ReverseTransaction posts a REVERSAL header whose legs mirror the original.
+// The original header row is locked while reversing, and the unique index on
+// reverses_transaction_id guarantees a transaction is only ever reversed once.
+func (s *Service) ReverseTransaction(ctx context.Context, headerID uuid.UUID, reason string) (*ReversalResult, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrReasonRequired
+	}
+
+	var result *ReversalResult
+
+	err := common.WithTransaction(ctx, s.dbPool, func(tx pgx.Tx) error {
+		var err error
+		result, err = s.reverseTransactionTx(ctx, tx, headerID, reason)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *Service) reverseTransactionTx(ctx context.Context, tx pgx.Tx, headerID uuid.UUID, reason string) (*ReversalResult, error) {
+	walletQ := s.q.WithTx(tx)
+
+	original, err := walletQ.GetTransactionHeaderForUpdate(ctx, headerID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	if original.ReversesTransactionID.Valid {
+		return nil, ErrCannotReverseReversal
+	}
+
+	if _, err := walletQ.GetReversalOfTransaction(ctx, common.GoogleUUIDtoPgUUID(headerID, true)); err == nil {
+		return nil, ErrAlreadyReversed
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	legs, err := walletQ.GetLedgerEntriesByTransaction(ctx, headerID)
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	mirrored := make([]JournalLeg, 0, len(legs))
+	var amount int64
+	for _, leg := range legs {
+		mirrored = append(mirrored, JournalLeg{WalletID: leg.WalletID, Amount: -leg.Amount})
+		if leg.Amount > 0 {
+			amount += leg.Amount
+		}
+	}
+
+	reversalID, err := s.PostJournalEntry(ctx, tx, JournalEntry{
+		Type:                  TransactionTypeReversal,
+		ReferenceID:           headerID.String(),
+		Description:           fmt.Sprintf("Reversal of %s %s: %s", original.Type, original.ReferenceID, reason),
+		Legs:                  mirrored,
+		ReversesTransactionID: headerID,
+	})
+	if err != nil {
+		if CheckUniqueConstraintViolation(err) {
+			return nil, ErrAlreadyReversed
+		}
+		return nil, err
+	}
+
+	return &ReversalResult{
+		ReversalID:          reversalID,
+		OriginalID:          original.ID,
+		OriginalType:        original.Type,
+		OriginalReferenceID: original.ReferenceID,
+		Amount:              common.Money(amount),
+		Reason:              reason,
+	}, nil
+}
@@ -13,7 +13,9 @@ import (
 	"github.com/google/uuid"
 	"github.com/hash-walker/giki-wallet/internal/common"
 	commonerrors "github.com/hash-walker/giki-wallet/internal/common/errors"
+	"github.com/hash-walker/giki-wallet/internal/config_management"
 	wallet "github.com/hash-walker/giki-wallet/internal/wallet/wallet_db"
+	"github.com/hash-walker/giki-wallet/internal/worker"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -24,17 +26,26 @@ type Service struct {
 	q            *wallet.Queries
 	dbPool       *pgxpool.Pool
 	ledgerSecret string
+	archiveDir   string
+	configS      *config_management.Service
+	worker       *worker.JobWorker
+	loc          *time.Location
 }
 
-func NewService(dbPool *pgxpool.Pool, ledgerSecret string) *Service {
+func NewService(dbPool *pgxpool.Pool, ledgerSecret string, archiveDir string, configS *config_management.Service, worker *worker.JobWorker, loc *time.Location) *Service {
 	return &Service{
 		q:            wallet.New(dbPool),
 		dbPool:       dbPool,
 		ledgerSecret: ledgerSecret,
+		archiveDir:   archiveDir,
+		configS:      configS,
+		worker:       worker,
+		loc:          loc,
 	}
 }
 
-func (s *Service) executeDoubleEntryTransaction(
+// ExecuteTransaction posts a two-leg journal entry moving amount from sender to receiver
+func (s *Service) ExecuteTransaction(
 	ctx context.Context,
 	tx pgx.Tx,
 	senderWalletID uuid.UUID,
@@ -45,131 +56,20 @@ func (s *Service) executeDoubleEntryTransaction(
 	description string,
 ) error {
 
-	walletQ := s.q
-	if tx != nil {
-		walletQ = s.q.WithTx(tx)
-	}
-
 	if amount <= 0 {
 		return commonerrors.Wrap(commonerrors.ErrInvalidInput, fmt.Errorf("transaction amount must be positive"))
 	}
 
-	// Sort IDs to prevent AB/BA deadlocks
-	firstID, secondID := senderWalletID, receiverWalletID
-	if firstID.String() > secondID.String() {
-		firstID, secondID = secondID, firstID
-	}
-
-	// lock both wallets in consistent order
-	firstWallet, err := walletQ.GetWalletForUpdate(ctx, firstID)
-	if err != nil {
-		fmt.Printf("[DEBUG] Failed to lock first wallet (%s): %v\n", firstID, err)
-		return commonerrors.Wrap(ErrDatabase, err)
-	}
-
-	secondWallet, err := walletQ.GetWalletForUpdate(ctx, secondID)
-	if err != nil {
-		fmt.Printf("[DEBUG] Failed to lock second wallet (%s): %v\n", secondID, err)
-		return commonerrors.Wrap(ErrDatabase, err)
-	}
-
-	// Correctly identify sender/receiver metadata from the locked rows
-	var senderWallet wallet.GikiWalletWallet
-	if firstWallet.ID == senderWalletID {
-		senderWallet = firstWallet
-	} else {
-		senderWallet = secondWallet
-	}
-
-	// get current balances (read once while wallets are locked)
-	senderBalance, err := s.getWalletBalance(ctx, walletQ, senderWalletID)
-	if err != nil {
-		return commonerrors.Wrap(ErrDatabase, err)
-	}
-	receiverBalance, err := s.getWalletBalance(ctx, walletQ, receiverWalletID)
-	if err != nil {
-		return commonerrors.Wrap(ErrDatabase, err)
-	}
-
-	// check sender balance (if not system wallet)
-	if common.TextToString(senderWallet.Type) != string(SystemWalletLiability) && common.TextToString(senderWallet.Type) != string(SystemWalletRevenue) {
-		if !checkBalance(senderBalance, amount) {
-			return ErrInsufficientFunds
-		}
-	}
-
-	txnHeader, err := walletQ.CreateTransactionHeader(ctx, wallet.CreateTransactionHeaderParams{
+	_, err := s.PostJournalEntry(ctx, tx, JournalEntry{
 		Type:        txnType,
 		ReferenceID: referenceID,
-		Description: common.StringToText(description),
+		Description: description,
+		Legs: []JournalLeg{
+			{WalletID: senderWalletID, Amount: -amount},
+			{WalletID: receiverWalletID, Amount: amount},
+		},
 	})
-
-	if err != nil {
-		return commonerrors.Wrap(ErrDatabase, err)
-	}
-
-	debitHash := s.CalculateRowHash(
-		senderWalletID,
-		-amount,
-		txnHeader.ID,
-		senderBalance-amount,
-		txnHeader.CreatedAt,
-	)
-
-	_, err = walletQ.CreateLedgerEntry(ctx, wallet.CreateLedgerEntryParams{
-		WalletID:      senderWalletID,
-		Amount:        -amount,
-		TransactionID: txnHeader.ID,
-		BalanceAfter:  senderBalance - amount,
-		RowHash:       debitHash,
-	})
-
-	if err != nil {
-		fmt.Printf("[DEBUG] Failed to create debit entry: %v\n", err)
-		return commonerrors.Wrap(ErrDatabase, err)
-	}
-
-	creditHash := s.CalculateRowHash(
-		receiverWalletID,
-		amount,
-		txnHeader.ID,
-		receiverBalance+amount,
-		txnHeader.CreatedAt,
-	)
-
-	// create CREDIT entry (receiver gains money)
-	_, err = walletQ.CreateLedgerEntry(ctx, wallet.CreateLedgerEntryParams{
-		WalletID:      receiverWalletID,
-		Amount:        amount,
-		TransactionID: txnHeader.ID,
-		BalanceAfter:  receiverBalance + amount,
-		RowHash:       creditHash,
-	})
-
-	if err != nil {
-		fmt.Printf("[DEBUG] Failed to create credit entry: %v\n", err)
-		return commonerrors.Wrap(ErrDatabase, err)
-	}
-
-	return nil
-}
-
-func (s *Service) ExecuteTransaction(
-	ctx context.Context,
-	tx pgx.Tx,
-	senderWalletID uuid.UUID,
-	receiverWalletID uuid.UUID,
-	amount int64,
-	txnType string,
-	referenceID string,
-	description string,
-) error {
-
-	return s.executeDoubleEntryTransaction(
-		ctx, tx, senderWalletID, receiverWalletID, amount,
-		txnType, referenceID, description,
-	)
-
+	return err
 }
 
 func (s *Service) RefundTicket(ctx context.Context, tx pgx.Tx, userID uuid.UUID, amount int64, referenceID string, description string) error {
@@ -239,47 +139,52 @@ func (s *Service) GetUserBalance(ctx context.Context, userID uuid.UUID) (*Balanc
 		return nil, err
 	}
 
+	reserved, err := s.q.GetReservedAmount(ctx, w.ID)
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
 	return &BalanceResponse{
-		Balance:  float64(balance) / 100.0,
-		Currency: w.Currency,
+		Balance:   common.Money(balance),
+		Reserved:  common.Money(reserved),
+		Available: common.Money(balance - reserved),
+		Currency:  w.Currency,
 	}, nil
 }
 
-func (s *Service) GetUserHistory(ctx context.Context, userID uuid.UUID) ([]TransactionHistoryItem, error) {
+func (s *Service) GetUserHistory(ctx context.Context, userID uuid.UUID, params common.PaginationParams) (*LedgerHistoryWithPagination, error) {
 	w, err := s.GetOrCreateWallet(ctx, nil, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	history, err := s.GetWalletHistory(ctx, w.ID, 1, 100)
-	if err != nil {
-		return nil, err
-	}
-	return history.Data, nil
+	return s.GetWalletHistory(ctx, w.ID, params)
 }
 
-func (s *Service) GetWalletHistory(ctx context.Context, walletID uuid.UUID, page, pageSize int) (*LedgerHistoryWithPagination, error) {
-	offset := (page - 1) * pageSize
+// GetWalletHistory pages through a wallet's ledger newest first using the
+// (created_at, id) keyset in params.After
+func (s *Service) GetWalletHistory(ctx context.Context, walletID uuid.UUID, params common.PaginationParams) (*LedgerHistoryWithPagination, error) {
+	cursorCreatedAt, cursorID := params.CursorArgs()
 	entries, err := s.q.GetLedgerEntriesByWallet(ctx, wallet.GetLedgerEntriesByWalletParams{
-		WalletID: walletID,
-		Limit:    int32(pageSize),
-		Offset:   int32(offset),
+		WalletID:        walletID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		Limit:           params.FetchLimit(),
 	})
 	if err != nil {
 		return nil, commonerrors.Wrap(ErrDatabase, err)
 	}
 
-	var totalCount int64 = 0
-	if len(entries) > 0 {
-		totalCount = entries[0].TotalCount
-	}
+	entries, nextCursor := common.TrimPage(entries, params.PageSize, func(e wallet.GetLedgerEntriesByWalletRow) (time.Time, uuid.UUID) {
+		return e.CreatedAt, e.ID
+	})
 
 	items := make([]TransactionHistoryItem, 0, len(entries))
 	for _, e := range entries {
 		items = append(items, TransactionHistoryItem{
 			ID:           e.ID,
-			Amount:       float64(e.Amount) / 100.0,
-			BalanceAfter: float64(e.BalanceAfter) / 100.0,
+			Amount:       common.Money(e.Amount),
+			BalanceAfter: common.Money(e.BalanceAfter),
 			Type:         e.Type,
 			ReferenceID:  e.ReferenceID,
 			Description:  common.TextToString(e.Description),
@@ -289,41 +194,43 @@ func (s *Service) GetWalletHistory(ctx context.Context, walletID uuid.UUID, page
 
 	return &LedgerHistoryWithPagination{
 		Data:       items,
-		TotalCount: totalCount,
-		Page:       page,
-		PageSize:   pageSize,
+		PageSize:   params.PageSize,
+		NextCursor: nextCursor,
+		HasMore:    nextCursor != "",
 	}, nil
 }
 
-func (s *Service) GetAdminRevenueTransactions(ctx context.Context, page, pageSize int, startDate, endDate time.Time, search string) ([]wallet.GetAdminRevenueTransactionsRow, int64, error) {
+func (s *Service) GetAdminRevenueTransactions(ctx context.Context, params common.TransactionListParams) ([]wallet.GetAdminRevenueTransactionsRow, string, error) {
 
 	revWalletID, err := s.GetSystemWalletByName(ctx, TransportSystemWallet, SystemWalletRevenue)
 	if err != nil {
-		return nil, 0, err
+		return nil, "", err
 	}
 
-	offset := (page - 1) * pageSize
+	cursorCreatedAt, cursorID := params.CursorArgs()
 
 	txns, err := s.q.GetAdminRevenueTransactions(ctx, wallet.GetAdminRevenueTransactionsParams{
-		WalletID:  revWalletID,
-		StartDate: startDate,
-		EndDate:   endDate,
-		Limit:     int32(pageSize),
-		Offset:    int32(offset),
-		Search:    search,
+		WalletID:        revWalletID,
+		StartDate:       params.StartDate,
+		EndDate:         params.EndDate,
+		Search:          params.Search,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		Limit:           params.FetchLimit(),
 	})
 	if err != nil {
-		return nil, 0, commonerrors.Wrap(ErrDatabase, err)
+		return nil, "", commonerrors.Wrap(ErrDatabase, err)
 	}
 
-	var totalCount int64 = 0
-	if len(txns) > 0 {
-		totalCount = txns[0].TotalCount
-	} else {
+	if txns == nil {
 		txns = []wallet.GetAdminRevenueTransactionsRow{}
 	}
 
-	return txns, totalCount, nil
+	txns, nextCursor := common.TrimPage(txns, params.PageSize, func(t wallet.GetAdminRevenueTransactionsRow) (time.Time, uuid.UUID) {
+		return t.CreatedAt, t.ID
+	})
+
+	return txns, nextCursor, nil
 }
 
 func (s *Service) GetWeeklyStats(ctx context.Context, startDate, endDate time.Time) (*wallet.GetWeeklyWalletStatsRow, error) {
@@ -366,7 +273,7 @@ func (s *Service) GetSystemWalletByName(ctx context.Context, walletName SystemWa
 	return sysWallet.ID, nil
 }
 
-func (s *Service) GetSystemWalletBalance(ctx context.Context, walletName SystemWalletName, walletType SystemWalletType) (float64, error) {
+func (s *Service) GetSystemWalletBalance(ctx context.Context, walletName SystemWalletName, walletType SystemWalletType) (common.Money, error) {
 	walletID, err := s.GetSystemWalletByName(ctx, walletName, walletType)
 	if err != nil {
 		return 0, err
@@ -377,12 +284,33 @@ func (s *Service) GetSystemWalletBalance(ctx context.Context, walletName SystemW
 		return 0, err
 	}
 
-	return float64(balance) / 100.0, nil
+	return common.Money(balance), nil
+}
+
+// CreateMerchantWallet opens the wallet a new merchant is paid into. It runs in
+// the caller's transaction so the wallet only exists if the merchant does.
+func (s *Service) CreateMerchantWallet(ctx context.Context, tx pgx.Tx, merchantName string) (uuid.UUID, error) {
+	w, err := s.q.WithTx(tx).CreateMerchantWallet(ctx, common.StringToText(merchantName))
+	if err != nil {
+		return uuid.Nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	return w.ID, nil
+}
+
+// GetWalletBalance returns the current balance of any wallet
+func (s *Service) GetWalletBalance(ctx context.Context, walletID uuid.UUID) (common.Money, error) {
+	balance, err := s.getWalletBalance(ctx, s.q, walletID)
+	if err != nil {
+		return 0, err
+	}
+
+	return common.Money(balance), nil
 }
 
 func (s *Service) getWalletBalance(ctx context.Context, walletQ *wallet.Queries, walletID uuid.UUID) (int64, error) {
 
-	balance, err := walletQ.GetWalletBalanceSnapshot(ctx, walletID)
+	balance, err := walletQ.GetMaterializedBalance(ctx, walletID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return 0, nil
@@ -25,8 +25,8 @@ SELECT * FROM giki_wallet.transactions
 WHERE type = $1 AND reference_id = $2;
 
 -- name: CreateTransactionHeader :one
-INSERT INTO giki_wallet.transactions(type, reference_id, description)
-VALUES ($1, $2, $3)
+INSERT INTO giki_wallet.transactions(type, reference_id, description, reverses_transaction_id)
+VALUES ($1, $2, $3, sqlc.narg('reverses_transaction_id'))
 RETURNING id, created_at;
 
 
@@ -37,12 +37,18 @@ INSERT INTO giki_wallet.ledger (wallet_id, amount, transaction_id, balance_after
 VALUES ($1, $2, $3, $4, $5)
 RETURNING *;
 
--- name: GetWalletBalanceSnapshot :one
-SELECT balance_after
-FROM giki_wallet.ledger
-WHERE wallet_id = $1
-ORDER BY created_at DESC, id DESC
-LIMIT 1;
+-- name: GetMaterializedBalance :one
+SELECT balance FROM giki_wallet.wallet_balances
+WHERE wallet_id = $1;
+
+-- name: ApplyWalletBalanceDelta :one
+INSERT INTO giki_wallet.wallet_balances (wallet_id, balance, last_entry_id)
+VALUES ($1, $2, $3)
+ON CONFLICT (wallet_id) DO UPDATE
+SET balance = wallet_balances.balance + EXCLUDED.balance,
+    last_entry_id = EXCLUDED.last_entry_id,
+    updated_at = NOW()
+RETURNING balance;
 
 -- name: GetLastLedgerHash :one
 SELECT row_hash
@@ -69,19 +75,28 @@ LIMIT 1;
 INSERT INTO giki_wallet.wallets (user_id, name, type, status)
 VALUES ($1, $2, $3, $4)
 ON CONFLICT (name, type) 
-WHERE type IN ('SYS_REVENUE', 'SYS_LIABILITY')
+WHERE type IN ('SYS_REVENUE', 'SYS_LIABILITY', 'SYS_PROMO')
 DO UPDATE SET status = wallets.status
 RETURNING *;
 
+-- name: CreateMerchantWallet :one
+INSERT INTO giki_wallet.wallets (user_id, name, type, status)
+VALUES (NULL, $1, 'MERCHANT', 'ACTIVE')
+RETURNING *;
+
 -- name: GetLedgerEntriesByWallet :many
 SELECT
-    l.id, l.amount, l.balance_after, l.created_at, COUNT(*) OVER() as total_count,
+    l.id, l.amount, l.balance_after, l.created_at,
     t.type, t.reference_id, t.description
 FROM giki_wallet.ledger l
          JOIN giki_wallet.transactions t ON l.transaction_id = t.id
 WHERE l.wallet_id = $1
+  AND (
+      sqlc.narg('cursor_created_at')::timestamptz IS NULL OR
+      (l.created_at, l.id) < (sqlc.narg('cursor_created_at')::timestamptz, sqlc.narg('cursor_id')::uuid)
+  )
 ORDER BY l.created_at DESC, l.id DESC
-LIMIT $2 OFFSET $3;
+LIMIT sqlc.arg('limit');
 
 -- name: GetAdminRevenueTransactions :many
 SELECT
@@ -94,11 +109,22 @@ SELECT
     t.reference_id,
 
     u.name as user_name,
-    u.email as user_email,
-    COUNT(*) OVER() as total_count
+    u.email as user_email
 FROM giki_wallet.ledger l
 JOIN giki_wallet.transactions t ON l.transaction_id = t.id
-LEFT JOIN giki_wallet.ledger other_side ON t.id = other_side.transaction_id AND other_side.id != l.id
+-- one counterparty per row, so an N-leg entry is not listed once per leg and
+-- keyset paging stays stable: a user's leg first, then the largest amount.
+-- All legs are written in one database transaction, so bounding the other
+-- leg's created_at lets the lookup skip every other month's partition
+LEFT JOIN LATERAL (
+    SELECT os.wallet_id
+    FROM giki_wallet.ledger os
+    JOIN giki_wallet.wallets ow ON os.wallet_id = ow.id
+    WHERE os.transaction_id = t.id AND os.id != l.id
+      AND os.created_at BETWEEN l.created_at - INTERVAL '1 hour' AND l.created_at + INTERVAL '1 hour'
+    ORDER BY ow.user_id IS NULL, ABS(os.amount) DESC, os.id
+    LIMIT 1
+) other_side ON TRUE
 LEFT JOIN giki_wallet.wallets w ON other_side.wallet_id = w.id
 LEFT JOIN giki_wallet.users u ON w.user_id = u.id
 WHERE l.wallet_id = $1
@@ -110,8 +136,12 @@ WHERE l.wallet_id = $1
       u.email ILIKE '%' || sqlc.arg('search')::text || '%' OR
       t.reference_id ILIKE '%' || sqlc.arg('search')::text || '%'
   )
-ORDER BY l.created_at DESC
-LIMIT $2 OFFSET $3;
+  AND (
+      sqlc.narg('cursor_created_at')::timestamptz IS NULL OR
+      (l.created_at, l.id) < (sqlc.narg('cursor_created_at')::timestamptz, sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY l.created_at DESC, l.id DESC
+LIMIT sqlc.arg('limit');
 
 -- name: GetWeeklyWalletStats :one
 SELECT
@@ -121,4 +151,525 @@ SELECT
 FROM giki_wallet.ledger
 WHERE wallet_id = $1
   AND created_at >= sqlc.arg('start_date')
-  AND created_at <= sqlc.arg('end_date');
\ No newline at end of file
+  AND created_at <= sqlc.arg('end_date');
+
+-- name: ListWalletIDs :many
+SELECT id FROM giki_wallet.wallets
+WHERE sqlc.narg('after_id')::uuid IS NULL OR id > sqlc.narg('after_id')::uuid
+ORDER BY id
+LIMIT sqlc.arg('limit');
+
+-- name: GetLedgerEntriesForVerification :many
+SELECT
+    l.id, l.wallet_id, l.amount, l.balance_after, l.row_hash, l.created_at,
+    l.transaction_id, t.created_at AS transaction_created_at
+FROM giki_wallet.ledger l
+         JOIN giki_wallet.transactions t ON l.transaction_id = t.id
+WHERE l.wallet_id = sqlc.arg('wallet_id')
+  AND (
+      sqlc.narg('cursor_created_at')::timestamptz IS NULL OR
+      (l.created_at, l.id) > (sqlc.narg('cursor_created_at')::timestamptz, sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY l.created_at ASC, l.id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: GetTransactionLegTotals :many
+-- One keyset page of transaction headers with their leg count and sum. Legs
+-- are written after their header, so the ledger is only read from the page's
+-- first header onwards and older partitions are pruned.
+WITH page AS (
+    SELECT t.id, t.type, t.reference_id, t.created_at
+    FROM giki_wallet.transactions t
+    WHERE sqlc.narg('cursor_created_at')::timestamptz IS NULL OR
+          (t.created_at, t.id) > (sqlc.narg('cursor_created_at')::timestamptz, sqlc.narg('cursor_id')::uuid)
+    ORDER BY t.created_at ASC, t.id ASC
+    LIMIT sqlc.arg('limit')
+)
+SELECT
+    p.id, p.type, p.reference_id, p.created_at,
+    COUNT(l.id) AS leg_count,
+    COALESCE(SUM(l.amount), 0)::BIGINT AS leg_sum
+FROM page p
+         LEFT JOIN giki_wallet.ledger l
+                   ON l.transaction_id = p.id
+                       AND l.created_at >= (SELECT MIN(created_at) FROM page)
+GROUP BY p.id, p.type, p.reference_id, p.created_at
+ORDER BY p.created_at ASC, p.id ASC;
+
+-- name: CreateLedgerIntegrityRun :one
+INSERT INTO giki_wallet.ledger_integrity_runs (requested_by)
+VALUES ($1)
+RETURNING *;
+
+-- name: GetQueuedLedgerIntegrityRun :one
+SELECT * FROM giki_wallet.ledger_integrity_runs
+WHERE status = 'QUEUED'
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: GetLedgerIntegrityRun :one
+SELECT * FROM giki_wallet.ledger_integrity_runs
+WHERE id = $1;
+
+-- name: GetLatestCompletedLedgerIntegrityRun :one
+SELECT * FROM giki_wallet.ledger_integrity_runs
+WHERE status = 'COMPLETED'
+ORDER BY completed_at DESC
+LIMIT 1;
+
+-- name: StartLedgerIntegrityRun :execrows
+UPDATE giki_wallet.ledger_integrity_runs
+SET status = 'RUNNING',
+    started_at = NOW(),
+    last_error = NULL
+WHERE id = $1 AND status <> 'COMPLETED';
+
+-- name: CompleteLedgerIntegrityRun :exec
+UPDATE giki_wallet.ledger_integrity_runs
+SET status = 'COMPLETED',
+    wallets_checked = sqlc.arg('wallets_checked'),
+    entries_checked = sqlc.arg('entries_checked'),
+    transactions_checked = sqlc.arg('transactions_checked'),
+    findings_count = sqlc.arg('findings_count'),
+    findings = sqlc.arg('findings'),
+    healthy = sqlc.arg('healthy')::boolean,
+    completed_at = NOW()
+WHERE id = sqlc.arg('id');
+
+-- name: FailLedgerIntegrityRun :exec
+UPDATE giki_wallet.ledger_integrity_runs
+SET status = 'FAILED',
+    last_error = $2
+WHERE id = $1;
+
+-- name: GetWalletByID :one
+SELECT * FROM giki_wallet.wallets
+WHERE id = $1;
+
+-- name: GetLedgerEntriesByTransaction :many
+SELECT * FROM giki_wallet.ledger
+WHERE transaction_id = $1
+ORDER BY amount ASC, id ASC;
+
+-- name: GetTransferRecipient :one
+SELECT u.id, u.name, u.email, u.is_active, u.is_verified, u.user_type
+FROM giki_wallet.users u
+         LEFT JOIN giki_wallet.student_profiles sp ON sp.user_id = u.id
+WHERE LOWER(u.email) = LOWER(sqlc.arg('identifier')::text)
+   OR sp.reg_id = sqlc.arg('identifier')::text
+LIMIT 1;
+
+-- name: GetUserContact :one
+SELECT id, name, email FROM giki_wallet.users
+WHERE id = $1;
+
+-- name: GetWalletOwnerContact :one
+SELECT u.id, u.name, u.email
+FROM giki_wallet.wallets w
+         JOIN giki_wallet.users u ON w.user_id = u.id
+WHERE w.id = $1;
+
+-- name: GetDailyTransferStats :one
+SELECT
+    COALESCE(SUM(-l.amount), 0)::BIGINT AS total_sent,
+    COUNT(*) AS transfer_count
+FROM giki_wallet.ledger l
+         JOIN giki_wallet.transactions t ON l.transaction_id = t.id
+WHERE l.wallet_id = $1
+  AND t.type = 'P2P_TRANSFER'
+  AND l.amount < 0
+  AND l.created_at >= sqlc.arg('since');
+
+-- name: UpdateWalletStatus :one
+UPDATE giki_wallet.wallets
+SET status = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateBalanceCheckpoints :execrows
+INSERT INTO giki_wallet.wallet_balance_checkpoints (run_id, wallet_id, materialized_balance, ledger_balance)
+SELECT
+    sqlc.arg('run_id')::uuid,
+    w.id,
+    COALESCE(wb.balance, 0),
+    COALESCE(SUM(l.amount), 0)::BIGINT
+FROM giki_wallet.wallets w
+         LEFT JOIN giki_wallet.wallet_balances wb ON wb.wallet_id = w.id
+         LEFT JOIN giki_wallet.ledger l ON l.wallet_id = w.id
+GROUP BY w.id, wb.balance;
+
+-- name: GetCheckpointDrift :many
+SELECT * FROM giki_wallet.wallet_balance_checkpoints
+WHERE run_id = $1 AND drift <> 0
+ORDER BY ABS(drift) DESC;
+
+-- name: PruneBalanceCheckpoints :exec
+DELETE FROM giki_wallet.wallet_balance_checkpoints
+WHERE created_at < NOW() - INTERVAL '30 days';
+
+-- name: GetTransactionHeaderForUpdate :one
+SELECT * FROM giki_wallet.transactions
+WHERE id = $1
+    FOR UPDATE;
+
+-- name: GetReversalOfTransaction :one
+SELECT * FROM giki_wallet.transactions
+WHERE reverses_transaction_id = $1;
+
+-- name: CreateLedgerAdjustment :one
+INSERT INTO giki_wallet.ledger_adjustments (user_id, amount, reason, requested_by)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetLedgerAdjustmentForUpdate :one
+SELECT * FROM giki_wallet.ledger_adjustments
+WHERE id = $1
+    FOR UPDATE;
+
+-- name: ApproveLedgerAdjustment :one
+UPDATE giki_wallet.ledger_adjustments
+SET status = 'APPROVED',
+    reviewed_by = $2,
+    review_note = $3,
+    transaction_id = $4,
+    reviewed_at = NOW()
+WHERE id = $1 AND status = 'PENDING'
+RETURNING *;
+
+-- name: RejectLedgerAdjustment :one
+UPDATE giki_wallet.ledger_adjustments
+SET status = 'REJECTED',
+    reviewed_by = $2,
+    review_note = $3,
+    reviewed_at = NOW()
+WHERE id = $1 AND status = 'PENDING'
+RETURNING *;
+
+-- name: ListLedgerAdjustments :many
+SELECT
+    a.id, a.user_id, a.amount, a.reason, a.status,
+    a.requested_by, a.reviewed_by, a.review_note, a.transaction_id,
+    a.created_at, a.reviewed_at,
+    u.name AS user_name,
+    u.email AS user_email,
+    req.name AS requested_by_name,
+    rev.name AS reviewed_by_name,
+    COUNT(*) OVER() AS total_count
+FROM giki_wallet.ledger_adjustments a
+         JOIN giki_wallet.users u ON a.user_id = u.id
+         JOIN giki_wallet.users req ON a.requested_by = req.id
+         LEFT JOIN giki_wallet.users rev ON a.reviewed_by = rev.id
+WHERE (sqlc.arg('status')::text = '' OR a.status = sqlc.arg('status')::text)
+ORDER BY a.created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: GetWalletBalanceAt :one
+SELECT COALESCE(SUM(amount), 0)::BIGINT AS balance
+FROM giki_wallet.ledger
+WHERE wallet_id = $1
+  AND created_at < sqlc.arg('before');
+
+-- name: GetStatementEntries :many
+SELECT
+    l.id, l.amount, l.balance_after, l.created_at,
+    t.type, t.reference_id, t.description
+FROM giki_wallet.ledger l
+         JOIN giki_wallet.transactions t ON l.transaction_id = t.id
+WHERE l.wallet_id = $1
+  AND l.created_at >= sqlc.arg('start_date')
+  AND l.created_at < sqlc.arg('end_date')
+ORDER BY l.created_at ASC, l.id ASC;
+
+-- name: SetStatementOptIn :one
+UPDATE giki_wallet.wallets
+SET statement_opt_in = $2
+WHERE id = $1
+RETURNING statement_opt_in;
+
+-- name: ListStatementSubscribers :many
+SELECT u.id AS user_id, u.name, u.email
+FROM giki_wallet.wallets w
+         JOIN giki_wallet.users u ON w.user_id = u.id
+WHERE w.type = 'PERSONAL'
+  AND w.statement_opt_in
+  AND w.status <> 'CLOSED'
+  AND u.is_active
+ORDER BY u.id;
+
+-- name: GetTrialBalance :many
+-- Ledger totals per wallet type (and per name for system wallets), optionally
+-- as of a point in time, next to the current materialized balances.
+WITH ledger_totals AS (
+    SELECT
+        wallet_id,
+        COALESCE(SUM(amount) FILTER (WHERE amount > 0), 0) AS credits,
+        COALESCE(-SUM(amount) FILTER (WHERE amount < 0), 0) AS debits,
+        SUM(amount) AS balance
+    FROM giki_wallet.ledger
+    WHERE sqlc.narg('as_of')::timestamptz IS NULL OR created_at <= sqlc.narg('as_of')::timestamptz
+    GROUP BY wallet_id
+)
+SELECT
+    COALESCE(w.type, 'PERSONAL')::text AS wallet_type,
+    (CASE WHEN w.type IN ('SYS_REVENUE', 'SYS_LIABILITY', 'SYS_PROMO') THEN COALESCE(w.name, '') ELSE '' END)::text AS wallet_name,
+    COUNT(*) AS wallet_count,
+    COALESCE(SUM(lt.credits), 0)::BIGINT AS total_credits,
+    COALESCE(SUM(lt.debits), 0)::BIGINT AS total_debits,
+    COALESCE(SUM(lt.balance), 0)::BIGINT AS ledger_balance,
+    COALESCE(SUM(wb.balance), 0)::BIGINT AS materialized_balance,
+    COUNT(*) FILTER (WHERE COALESCE(lt.balance, 0) < 0) AS negative_wallets
+FROM giki_wallet.wallets w
+         LEFT JOIN ledger_totals lt ON lt.wallet_id = w.id
+         LEFT JOIN giki_wallet.wallet_balances wb ON wb.wallet_id = w.id
+GROUP BY 1, 2
+ORDER BY 1, 2;
+
+-- name: ListFinanceAdminContacts :many
+SELECT name, email FROM giki_wallet.users
+WHERE user_type = 'FINANCE_ADMIN'
+  AND is_active
+ORDER BY email;
+
+-- name: CreatePayoutRequest :one
+INSERT INTO giki_wallet.payout_requests (
+    user_id, wallet_id, mobile_number, account_title, user_note, requested_balance
+)
+VALUES (
+    sqlc.arg('user_id'), sqlc.arg('wallet_id'), sqlc.arg('mobile_number'), sqlc.arg('account_title'),
+    sqlc.narg('user_note'), sqlc.arg('requested_balance')
+)
+RETURNING *;
+
+-- name: GetLatestPayoutRequest :one
+SELECT * FROM giki_wallet.payout_requests
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: GetPayoutRequestForUpdate :one
+SELECT * FROM giki_wallet.payout_requests
+WHERE id = $1
+    FOR UPDATE;
+
+-- name: ApprovePayoutRequest :one
+UPDATE giki_wallet.payout_requests
+SET status = 'APPROVED',
+    amount = sqlc.arg('amount'),
+    reviewed_by = sqlc.arg('reviewed_by'),
+    review_note = sqlc.narg('review_note'),
+    transaction_id = sqlc.narg('transaction_id'),
+    reviewed_at = NOW()
+WHERE id = sqlc.arg('id') AND status = 'PENDING'
+RETURNING *;
+
+-- name: RejectPayoutRequest :one
+UPDATE giki_wallet.payout_requests
+SET status = 'REJECTED',
+    reviewed_by = $2,
+    review_note = $3,
+    reviewed_at = NOW()
+WHERE id = $1 AND status = 'PENDING'
+RETURNING *;
+
+-- name: CancelPayoutRequest :one
+UPDATE giki_wallet.payout_requests
+SET status = 'CANCELLED'
+WHERE user_id = $1 AND status = 'PENDING'
+RETURNING *;
+
+-- name: ListPayoutRequests :many
+SELECT
+    p.id, p.user_id, p.wallet_id, p.mobile_number, p.account_title, p.user_note,
+    p.requested_balance, p.amount, p.status, p.reviewed_by, p.review_note,
+    p.transaction_id, p.batch_id, p.created_at, p.reviewed_at, p.paid_at,
+    u.name AS user_name,
+    u.email AS user_email,
+    rev.name AS reviewed_by_name,
+    COUNT(*) OVER() AS total_count
+FROM giki_wallet.payout_requests p
+         JOIN giki_wallet.users u ON p.user_id = u.id
+         LEFT JOIN giki_wallet.users rev ON p.reviewed_by = rev.id
+WHERE (sqlc.arg('status')::text = '' OR p.status = sqlc.arg('status')::text)
+ORDER BY p.created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CreatePayoutBatch :one
+INSERT INTO giki_wallet.payout_batches (created_by)
+VALUES ($1)
+RETURNING *;
+
+-- name: AssignApprovedPayoutsToBatch :many
+-- Claims every approved payout not yet exported for the given batch
+UPDATE giki_wallet.payout_requests p
+SET batch_id = sqlc.arg('batch_id')
+FROM giki_wallet.users u
+WHERE u.id = p.user_id
+  AND p.status = 'APPROVED'
+  AND p.batch_id IS NULL
+RETURNING p.id, p.user_id, p.mobile_number, p.account_title, p.amount, u.name AS user_name, u.email AS user_email;
+
+-- name: SetPayoutBatchTotals :one
+UPDATE giki_wallet.payout_batches
+SET payout_count = $2,
+    total_amount = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: GetPayoutBatch :one
+SELECT * FROM giki_wallet.payout_batches
+WHERE id = $1;
+
+-- name: ListPayoutBatchItems :many
+SELECT
+    p.id, p.user_id, p.mobile_number, p.account_title, p.amount,
+    u.name AS user_name,
+    u.email AS user_email
+FROM giki_wallet.payout_requests p
+         JOIN giki_wallet.users u ON p.user_id = u.id
+WHERE p.batch_id = $1
+ORDER BY p.reviewed_at;
+
+-- name: ListPayoutBatches :many
+SELECT *, COUNT(*) OVER() AS total_count
+FROM giki_wallet.payout_batches
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: CompletePayoutBatch :one
+UPDATE giki_wallet.payout_batches
+SET completed_by = $2,
+    completed_at = NOW()
+WHERE id = $1 AND completed_at IS NULL
+RETURNING *;
+
+-- name: MarkBatchPayoutsPaid :execrows
+UPDATE giki_wallet.payout_requests
+SET status = 'PAID',
+    paid_at = NOW()
+WHERE batch_id = $1 AND status = 'APPROVED';
+
+-- name: GetReservedAmount :one
+SELECT COALESCE(SUM(amount), 0)::BIGINT
+FROM giki_wallet.wallet_reservations
+WHERE wallet_id = $1 AND status = 'ACTIVE' AND expires_at > NOW();
+
+-- name: CreateWalletReservation :one
+INSERT INTO giki_wallet.wallet_reservations (wallet_id, amount, reference_id, description, expires_at)
+VALUES (
+    sqlc.arg('wallet_id'), sqlc.arg('amount'), sqlc.arg('reference_id'), sqlc.arg('description'),
+    -- no expiry means the hold lasts until it is captured or released
+    COALESCE(sqlc.narg('expires_at')::TIMESTAMPTZ, 'infinity')
+)
+RETURNING *;
+
+-- name: CaptureWalletReservation :one
+UPDATE giki_wallet.wallet_reservations
+SET status = 'CAPTURED',
+    resolved_at = NOW()
+WHERE reference_id = $1 AND status = 'ACTIVE'
+RETURNING *;
+
+-- name: ResolveWalletReservations :execrows
+UPDATE giki_wallet.wallet_reservations
+SET status = sqlc.arg('status'),
+    resolved_at = NOW()
+WHERE reference_id = ANY(sqlc.arg('reference_ids')::TEXT[]) AND status = 'ACTIVE';
+
+-- name: GetWalletSpendPolicy :one
+SELECT
+    u.id AS user_id,
+    u.user_type,
+    o.per_transaction_limit,
+    o.daily_limit,
+    o.weekly_limit
+FROM giki_wallet.wallets w
+         JOIN giki_wallet.users u ON w.user_id = u.id
+         LEFT JOIN giki_wallet.spend_limit_overrides o ON o.user_id = u.id
+WHERE w.id = $1 AND w.type = 'PERSONAL';
+
+-- name: GetWalletSpendSince :one
+SELECT COALESCE(SUM(-l.amount), 0)::BIGINT
+FROM giki_wallet.ledger l
+         JOIN giki_wallet.transactions t ON l.transaction_id = t.id
+WHERE l.wallet_id = $1
+  AND l.amount < 0
+  AND l.created_at >= sqlc.arg('since')
+  AND NOT (t.type = ANY(sqlc.arg('exempt_types')::TEXT[]));
+
+-- name: GetSpendLimitOverride :one
+SELECT * FROM giki_wallet.spend_limit_overrides
+WHERE user_id = $1;
+
+-- name: UpsertSpendLimitOverride :one
+INSERT INTO giki_wallet.spend_limit_overrides (user_id, per_transaction_limit, daily_limit, weekly_limit, reason, set_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (user_id) DO UPDATE
+SET per_transaction_limit = EXCLUDED.per_transaction_limit,
+    daily_limit = EXCLUDED.daily_limit,
+    weekly_limit = EXCLUDED.weekly_limit,
+    reason = EXCLUDED.reason,
+    set_by = EXCLUDED.set_by,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: DeleteSpendLimitOverride :execrows
+DELETE FROM giki_wallet.spend_limit_overrides
+WHERE user_id = $1;
+
+-- name: EnsureLedgerPartition :one
+SELECT giki_wallet.ensure_ledger_partition(sqlc.arg('month')::date)::text AS partition_name;
+
+-- name: DrainLedgerDefaultPartition :one
+SELECT giki_wallet.drain_ledger_default_partition()::INTEGER AS months_drained;
+
+-- name: ListLedgerPartitions :many
+SELECT
+    c.relname::text AS partition_name,
+    GREATEST(c.reltuples, 0)::BIGINT AS estimated_rows,
+    pg_total_relation_size(c.oid)::BIGINT AS size_bytes
+FROM pg_catalog.pg_inherits i
+         JOIN pg_catalog.pg_class c ON c.oid = i.inhrelid
+WHERE i.inhparent = 'giki_wallet.ledger'::regclass
+ORDER BY c.relname;
+
+-- name: GetLedgerArchiveEntries :many
+SELECT
+    l.id, l.wallet_id, l.amount, l.balance_after, l.row_hash, l.created_at,
+    l.transaction_id, t.type, t.reference_id, t.description,
+    t.created_at AS transaction_created_at
+FROM giki_wallet.ledger l
+         JOIN giki_wallet.transactions t ON l.transaction_id = t.id
+WHERE l.created_at >= sqlc.arg('start_date')
+  AND l.created_at < sqlc.arg('end_date')
+  AND (
+      sqlc.narg('cursor_created_at')::timestamptz IS NULL OR
+      (l.created_at, l.id) > (sqlc.narg('cursor_created_at')::timestamptz, sqlc.narg('cursor_id')::uuid)
+  )
+ORDER BY l.created_at ASC, l.id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: CreateLedgerArchive :one
+INSERT INTO giki_wallet.ledger_archives (
+    partition_name, period_start, period_end, file_name,
+    row_count, amount_sum, content_sha256, file_sha256, archived_by
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: GetLedgerArchive :one
+SELECT * FROM giki_wallet.ledger_archives
+WHERE id = $1;
+
+-- name: GetLedgerArchiveByPartition :one
+SELECT * FROM giki_wallet.ledger_archives
+WHERE partition_name = $1;
+
+-- name: ListLedgerArchives :many
+SELECT * FROM giki_wallet.ledger_archives
+ORDER BY period_start DESC;
+
+-- name: MarkLedgerArchiveVerified :one
+UPDATE giki_wallet.ledger_archives
+SET verified_at = NOW()
+WHERE id = $1
+RETURNING *;
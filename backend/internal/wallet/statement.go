@@ -0,0 +1,261 @@
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hash-walker/giki-wallet/internal/common"
+	commonerrors "github.com/hash-walker/giki-wallet/internal/common/errors"
+	wallet "github.com/hash-walker/giki-wallet/internal/wallet/wallet_db"
+	"github.com/hash-walker/giki-wallet/internal/worker"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	JobScheduleMonthlyStatements = "SCHEDULE_MONTHLY_STATEMENTS"
+	JobSendMonthlyStatement      = "SEND_MONTHLY_STATEMENT"
+
+	// maxStatementRange keeps a single statement to roughly a year of ledger lines
+	maxStatementRange = 366 * 24 * time.Hour
+)
+
+// GetStatement builds the statement of a user's personal wallet for [start, end).
+// The opening balance is the sum of every ledger line before start, and each
+// line's balance is carried forward from it so the statement always adds up.
+func (s *Service) GetStatement(ctx context.Context, userID uuid.UUID, start, end time.Time) (*Statement, error) {
+	if !end.After(start) {
+		return nil, ErrInvalidStatementRange.WithDetails("end_date", "must be after start_date")
+	}
+
+	if end.Sub(start) > maxStatementRange {
+		return nil, ErrInvalidStatementRange.WithDetails("range", "cannot exceed one year")
+	}
+
+	contact, err := s.q.GetUserContact(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrWalletNotFound
+		}
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	w, err := s.GetOrCreateWallet(ctx, nil, userID)
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	opening, err := s.q.GetWalletBalanceAt(ctx, wallet.GetWalletBalanceAtParams{
+		WalletID: w.ID,
+		Before:   start,
+	})
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	entries, err := s.q.GetStatementEntries(ctx, wallet.GetStatementEntriesParams{
+		WalletID:  w.ID,
+		StartDate: start,
+		EndDate:   end,
+	})
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	running := opening
+	var credits, debits int64
+	lines := make([]StatementLine, 0, len(entries))
+	for _, e := range entries {
+		running += e.Amount
+		if e.Amount > 0 {
+			credits += e.Amount
+		} else {
+			debits -= e.Amount
+		}
+
+		lines = append(lines, StatementLine{
+			ID:          e.ID,
+			Date:        e.CreatedAt.In(s.loc),
+			Type:        e.Type,
+			ReferenceID: e.ReferenceID,
+			Description: common.TextToString(e.Description),
+			Amount:      common.Money(e.Amount),
+			Balance:     common.Money(running),
+		})
+	}
+
+	return &Statement{
+		WalletID:       w.ID,
+		AccountHolder:  contact.Name,
+		Email:          contact.Email,
+		PeriodStart:    start.In(s.loc),
+		PeriodEnd:      end.In(s.loc),
+		OpeningBalance: common.Money(opening),
+		ClosingBalance: common.Money(running),
+		TotalCredits:   common.Money(credits),
+		TotalDebits:    common.Money(debits),
+		Lines:          lines,
+		GeneratedAt:    time.Now().In(s.loc),
+	}, nil
+}
+
+// RenderStatementCSV writes the statement as CSV. The opening and closing
+// balances are emitted as their own rows so the file reconciles on its own.
+func RenderStatementCSV(st *Statement) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	w := csv.NewWriter(buf)
+
+	rows := [][]string{
+		{"Date", "Type", "Reference", "Description", "Amount", "Balance"},
+		{st.PeriodStart.Format(time.RFC3339), "", "", "Opening Balance", "", st.OpeningBalance.Decimal()},
+	}
+
+	for _, line := range st.Lines {
+		rows = append(rows, []string{
+			line.Date.Format(time.RFC3339),
+			line.Type,
+			line.ReferenceID,
+			line.Description,
+			line.Amount.Decimal(),
+			line.Balance.Decimal(),
+		})
+	}
+
+	rows = append(rows, []string{st.PeriodEnd.Format(time.RFC3339), "", "", "Closing Balance", "", st.ClosingBalance.Decimal()})
+
+	if err := w.WriteAll(rows); err != nil {
+		return nil, commonerrors.Wrap(commonerrors.ErrInternal, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// StatementFileName is the download name for a statement in the given extension
+func StatementFileName(st *Statement, ext string) string {
+	return fmt.Sprintf("statement_%s_%s.%s",
+		st.PeriodStart.Format("2006-01-02"),
+		st.PeriodEnd.Add(-time.Nanosecond).Format("2006-01-02"),
+		ext,
+	)
+}
+
+func (s *Service) GetStatementSubscription(ctx context.Context, userID uuid.UUID) (*StatementSubscription, error) {
+	w, err := s.GetOrCreateWallet(ctx, nil, userID)
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	return &StatementSubscription{Enabled: w.StatementOptIn}, nil
+}
+
+func (s *Service) SetStatementSubscription(ctx context.Context, userID uuid.UUID, enabled bool) (*StatementSubscription, error) {
+	w, err := s.GetOrCreateWallet(ctx, nil, userID)
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	optIn, err := s.q.SetStatementOptIn(ctx, wallet.SetStatementOptInParams{
+		ID:             w.ID,
+		StatementOptIn: enabled,
+	})
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	return &StatementSubscription{Enabled: optIn}, nil
+}
+
+// RegisterStatementJobs hands the statement jobs to the worker and makes sure
+// the monthly fan-out is scheduled for the start of next month.
+func (s *Service) RegisterStatementJobs(ctx context.Context) error {
+	s.worker.RegisterHandler(JobScheduleMonthlyStatements, s.handleScheduleMonthlyStatements)
+	s.worker.RegisterHandler(JobSendMonthlyStatement, s.handleSendMonthlyStatement)
+
+	return s.worker.EnsureScheduled(ctx, JobScheduleMonthlyStatements, struct{}{}, nextMonthStart(time.Now().In(s.loc)))
+}
+
+// handleScheduleMonthlyStatements queues one statement job per opted-in user
+// for the month that just ended, then schedules itself for next month.
+func (s *Service) handleScheduleMonthlyStatements(ctx context.Context, _ json.RawMessage) error {
+	now := time.Now().In(s.loc)
+	periodEnd := monthStart(now)
+	periodStart := periodEnd.AddDate(0, -1, 0)
+
+	// reschedule first so a failure below cannot drop the next run
+	if err := s.worker.EnsureScheduled(ctx, JobScheduleMonthlyStatements, struct{}{}, nextMonthStart(now)); err != nil {
+		return err
+	}
+
+	subscribers, err := s.q.ListStatementSubscribers(ctx)
+	if err != nil {
+		return commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	for _, sub := range subscribers {
+		if err := s.worker.Enqueue(ctx, JobSendMonthlyStatement, MonthlyStatementJob{
+			UserID:      sub.UserID,
+			PeriodStart: periodStart,
+			PeriodEnd:   periodEnd,
+		}); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("[Statements] queued %d statements for %s", len(subscribers), periodStart.Format("January 2006"))
+	return nil
+}
+
+// handleSendMonthlyStatement renders one user's statement as a PDF and queues
+// the email that carries it. Users who opted out after the fan-out are skipped.
+func (s *Service) handleSendMonthlyStatement(ctx context.Context, payload json.RawMessage) error {
+	var job MonthlyStatementJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return err
+	}
+
+	w, err := s.GetOrCreateWallet(ctx, nil, job.UserID)
+	if err != nil {
+		return commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	if !w.StatementOptIn {
+		return nil
+	}
+
+	st, err := s.GetStatement(ctx, job.UserID, job.PeriodStart, job.PeriodEnd)
+	if err != nil {
+		return err
+	}
+
+	document, err := RenderStatementPDF(st)
+	if err != nil {
+		return err
+	}
+
+	return s.worker.Enqueue(ctx, "SEND_STATEMENT_EMAIL", worker.StatementEmailPayload{
+		Email:          st.Email,
+		Name:           st.AccountHolder,
+		Period:         st.PeriodStart.Format("January 2006"),
+		OpeningBalance: st.OpeningBalance.Rupees(),
+		ClosingBalance: st.ClosingBalance.Rupees(),
+		TotalCredits:   st.TotalCredits.Rupees(),
+		TotalDebits:    st.TotalDebits.Rupees(),
+		LineCount:      len(st.Lines),
+		FileName:       StatementFileName(st, "pdf"),
+		Document:       document,
+	})
+}
+
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+func nextMonthStart(t time.Time) time.Time {
+	return monthStart(t).AddDate(0, 1, 0)
+}
@@ -0,0 +1,188 @@
+package wallet
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// A4 portrait in PDF points, and the layout of the statement table
+const (
+	pdfPageWidth    = 595
+	pdfPageHeight   = 842
+	pdfMargin       = 40
+	pdfLineHeight   = 14
+	pdfFontSize     = 8
+	pdfHeaderHeight = 120
+)
+
+// statement table columns: x offset and max characters
+var pdfColumns = []struct {
+	title string
+	x     int
+	width int
+	right bool
+}{
+	{"Date", pdfMargin, 16, false},
+	{"Type", 125, 18, false},
+	{"Reference", 215, 14, false},
+	{"Description", 290, 30, false},
+	{"Amount", 490, 12, true},
+	{"Balance", 555, 12, true},
+}
+
+// RenderStatementPDF lays the statement out as a plain multi-page PDF using the
+// built-in Helvetica font, so no font files or PDF libraries are needed.
+func RenderStatementPDF(st *Statement) ([]byte, error) {
+	rowsPerPage := (pdfPageHeight - 2*pdfMargin - pdfHeaderHeight) / pdfLineHeight
+
+	var pages []string
+	lines := st.Lines
+	for page := 1; page == 1 || len(lines) > 0; page++ {
+		n := min(rowsPerPage, len(lines))
+		pages = append(pages, statementPageContent(st, lines[:n], page, len(lines) == n))
+		lines = lines[n:]
+	}
+
+	return buildPDF(pages), nil
+}
+
+func statementPageContent(st *Statement, lines []StatementLine, page int, last bool) string {
+	var b strings.Builder
+	y := pdfPageHeight - pdfMargin
+
+	text := func(x, y, size int, bold bool, s string) {
+		font := "F1"
+		if bold {
+			font = "F2"
+		}
+		fmt.Fprintf(&b, "BT /%s %d Tf %d %d Td (%s) Tj ET\n", font, size, x, y, pdfEscape(s))
+	}
+
+	// right-aligned text is approximated from Helvetica's average glyph width
+	rightText := func(x, y, size int, bold bool, s string) {
+		text(x-len(s)*size/2, y, size, bold, s)
+	}
+
+	text(pdfMargin, y-16, 16, true, "G-Bux Wallet Statement")
+	rightText(pdfPageWidth-pdfMargin, y-16, 9, false, fmt.Sprintf("Page %d", page))
+
+	text(pdfMargin, y-40, 10, false, fmt.Sprintf("Account holder: %s (%s)", st.AccountHolder, st.Email))
+	text(pdfMargin, y-54, 10, false, fmt.Sprintf("Period: %s to %s",
+		st.PeriodStart.Format("02 Jan 2006"), st.PeriodEnd.Add(-time.Nanosecond).Format("02 Jan 2006")))
+	text(pdfMargin, y-68, 10, false, fmt.Sprintf("Opening balance: %s    Money in: %s    Money out: %s    Closing balance: %s",
+		st.OpeningBalance.Decimal(), st.TotalCredits.Decimal(), st.TotalDebits.Decimal(), st.ClosingBalance.Decimal()))
+	text(pdfMargin, y-82, 8, false, fmt.Sprintf("Generated %s", st.GeneratedAt.Format("02 Jan 2006 15:04 MST")))
+
+	y -= pdfHeaderHeight - pdfLineHeight
+	for _, col := range pdfColumns {
+		if col.right {
+			rightText(col.x, y, pdfFontSize, true, col.title)
+		} else {
+			text(col.x, y, pdfFontSize, true, col.title)
+		}
+	}
+	fmt.Fprintf(&b, "%d %d m %d %d l S\n", pdfMargin, y-4, pdfPageWidth-pdfMargin, y-4)
+
+	for _, line := range lines {
+		y -= pdfLineHeight
+		cells := []string{
+			line.Date.Format("02 Jan 2006 15:04"),
+			line.Type,
+			line.ReferenceID,
+			line.Description,
+			line.Amount.Decimal(),
+			line.Balance.Decimal(),
+		}
+		for i, col := range pdfColumns {
+			cell := truncate(cells[i], col.width)
+			if col.right {
+				rightText(col.x, y, pdfFontSize, false, cell)
+			} else {
+				text(col.x, y, pdfFontSize, false, cell)
+			}
+		}
+	}
+
+	if len(st.Lines) == 0 {
+		text(pdfMargin, y-pdfLineHeight, pdfFontSize, false, "No transactions in this period.")
+	}
+
+	if last {
+		text(pdfMargin, pdfMargin, 7, false, "This statement is generated from the G-Bux ledger. Contact the administration if anything looks wrong.")
+	}
+
+	return b.String()
+}
+
+// buildPDF assembles the catalog, page tree, fonts and one content stream per
+// page, then writes the cross-reference table with each object's byte offset.
+func buildPDF(pages []string) []byte {
+	var objects []string
+
+	// 1: catalog, 2: page tree, 3-4: fonts, then a page and content object per page
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+	)
+
+	for i, content := range pages {
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+				pdfPageWidth, pdfPageHeight, 6+2*i),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content),
+		)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return buf.Bytes()
+}
+
+// pdfEscape escapes a string for a PDF literal. Characters outside printable
+// ASCII are replaced, since the standard fonts cannot be relied on for them.
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 32 || r > 126:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func truncate(s string, limit int) string {
+	r := []rune(s)
+	if len(r) <= limit {
+		return s
+	}
+	return string(r[:limit-1]) + "~"
+}
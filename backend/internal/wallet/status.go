@@ -0,0 +1,191 @@
+package wallet
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/hash-walker/giki-wallet/internal/auth"
+	"github.com/hash-walker/giki-wallet/internal/common"
+	commonerrors "github.com/hash-walker/giki-wallet/internal/common/errors"
+	wallet "github.com/hash-walker/giki-wallet/internal/wallet/wallet_db"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	WalletStatusActive    = "ACTIVE"
+	WalletStatusFrozen    = "FROZEN"
+	WalletStatusSuspended = "SUSPENDED"
+	WalletStatusClosed    = "CLOSED"
+)
+
+// restrictedCreditTypes are the only credits a FROZEN or SUSPENDED wallet accepts:
+// money being returned to the user or finance corrections, never new money coming in.
+var restrictedCreditTypes = map[string]bool{
+	"REFUND":                       true,
+	TransactionTypeReversal:        true,
+	TransactionTypeAdminAdjustment: true,
+}
+
+// correctiveDebitTypes are finance corrections that may still debit a FROZEN or
+// SUSPENDED wallet, e.g. reversing a fraudulent credit on a frozen account, or
+// settling a gateway refund the gateway has already paid out.
+var correctiveDebitTypes = map[string]bool{
+	TransactionTypeReversal:        true,
+	TransactionTypeAdminAdjustment: true,
+	TransactionTypeGatewayRefund:   true,
+}
+
+// checkCanSend decides whether a wallet may be debited for the given transaction type.
+// System wallets are never restricted.
+func checkCanSend(w wallet.GikiWalletWallet, txnType string) error {
+	if isSystemWallet(w) {
+		return nil
+	}
+
+	switch w.Status {
+	case WalletStatusActive:
+		return nil
+	case WalletStatusFrozen:
+		if correctiveDebitTypes[txnType] {
+			return nil
+		}
+		return ErrWalletFrozen
+	case WalletStatusSuspended:
+		if correctiveDebitTypes[txnType] {
+			return nil
+		}
+		return ErrWalletSuspended
+	default:
+		return ErrWalletClosed
+	}
+}
+
+// checkCanReceive decides whether a wallet may be credited for the given transaction type.
+// FROZEN and SUSPENDED wallets only accept refunds and corrections; CLOSED wallets accept nothing.
+func checkCanReceive(w wallet.GikiWalletWallet, txnType string) error {
+	if isSystemWallet(w) {
+		return nil
+	}
+
+	switch w.Status {
+	case WalletStatusActive:
+		return nil
+	case WalletStatusFrozen, WalletStatusSuspended:
+		if restrictedCreditTypes[txnType] {
+			return nil
+		}
+		return ErrRecipientWalletRestricted
+	default:
+		return ErrRecipientWalletRestricted
+	}
+}
+
+func isSystemWallet(w wallet.GikiWalletWallet) bool {
+	walletType := common.TextToString(w.Type)
+	return walletType == string(SystemWalletLiability) ||
+		walletType == string(SystemWalletRevenue) ||
+		walletType == string(SystemWalletPromo)
+}
+
+// CheckCanReceive lets callers reject a credit up front, before money moves
+// outside the ledger (e.g. before sending a user to the payment gateway).
+func (s *Service) CheckCanReceive(ctx context.Context, userID uuid.UUID, txnType string) error {
+	w, err := s.GetOrCreateWallet(ctx, nil, userID)
+	if err != nil {
+		return commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	return checkCanReceive(w, txnType)
+}
+
+// GetUserWalletOverview returns a user's wallet together with its current balance
+func (s *Service) GetUserWalletOverview(ctx context.Context, userID uuid.UUID) (*WalletOverview, error) {
+	w, err := s.GetOrCreateWallet(ctx, nil, userID)
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	balance, err := s.getWalletBalance(ctx, s.q, w.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WalletOverview{
+		Wallet:  *MapDBWalletToWallet(w),
+		Balance: common.Money(balance),
+	}, nil
+}
+
+// UpdateWalletStatus changes the status of a user's personal wallet.
+// Lifting a suspension is reserved for super admins, CLOSED is terminal,
+// and a wallet can only be closed once it holds no money.
+func (s *Service) UpdateWalletStatus(ctx context.Context, userID uuid.UUID, req UpdateWalletStatusRequest, actorRole string) (*WalletStatusChange, error) {
+	newStatus := strings.ToUpper(strings.TrimSpace(req.Status))
+	reason := strings.TrimSpace(req.Reason)
+
+	switch newStatus {
+	case WalletStatusActive, WalletStatusFrozen, WalletStatusSuspended, WalletStatusClosed:
+	default:
+		return nil, ErrInvalidWalletStatus.WithDetails("status", req.Status)
+	}
+
+	if reason == "" {
+		return nil, ErrInvalidWalletStatus.WithDetails("reason", "required")
+	}
+
+	var change *WalletStatusChange
+
+	err := common.WithTransaction(ctx, s.dbPool, func(tx pgx.Tx) error {
+		walletQ := s.q.WithTx(tx)
+
+		userWallet, err := s.GetOrCreateWallet(ctx, tx, userID)
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabase, err)
+		}
+
+		locked, err := walletQ.GetWalletForUpdate(ctx, userWallet.ID)
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabase, err)
+		}
+
+		if locked.Status == WalletStatusClosed {
+			return ErrWalletClosed
+		}
+
+		if locked.Status == WalletStatusSuspended && newStatus != WalletStatusSuspended && actorRole != auth.RoleSuperAdmin {
+			return ErrWalletStatusForbidden
+		}
+
+		if newStatus == WalletStatusClosed {
+			balance, err := s.getWalletBalance(ctx, walletQ, locked.ID)
+			if err != nil {
+				return err
+			}
+			if balance != 0 {
+				return ErrWalletNotEmpty.WithDetails("balance", common.Money(balance))
+			}
+		}
+
+		updated, err := walletQ.UpdateWalletStatus(ctx, wallet.UpdateWalletStatusParams{
+			ID:     locked.ID,
+			Status: newStatus,
+		})
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabase, err)
+		}
+
+		change = &WalletStatusChange{
+			Wallet:         *MapDBWalletToWallet(updated),
+			PreviousStatus: locked.Status,
+			Reason:         reason,
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
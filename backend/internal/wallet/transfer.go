@@ -0,0 +1,233 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hash-walker/giki-wallet/internal/common"
+	commonerrors "github.com/hash-walker/giki-wallet/internal/common/errors"
+	"github.com/hash-walker/giki-wallet/internal/middleware"
+	wallet "github.com/hash-walker/giki-wallet/internal/wallet/wallet_db"
+	"github.com/hash-walker/giki-wallet/internal/worker"
+	"github.com/jackc/pgx/v5"
+)
+
+const maxTransferNoteLength = 140
+
+// TransferFunds moves money from the sender's wallet to another user's wallet.
+// The idempotency key doubles as the transaction reference, so retrying the
+// same request returns the original transfer instead of posting a new one.
+func (s *Service) TransferFunds(ctx context.Context, senderID uuid.UUID, req TransferRequest) (*TransferResult, error) {
+	if req.IdempotencyKey == uuid.Nil {
+		return nil, ErrInvalidTransfer.WithDetails("idempotency_key", "required")
+	}
+
+	amount := int64(common.AmountToLowestUnit(req.Amount))
+	if amount <= 0 {
+		return nil, ErrInvalidTransfer.WithDetails("amount", "must be greater than zero")
+	}
+
+	identifier := strings.TrimSpace(req.Recipient)
+	if identifier == "" {
+		return nil, ErrInvalidTransfer.WithDetails("recipient", "email or registration number is required")
+	}
+
+	note := strings.TrimSpace(req.Note)
+	if len(note) > maxTransferNoteLength {
+		return nil, ErrInvalidTransfer.WithDetails("note", fmt.Sprintf("must be at most %d characters", maxTransferNoteLength))
+	}
+
+	recipient, err := s.q.GetTransferRecipient(ctx, identifier)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRecipientNotFound
+		}
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	if recipient.ID == senderID {
+		return nil, ErrSelfTransfer
+	}
+
+	if !recipient.IsActive || !recipient.IsVerified {
+		return nil, ErrRecipientInactive
+	}
+
+	sender, err := s.q.GetUserContact(ctx, senderID)
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	referenceID := req.IdempotencyKey.String()
+	description := fmt.Sprintf("Transfer from %s to %s", sender.Name, recipient.Name)
+	if note != "" {
+		description = fmt.Sprintf("%s: %s", description, note)
+	}
+
+	var result *TransferResult
+	replayed := false
+
+	err = common.WithTransaction(ctx, s.dbPool, func(tx pgx.Tx) error {
+		// Serialize transfers per sender so the daily limit check cannot be raced
+		if _, lockErr := tx.Exec(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", senderID.String()); lockErr != nil {
+			return commonerrors.Wrap(ErrDatabase, lockErr)
+		}
+
+		walletQ := s.q.WithTx(tx)
+
+		senderWallet, err := s.GetOrCreateWallet(ctx, tx, senderID)
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabase, err)
+		}
+
+		existing, err := walletQ.GetTransactionHeaderByTypeAndRef(ctx, wallet.GetTransactionHeaderByTypeAndRefParams{
+			Type:        TransactionTypeP2PTransfer,
+			ReferenceID: referenceID,
+		})
+		if err == nil {
+			result, err = s.replayTransfer(ctx, walletQ, existing, senderWallet.ID)
+			replayed = true
+			return err
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return commonerrors.Wrap(ErrDatabase, err)
+		}
+
+		recipientWallet, err := s.GetOrCreateWallet(ctx, tx, recipient.ID)
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabase, err)
+		}
+
+		if err := s.checkTransferLimits(ctx, walletQ, senderWallet.ID, amount); err != nil {
+			return err
+		}
+
+		if err := s.ExecuteTransaction(ctx, tx, senderWallet.ID, recipientWallet.ID, amount, TransactionTypeP2PTransfer, referenceID, description); err != nil {
+			if CheckUniqueConstraintViolation(err) {
+				return ErrDuplicateTransfer
+			}
+			return err
+		}
+
+		header, err := walletQ.GetTransactionHeaderByTypeAndRef(ctx, wallet.GetTransactionHeaderByTypeAndRefParams{
+			Type:        TransactionTypeP2PTransfer,
+			ReferenceID: referenceID,
+		})
+		if err != nil {
+			return commonerrors.Wrap(ErrDatabase, err)
+		}
+
+		result = &TransferResult{
+			TransactionID:  header.ID,
+			ReferenceID:    referenceID,
+			Amount:         common.Money(amount),
+			RecipientName:  recipient.Name,
+			RecipientEmail: recipient.Email,
+			Description:    description,
+			CreatedAt:      header.CreatedAt,
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !replayed {
+		s.notifyTransfer(ctx, sender, recipient, amount, note, referenceID)
+	}
+
+	return result, nil
+}
+
+// checkTransferLimits enforces the rolling 24 hour amount and count caps
+func (s *Service) checkTransferLimits(ctx context.Context, walletQ *wallet.Queries, walletID uuid.UUID, amount int64) error {
+	stats, err := walletQ.GetDailyTransferStats(ctx, wallet.GetDailyTransferStatsParams{
+		WalletID: walletID,
+		Since:    time.Now().Add(-24 * time.Hour),
+	})
+	if err != nil {
+		return commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	maxCount := s.configS.GetP2PDailyCount(ctx)
+	if stats.TransferCount >= maxCount {
+		return ErrTransferLimitExceeded.WithDetails("max_transfers", maxCount)
+	}
+
+	maxAmount := s.configS.GetP2PDailyLimit(ctx)
+	if stats.TotalSent+amount > maxAmount {
+		return ErrTransferLimitExceeded.
+			WithDetails("daily_limit", common.Money(maxAmount)).
+			WithDetails("remaining", common.Money(max(maxAmount-stats.TotalSent, 0)))
+	}
+
+	return nil
+}
+
+// replayTransfer rebuilds the result of an already posted transfer, refusing
+// keys that belong to someone else's transfer
+func (s *Service) replayTransfer(ctx context.Context, walletQ *wallet.Queries, header wallet.GikiWalletTransaction, senderWalletID uuid.UUID) (*TransferResult, error) {
+	legs, err := walletQ.GetLedgerEntriesByTransaction(ctx, header.ID)
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	var debit, credit *wallet.GikiWalletLedger
+	for i := range legs {
+		if legs[i].Amount < 0 {
+			debit = &legs[i]
+		} else {
+			credit = &legs[i]
+		}
+	}
+
+	if debit == nil || credit == nil || debit.WalletID != senderWalletID {
+		return nil, ErrDuplicateTransfer
+	}
+
+	recipient, err := walletQ.GetWalletOwnerContact(ctx, credit.WalletID)
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	return &TransferResult{
+		TransactionID:  header.ID,
+		ReferenceID:    header.ReferenceID,
+		Amount:         common.Money(credit.Amount),
+		RecipientName:  recipient.Name,
+		RecipientEmail: recipient.Email,
+		Description:    common.TextToString(header.Description),
+		CreatedAt:      header.CreatedAt,
+	}, nil
+}
+
+// notifyTransfer emails both parties. The transfer is already committed, so
+// enqueue failures are logged rather than returned.
+func (s *Service) notifyTransfer(ctx context.Context, sender wallet.GetUserContactRow, recipient wallet.GetTransferRecipientRow, amount int64, note, referenceID string) {
+	if err := s.worker.Enqueue(ctx, "SEND_TRANSFER_SENT_EMAIL", worker.TransferNotificationPayload{
+		Email:            sender.Email,
+		Name:             sender.Name,
+		CounterpartyName: recipient.Name,
+		Amount:           common.Money(amount).Rupees(),
+		Note:             note,
+		ReferenceID:      referenceID,
+	}); err != nil {
+		middleware.LogAppError(err, "Failed to enqueue transfer sent email")
+	}
+
+	if err := s.worker.Enqueue(ctx, "SEND_TRANSFER_RECEIVED_EMAIL", worker.TransferNotificationPayload{
+		Email:            recipient.Email,
+		Name:             recipient.Name,
+		CounterpartyName: sender.Name,
+		Amount:           common.Money(amount).Rupees(),
+		Note:             note,
+		ReferenceID:      referenceID,
+	}); err != nil {
+		middleware.LogAppError(err, "Failed to enqueue transfer received email")
+	}
+}
@@ -0,0 +1,203 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/hash-walker/giki-wallet/internal/common"
+	commonerrors "github.com/hash-walker/giki-wallet/internal/common/errors"
+	"github.com/hash-walker/giki-wallet/internal/worker"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const JobTrialBalanceReport = "TRIAL_BALANCE_REPORT"
+
+// GetTrialBalance totals the ledger by wallet type at asOf (nil for now) and checks
+// that the GIKI Wallet liability mirrors personal balances plus what has been spent.
+//
+// Every top-up credits a personal wallet from the liability wallet, and every ticket
+// or merchant payment moves money from a personal wallet to transport revenue or a
+// merchant wallet, so liability + personal + revenue + merchant must always be zero.
+// Promo credits are issued from the promo wallet instead of the liability wallet, so
+// its balance counts on the liability side. Any other result means money exists that
+// was never topped up or issued.
+func (s *Service) GetTrialBalance(ctx context.Context, asOf *time.Time) (*TrialBalanceReport, error) {
+	asOfArg := pgtype.Timestamptz{}
+	if asOf != nil {
+		asOfArg = pgtype.Timestamptz{Time: *asOf, Valid: true}
+	}
+
+	rows, err := s.q.GetTrialBalance(ctx, asOfArg)
+	if err != nil {
+		return nil, commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	report := &TrialBalanceReport{
+		AsOf:        asOf,
+		GeneratedAt: time.Now(),
+		Lines:       make([]TrialBalanceLine, 0, len(rows)),
+		Findings:    []IntegrityFinding{},
+	}
+
+	var credits, debits, liability, promo, personal, revenue, merchant int64
+	for _, r := range rows {
+		credits += r.TotalCredits
+		debits += r.TotalDebits
+
+		line := TrialBalanceLine{
+			WalletType:   r.WalletType,
+			WalletName:   r.WalletName,
+			WalletCount:  r.WalletCount,
+			TotalCredits: common.Money(r.TotalCredits),
+			TotalDebits:  common.Money(r.TotalDebits),
+			Balance:      common.Money(r.LedgerBalance),
+		}
+
+		switch {
+		case r.WalletType == string(SystemWalletLiability) && r.WalletName == string(GikiWallet):
+			liability += r.LedgerBalance
+		case r.WalletType == string(SystemWalletPromo) && r.WalletName == string(PromoSystemWallet):
+			promo += r.LedgerBalance
+		case r.WalletType == string(SystemWalletRevenue) && r.WalletName == string(TransportSystemWallet):
+			revenue += r.LedgerBalance
+		case r.WalletType == WalletTypeMerchant:
+			merchant += r.LedgerBalance
+		case r.WalletType == "PERSONAL":
+			personal += r.LedgerBalance
+			if r.NegativeWallets > 0 {
+				report.Findings = append(report.Findings, IntegrityFinding{
+					Kind:     FindingNegativeBalance,
+					Expected: "0",
+					Actual:   strconv.FormatInt(r.NegativeWallets, 10),
+					Detail:   "personal wallets with a negative ledger balance",
+				})
+			}
+		}
+
+		// materialized balances only exist for the present
+		if asOf == nil {
+			line.MaterializedBalance = common.Money(r.MaterializedBalance)
+			if r.MaterializedBalance != r.LedgerBalance {
+				report.Findings = append(report.Findings, IntegrityFinding{
+					Kind:     FindingMaterializedDrift,
+					Expected: strconv.FormatInt(r.LedgerBalance, 10),
+					Actual:   strconv.FormatInt(r.MaterializedBalance, 10),
+					Detail:   fmt.Sprintf("materialized total of %s %s does not match the ledger", r.WalletType, r.WalletName),
+				})
+			}
+		}
+
+		report.Lines = append(report.Lines, line)
+	}
+
+	if credits != debits {
+		report.Findings = append(report.Findings, IntegrityFinding{
+			Kind:     FindingLedgerNotBalanced,
+			Expected: strconv.FormatInt(credits, 10),
+			Actual:   strconv.FormatInt(debits, 10),
+			Detail:   "total debits do not equal total credits",
+		})
+	}
+
+	drift := liability + promo + personal + revenue + merchant
+	if drift != 0 {
+		report.Findings = append(report.Findings, IntegrityFinding{
+			Kind:     FindingLiabilityMismatch,
+			Expected: strconv.FormatInt(-(personal + revenue + merchant), 10),
+			Actual:   strconv.FormatInt(liability+promo, 10),
+			Detail:   "GIKI Wallet liability and promo wallet do not mirror personal, transport revenue and merchant balances",
+		})
+	}
+
+	report.TotalCredits = common.Money(credits)
+	report.TotalDebits = common.Money(debits)
+	report.LiabilityBalance = common.Money(liability)
+	report.PromoBalance = common.Money(promo)
+	report.PersonalBalance = common.Money(personal)
+	report.RevenueBalance = common.Money(revenue)
+	report.MerchantBalance = common.Money(merchant)
+	report.LiabilityDrift = common.Money(drift)
+	report.Healthy = len(report.Findings) == 0
+
+	return report, nil
+}
+
+// RegisterReportJobs hands the daily trial balance to the worker and schedules
+// the first run for the coming midnight.
+func (s *Service) RegisterReportJobs(ctx context.Context) error {
+	s.worker.RegisterHandler(JobTrialBalanceReport, s.handleTrialBalanceReport)
+
+	return s.worker.EnsureScheduled(ctx, JobTrialBalanceReport, struct{}{}, nextMidnight(time.Now()))
+}
+
+// handleTrialBalanceReport computes the trial balance at midnight and emails it
+// to every finance admin, then schedules tomorrow's run.
+func (s *Service) handleTrialBalanceReport(ctx context.Context, _ json.RawMessage) error {
+	now := time.Now()
+	asOf := nextMidnight(now).AddDate(0, 0, -1)
+
+	if err := s.worker.EnsureScheduled(ctx, JobTrialBalanceReport, struct{}{}, nextMidnight(now)); err != nil {
+		return err
+	}
+
+	report, err := s.GetTrialBalance(ctx, &asOf)
+	if err != nil {
+		return err
+	}
+
+	admins, err := s.q.ListFinanceAdminContacts(ctx)
+	if err != nil {
+		return commonerrors.Wrap(ErrDatabase, err)
+	}
+
+	payload := trialBalanceEmail(report)
+	for _, admin := range admins {
+		payload.Email = admin.Email
+		payload.Name = admin.Name
+		if err := s.worker.Enqueue(ctx, "SEND_TRIAL_BALANCE_EMAIL", payload); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("[TrialBalance] as of %s: healthy=%t, liability drift %s, sent to %d admins",
+		asOf.Format(time.RFC3339), report.Healthy, report.LiabilityDrift, len(admins))
+	return nil
+}
+
+func trialBalanceEmail(report *TrialBalanceReport) worker.TrialBalanceEmailPayload {
+	payload := worker.TrialBalanceEmailPayload{
+		AsOf:             report.GeneratedAt.Format("02 Jan 2006 15:04"),
+		Healthy:          report.Healthy,
+		LiabilityBalance: report.LiabilityBalance.Rupees(),
+		PersonalBalance:  report.PersonalBalance.Rupees(),
+		RevenueBalance:   report.RevenueBalance.Rupees(),
+		LiabilityDrift:   report.LiabilityDrift.Rupees(),
+	}
+
+	if report.AsOf != nil {
+		payload.AsOf = report.AsOf.Format("02 Jan 2006 15:04")
+	}
+
+	for _, l := range report.Lines {
+		payload.Lines = append(payload.Lines, worker.TrialBalanceEmailLine{
+			WalletType:  l.WalletType,
+			WalletName:  l.WalletName,
+			WalletCount: l.WalletCount,
+			Balance:     l.Balance.Rupees(),
+		})
+	}
+
+	for _, f := range report.Findings {
+		payload.Findings = append(payload.Findings, fmt.Sprintf("%s: expected %s, got %s (%s)", f.Kind, f.Expected, f.Actual, f.Detail))
+	}
+
+	return payload
+}
+
+func nextMidnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()).AddDate(0, 0, 1)
+}
@@ -59,3 +59,56 @@ type TicketCancelledPayload struct {
 	RefundAmount int    `json:"refund_amount"`
 	Reason       string `json:"reason"`
 }
+
+type TransferNotificationPayload struct {
+	Email            string  `json:"email"`
+	Name             string  `json:"name"`
+	CounterpartyName string  `json:"counterparty_name"`
+	Amount           float64 `json:"amount"`
+	Note             string  `json:"note"`
+	ReferenceID      string  `json:"reference_id"`
+}
+
+type StatementEmailPayload struct {
+	Email          string  `json:"email"`
+	Name           string  `json:"name"`
+	Period         string  `json:"period"`
+	OpeningBalance float64 `json:"opening_balance"`
+	ClosingBalance float64 `json:"closing_balance"`
+	TotalCredits   float64 `json:"total_credits"`
+	TotalDebits    float64 `json:"total_debits"`
+	LineCount      int     `json:"line_count"`
+	FileName       string  `json:"file_name"`
+	Document       []byte  `json:"document"`
+}
+
+type TrialBalanceEmailLine struct {
+	WalletType  string  `json:"wallet_type"`
+	WalletName  string  `json:"wallet_name"`
+	WalletCount int64   `json:"wallet_count"`
+	Balance     float64 `json:"balance"`
+}
+
+type TrialBalanceEmailPayload struct {
+	Email            string                  `json:"email"`
+	Name             string                  `json:"name"`
+	AsOf             string                  `json:"as_of"`
+	Healthy          bool                    `json:"healthy"`
+	LiabilityBalance float64                 `json:"liability_balance"`
+	PersonalBalance  float64                 `json:"personal_balance"`
+	RevenueBalance   float64                 `json:"revenue_balance"`
+	LiabilityDrift   float64                 `json:"liability_drift"`
+	Lines            []TrialBalanceEmailLine `json:"lines"`
+	Findings         []string                `json:"findings"`
+}
+
+type MerchantSettlementEmailPayload struct {
+	Email        string  `json:"email"`
+	MerchantName string  `json:"merchant_name"`
+	Date         string  `json:"date"`
+	PaymentCount int     `json:"payment_count"`
+	GrossAmount  float64 `json:"gross_amount"`
+	NetAmount    float64 `json:"net_amount"`
+	FileName     string  `json:"file_name"`
+	Document     []byte  `json:"document"`
+}
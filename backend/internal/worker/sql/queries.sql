@@ -66,3 +66,7 @@ WHERE status = 'COMPLETED' AND updated_at < NOW() - INTERVAL '7 days';
 -- name: PruneExpiredTokens :exec
 DELETE FROM giki_wallet.access_tokens
 WHERE expires_at < NOW() - INTERVAL '24 hours';
+
+-- name: CountPendingJobsByType :one
+SELECT COUNT(*) FROM giki_wallet.jobs
+WHERE job_type = $1 AND status = 'PENDING';
@@ -14,10 +14,15 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// JobHandler processes a job type owned by another package.
+// Returning an error retries the job like any built-in job.
+type JobHandler func(ctx context.Context, payload json.RawMessage) error
+
 type JobWorker struct {
 	q             *worker.Queries
 	dbPool        *pgxpool.Pool
 	mailer        *mailer.GraphSender
+	handlers      map[string]JobHandler
 	lastHeartbeat time.Time
 }
 func NewWorker(dbPool *pgxpool.Pool, mailer *mailer.GraphSender) *JobWorker {
@@ -25,10 +30,17 @@ func NewWorker(dbPool *pgxpool.Pool, mailer *mailer.GraphSender) *JobWorker {
 		q:             worker.New(dbPool),
 		dbPool:        dbPool,
 		mailer:        mailer,
+		handlers:      make(map[string]JobHandler),
 		lastHeartbeat: time.Now(),
 	}
 }
 
+// RegisterHandler lets other packages own job types without the worker importing them.
+// Handlers must be registered before StartJobTicker is called.
+func (w *JobWorker) RegisterHandler(jobType string, handler JobHandler) {
+	w.handlers[jobType] = handler
+}
+
 func (w *JobWorker) Enqueue(ctx context.Context, jobType string, payload interface{}) error {
 	return w.EnqueueIn(ctx, jobType, payload, 0)
 }
@@ -54,6 +66,21 @@ func (w *JobWorker) EnqueueIn(ctx context.Context, jobType string, payload inter
 	return nil
 }
 
+// EnsureScheduled enqueues a job at runAt unless one of the same type is already pending.
+// Used for recurring jobs that reschedule themselves, so restarts do not stack duplicates.
+func (w *JobWorker) EnsureScheduled(ctx context.Context, jobType string, payload interface{}, runAt time.Time) error {
+	pending, err := w.q.CountPendingJobsByType(ctx, jobType)
+	if err != nil {
+		return fmt.Errorf("failed to check scheduled jobs: %w", err)
+	}
+
+	if pending > 0 {
+		return nil
+	}
+
+	return w.EnqueueIn(ctx, jobType, payload, time.Until(runAt))
+}
+
 func (w *JobWorker) StartJobTicker(ctx context.Context, workerCount int) {
 	for i := range workerCount {
 		go func(workerID int) {
@@ -128,7 +155,21 @@ func (w *JobWorker) processNextJob(ctx context.Context) {
 		processErr = w.handleAccountCreated(job.Payload)
 	case "SEND_PASSWORD_RESET_EMAIL":
 		processErr = w.handlePasswordReset(job.Payload)
+	case "SEND_TRANSFER_SENT_EMAIL":
+		processErr = w.handleTransferSent(job.Payload)
+	case "SEND_TRANSFER_RECEIVED_EMAIL":
+		processErr = w.handleTransferReceived(job.Payload)
+	case "SEND_STATEMENT_EMAIL":
+		processErr = w.handleStatementEmail(job.Payload)
+	case "SEND_TRIAL_BALANCE_EMAIL":
+		processErr = w.handleTrialBalanceEmail(job.Payload)
+	case "SEND_MERCHANT_SETTLEMENT_EMAIL":
+		processErr = w.handleMerchantSettlementEmail(job.Payload)
 	default:
+		if handler, ok := w.handlers[job.JobType]; ok {
+			processErr = handler(ctx, job.Payload)
+			break
+		}
 		log.Printf("Unknown job type: %s", job.JobType)
 		processErr = fmt.Errorf("unknown job type")
 	}
@@ -216,6 +257,64 @@ func (w *JobWorker) handlePasswordReset(payload json.RawMessage) error {
 
 	return w.mailer.SendTemplate(data.Email, "Reset Your GIKI Password", "reset_password.html", data)
 }
+func (w *JobWorker) handleTransferSent(payload json.RawMessage) error {
+	var data TransferNotificationPayload
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return err
+	}
+
+	return w.mailer.SendTemplate(data.Email, "Transfer Sent", "transfer_sent.html", data)
+}
+
+func (w *JobWorker) handleTransferReceived(payload json.RawMessage) error {
+	var data TransferNotificationPayload
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return err
+	}
+
+	return w.mailer.SendTemplate(data.Email, "You Received G-Bux", "transfer_received.html", data)
+}
+
+func (w *JobWorker) handleStatementEmail(payload json.RawMessage) error {
+	var data StatementEmailPayload
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return err
+	}
+
+	attachments := []mailer.Attachment{
+		{Name: data.FileName, ContentType: "application/pdf", Content: data.Document},
+	}
+
+	return w.mailer.SendTemplateWithAttachments(data.Email, "Your G-Bux Statement for "+data.Period, "statement.html", data, attachments)
+}
+
+func (w *JobWorker) handleTrialBalanceEmail(payload json.RawMessage) error {
+	var data TrialBalanceEmailPayload
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return err
+	}
+
+	subject := "Trial Balance " + data.AsOf
+	if !data.Healthy {
+		subject = "[DRIFT DETECTED] " + subject
+	}
+
+	return w.mailer.SendTemplate(data.Email, subject, "trial_balance.html", data)
+}
+
+func (w *JobWorker) handleMerchantSettlementEmail(payload json.RawMessage) error {
+	var data MerchantSettlementEmailPayload
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return err
+	}
+
+	attachments := []mailer.Attachment{
+		{Name: data.FileName, ContentType: "text/csv", Content: data.Document},
+	}
+
+	return w.mailer.SendTemplateWithAttachments(data.Email, "Daily Settlement for "+data.MerchantName+" - "+data.Date, "merchant_settlement.html", data, attachments)
+}
+
 func (w *JobWorker) GetStatus(ctx context.Context) (map[string]interface{}, error) {
 	stats, err := w.q.GetJobStats(ctx)
 	if err != nil {
@@ -0,0 +1,34 @@
+-- +goose Up
+
+-- Materialized balance per wallet, maintained by the ledger engine under the wallet row lock
+CREATE TABLE giki_wallet.wallet_balances (
+    wallet_id uuid PRIMARY KEY REFERENCES giki_wallet.wallets(id) ON DELETE RESTRICT,
+    balance BIGINT NOT NULL DEFAULT 0,
+    last_entry_id uuid,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Seed from the ledger. Summing amounts does not depend on row order.
+INSERT INTO giki_wallet.wallet_balances (wallet_id, balance)
+SELECT w.id, COALESCE(SUM(l.amount), 0)
+FROM giki_wallet.wallets w
+LEFT JOIN giki_wallet.ledger l ON l.wallet_id = w.id
+GROUP BY w.id;
+
+-- Periodic cross-checks of the materialized balance against the ledger
+CREATE TABLE giki_wallet.wallet_balance_checkpoints (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    run_id uuid NOT NULL,
+    wallet_id uuid NOT NULL REFERENCES giki_wallet.wallets(id) ON DELETE CASCADE,
+    materialized_balance BIGINT NOT NULL,
+    ledger_balance BIGINT NOT NULL,
+    drift BIGINT GENERATED ALWAYS AS (materialized_balance - ledger_balance) STORED,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_balance_checkpoints_run ON giki_wallet.wallet_balance_checkpoints(run_id);
+CREATE INDEX IF NOT EXISTS idx_balance_checkpoints_wallet ON giki_wallet.wallet_balance_checkpoints(wallet_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS giki_wallet.wallet_balance_checkpoints;
+DROP TABLE IF EXISTS giki_wallet.wallet_balances;
@@ -0,0 +1,14 @@
+-- +goose Up
+
+-- Links a REVERSAL header to the header it mirrors
+ALTER TABLE giki_wallet.transactions
+    ADD COLUMN reverses_transaction_id uuid REFERENCES giki_wallet.transactions(id) ON DELETE RESTRICT;
+
+-- A transaction can be reversed at most once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_reverses_unique
+    ON giki_wallet.transactions(reverses_transaction_id)
+    WHERE reverses_transaction_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS giki_wallet.idx_transactions_reverses_unique;
+ALTER TABLE giki_wallet.transactions DROP COLUMN IF EXISTS reverses_transaction_id;
@@ -0,0 +1,31 @@
+-- +goose Up
+
+-- Maker-checker requests for manual credits and debits by finance
+CREATE TABLE giki_wallet.ledger_adjustments (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL REFERENCES giki_wallet.users(id),
+
+    -- signed paisa: positive credits the user, negative debits
+    amount BIGINT NOT NULL CHECK (amount <> 0),
+    reason TEXT NOT NULL,
+
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING'
+        CHECK (status IN ('PENDING', 'APPROVED', 'REJECTED')),
+
+    requested_by uuid NOT NULL REFERENCES giki_wallet.users(id),
+    reviewed_by uuid REFERENCES giki_wallet.users(id),
+    review_note TEXT,
+    transaction_id uuid REFERENCES giki_wallet.transactions(id),
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    reviewed_at TIMESTAMPTZ,
+
+    -- the approver must never be the person who proposed the adjustment
+    CONSTRAINT chk_adjustment_four_eyes CHECK (reviewed_by IS NULL OR reviewed_by <> requested_by)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_adjustments_status_created ON giki_wallet.ledger_adjustments(status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_ledger_adjustments_user ON giki_wallet.ledger_adjustments(user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS giki_wallet.ledger_adjustments;
@@ -0,0 +1,12 @@
+-- +goose Up
+
+-- Users who opt in get their previous month's statement emailed on the 1st
+ALTER TABLE giki_wallet.wallets
+    ADD COLUMN IF NOT EXISTS statement_opt_in BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_wallets_statement_opt_in
+    ON giki_wallet.wallets(id) WHERE statement_opt_in;
+
+-- +goose Down
+DROP INDEX IF EXISTS giki_wallet.idx_wallets_statement_opt_in;
+ALTER TABLE giki_wallet.wallets DROP COLUMN IF EXISTS statement_opt_in;
@@ -0,0 +1,18 @@
+-- +goose Up
+
+-- Keyset pagination walks (created_at, id) newest first; these indexes let
+-- every page be an index range scan instead of an OFFSET over the whole list.
+-- The wallet history index gains id as a tie-breaker and replaces the old one.
+CREATE INDEX IF NOT EXISTS idx_ledger_wallet_created_id
+    ON giki_wallet.ledger(wallet_id, created_at DESC, id DESC);
+DROP INDEX IF EXISTS giki_wallet.idx_ledger_wallet_history;
+
+CREATE INDEX IF NOT EXISTS idx_gateway_txn_created_id
+    ON giki_wallet.gateway_transactions(created_at DESC, id DESC);
+
+-- +goose Down
+DROP INDEX IF EXISTS giki_wallet.idx_gateway_txn_created_id;
+
+CREATE INDEX IF NOT EXISTS idx_ledger_wallet_history
+    ON giki_wallet.ledger(wallet_id, created_at DESC);
+DROP INDEX IF EXISTS giki_wallet.idx_ledger_wallet_created_id;
@@ -0,0 +1,57 @@
+-- +goose Up
+
+-- Promo credits are funded from their own system wallet, so widen the
+-- system wallet uniqueness to cover it.
+DROP INDEX IF EXISTS giki_wallet.idx_system_wallet_unique;
+CREATE UNIQUE INDEX idx_system_wallet_unique
+ON giki_wallet.wallets (name, type)
+WHERE type IN ('SYS_REVENUE', 'SYS_LIABILITY', 'SYS_PROMO');
+
+CREATE TABLE giki_wallet.promo_vouchers (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    code VARCHAR(32) NOT NULL UNIQUE CHECK (code = UPPER(code)),
+    description TEXT,
+
+    -- paisa credited per redemption
+    amount BIGINT NOT NULL CHECK (amount > 0),
+
+    -- NULL means unlimited
+    max_redemptions INT CHECK (max_redemptions IS NULL OR max_redemptions > 0),
+    per_user_limit INT NOT NULL DEFAULT 1 CHECK (per_user_limit > 0),
+    redemption_count INT NOT NULL DEFAULT 0,
+
+    -- empty means every user type may redeem
+    target_user_types TEXT[] NOT NULL DEFAULT '{}',
+
+    starts_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+
+    created_by uuid NOT NULL REFERENCES giki_wallet.users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_promo_voucher_window CHECK (expires_at IS NULL OR expires_at > starts_at),
+    CONSTRAINT chk_promo_voucher_limit CHECK (max_redemptions IS NULL OR redemption_count <= max_redemptions)
+);
+
+-- Each redemption's id is the reference_id of its PROMO_CREDIT transaction
+CREATE TABLE giki_wallet.promo_redemptions (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    voucher_id uuid NOT NULL REFERENCES giki_wallet.promo_vouchers(id),
+    user_id uuid NOT NULL REFERENCES giki_wallet.users(id),
+    amount BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_promo_redemptions_voucher_user ON giki_wallet.promo_redemptions(voucher_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_promo_vouchers_created ON giki_wallet.promo_vouchers(created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS giki_wallet.promo_redemptions;
+DROP TABLE IF EXISTS giki_wallet.promo_vouchers;
+
+DROP INDEX IF EXISTS giki_wallet.idx_system_wallet_unique;
+CREATE UNIQUE INDEX idx_system_wallet_unique
+ON giki_wallet.wallets (name, type)
+WHERE type IN ('SYS_REVENUE', 'SYS_LIABILITY');
@@ -0,0 +1,55 @@
+-- +goose Up
+
+-- Campus shops that charge student wallets. Each merchant owns a MERCHANT wallet
+-- that receives its payments; the API secret is only ever stored as a SHA-256 hash.
+CREATE TABLE giki_wallet.merchants (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL UNIQUE,
+    category VARCHAR(50) NOT NULL DEFAULT 'GENERAL',
+    wallet_id uuid NOT NULL UNIQUE REFERENCES giki_wallet.wallets(id),
+
+    api_key_id VARCHAR(64) NOT NULL UNIQUE,
+    api_secret_hash VARCHAR(64) NOT NULL,
+    credentials_rotated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    -- daily settlement reports go here; NULL disables the email
+    settlement_email VARCHAR(255),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+
+    created_by uuid NOT NULL REFERENCES giki_wallet.users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Short-lived payment codes a student presents at the counter. The code itself
+-- is signed; this row only makes it single-use.
+CREATE TABLE giki_wallet.payment_codes (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL REFERENCES giki_wallet.users(id),
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Each payment's id is the reference_id of its MERCHANT_PAYMENT transaction
+CREATE TABLE giki_wallet.merchant_payments (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    merchant_id uuid NOT NULL REFERENCES giki_wallet.merchants(id),
+    user_id uuid NOT NULL REFERENCES giki_wallet.users(id),
+    payment_code_id uuid NOT NULL UNIQUE REFERENCES giki_wallet.payment_codes(id),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+
+    -- the merchant's own order/receipt number, unique per merchant for idempotent retries
+    merchant_reference VARCHAR(64) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_merchant_payment_reference UNIQUE (merchant_id, merchant_reference)
+);
+
+CREATE INDEX IF NOT EXISTS idx_merchant_payments_merchant_created ON giki_wallet.merchant_payments(merchant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_payment_codes_user ON giki_wallet.payment_codes(user_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS giki_wallet.merchant_payments;
+DROP TABLE IF EXISTS giki_wallet.payment_codes;
+DROP TABLE IF EXISTS giki_wallet.merchants;
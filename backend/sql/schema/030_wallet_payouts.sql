@@ -0,0 +1,56 @@
+-- +goose Up
+
+-- Batches of approved payouts exported for finance to send from the
+-- institution's mobile wallet account
+CREATE TABLE giki_wallet.payout_batches (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    payout_count INT NOT NULL DEFAULT 0,
+    total_amount BIGINT NOT NULL DEFAULT 0,
+    created_by uuid NOT NULL REFERENCES giki_wallet.users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_by uuid REFERENCES giki_wallet.users(id),
+    completed_at TIMESTAMPTZ
+);
+
+-- Closure requests: the user asks for their balance to be paid out to a mobile
+-- wallet, finance approves, the balance is debited to the liability wallet and
+-- the wallet is closed.
+CREATE TABLE giki_wallet.payout_requests (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL REFERENCES giki_wallet.users(id),
+    wallet_id uuid NOT NULL REFERENCES giki_wallet.wallets(id),
+    mobile_number VARCHAR(11) NOT NULL,
+    account_title VARCHAR(100) NOT NULL,
+    user_note TEXT,
+
+    -- balance when requested, and the paisa actually paid out on approval
+    requested_balance BIGINT NOT NULL,
+    amount BIGINT,
+
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING'
+        CHECK (status IN ('PENDING', 'APPROVED', 'REJECTED', 'CANCELLED', 'PAID')),
+
+    reviewed_by uuid REFERENCES giki_wallet.users(id),
+    review_note TEXT,
+    transaction_id uuid REFERENCES giki_wallet.transactions(id),
+    batch_id uuid REFERENCES giki_wallet.payout_batches(id),
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    reviewed_at TIMESTAMPTZ,
+    paid_at TIMESTAMPTZ,
+
+    -- finance staff cannot approve their own payout
+    CONSTRAINT chk_payout_four_eyes CHECK (reviewed_by IS NULL OR reviewed_by <> user_id)
+);
+
+-- a user can only have one payout waiting for review
+CREATE UNIQUE INDEX idx_payout_requests_one_pending
+ON giki_wallet.payout_requests (user_id)
+WHERE status = 'PENDING';
+
+CREATE INDEX IF NOT EXISTS idx_payout_requests_status_created ON giki_wallet.payout_requests(status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_payout_requests_batch ON giki_wallet.payout_requests(batch_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS giki_wallet.payout_requests;
+DROP TABLE IF EXISTS giki_wallet.payout_batches;
@@ -0,0 +1,28 @@
+-- +goose Up
+
+-- Authorization holds on a wallet: an ACTIVE reservation lowers what the
+-- wallet can spend without posting to the ledger. It is captured when the
+-- purchase it was made for goes through, or released/expired otherwise.
+CREATE TABLE giki_wallet.wallet_reservations (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    wallet_id uuid NOT NULL REFERENCES giki_wallet.wallets(id),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+
+    -- what the funds are held for, e.g. a seat hold ID
+    reference_id TEXT NOT NULL UNIQUE,
+    description TEXT,
+
+    status VARCHAR(20) NOT NULL DEFAULT 'ACTIVE'
+        CHECK (status IN ('ACTIVE', 'CAPTURED', 'RELEASED', 'EXPIRED')),
+
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_reservations_active
+ON giki_wallet.wallet_reservations (wallet_id, expires_at)
+WHERE status = 'ACTIVE';
+
+-- +goose Down
+DROP TABLE IF EXISTS giki_wallet.wallet_reservations;
@@ -0,0 +1,20 @@
+-- +goose Up
+
+-- Per-user spend caps set by finance, e.g. to slow down a compromised account.
+-- A NULL column falls back to the user type's default from system_configs.
+CREATE TABLE giki_wallet.spend_limit_overrides (
+    user_id uuid PRIMARY KEY REFERENCES giki_wallet.users(id),
+
+    -- paisa
+    per_transaction_limit BIGINT CHECK (per_transaction_limit > 0),
+    daily_limit BIGINT CHECK (daily_limit > 0),
+    weekly_limit BIGINT CHECK (weekly_limit > 0),
+
+    reason TEXT NOT NULL,
+    set_by uuid NOT NULL REFERENCES giki_wallet.users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS giki_wallet.spend_limit_overrides;
@@ -0,0 +1,203 @@
+-- +goose Up
+
+-- The ledger becomes range partitioned by calendar month (UTC) on created_at,
+-- so the date-bounded history, revenue and stats queries only touch the months
+-- they ask about. Partitions are named ledger_YYYY_MM; anything that falls
+-- outside them lands in ledger_default until the maintenance job moves it.
+--
+-- transactions stays a plain table: the ledger, payout requests, adjustments
+-- and reversals all reference transactions(id), and a partitioned table cannot
+-- have a unique key that leaves out the partition column. Ledger ids are
+-- random UUIDs, so the primary key only has to include created_at to satisfy
+-- the same rule.
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION giki_wallet.ensure_ledger_partition(month DATE)
+RETURNS TEXT
+LANGUAGE plpgsql
+AS $$
+DECLARE
+    period_start TIMESTAMPTZ := date_trunc('month', month::timestamp) AT TIME ZONE 'UTC';
+    period_end TIMESTAMPTZ := (date_trunc('month', month::timestamp) + INTERVAL '1 month') AT TIME ZONE 'UTC';
+    partition_name TEXT := 'ledger_' || to_char(month, 'YYYY_MM');
+    lifted BIGINT := 0;
+BEGIN
+    IF to_regclass('giki_wallet.' || partition_name) IS NOT NULL THEN
+        RETURN partition_name;
+    END IF;
+
+    -- a new partition may not overlap rows already in the default partition,
+    -- so lift them out and put them back once the partition exists
+    IF EXISTS (
+        SELECT 1 FROM giki_wallet.ledger_default
+        WHERE created_at >= period_start AND created_at < period_end
+    ) THEN
+        CREATE TEMP TABLE ledger_partition_backfill (LIKE giki_wallet.ledger);
+
+        WITH moved AS (
+            DELETE FROM giki_wallet.ledger_default
+            WHERE created_at >= period_start AND created_at < period_end
+            RETURNING *
+        )
+        INSERT INTO ledger_partition_backfill SELECT * FROM moved;
+
+        GET DIAGNOSTICS lifted = ROW_COUNT;
+    END IF;
+
+    EXECUTE format(
+        'CREATE TABLE giki_wallet.%I PARTITION OF giki_wallet.ledger FOR VALUES FROM (%L) TO (%L)',
+        partition_name, period_start, period_end
+    );
+
+    IF lifted > 0 THEN
+        INSERT INTO giki_wallet.ledger SELECT * FROM ledger_partition_backfill;
+        DROP TABLE ledger_partition_backfill;
+    END IF;
+
+    RETURN partition_name;
+END;
+$$;
+-- +goose StatementEnd
+
+-- Gives every month that has rows in the default partition its own partition
+-- and returns how many months were split out
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION giki_wallet.drain_ledger_default_partition()
+RETURNS INTEGER
+LANGUAGE plpgsql
+AS $$
+DECLARE
+    month DATE;
+    drained INTEGER := 0;
+BEGIN
+    FOR month IN
+        SELECT DISTINCT date_trunc('month', created_at AT TIME ZONE 'UTC')::date
+        FROM giki_wallet.ledger_default
+    LOOP
+        PERFORM giki_wallet.ensure_ledger_partition(month);
+        drained := drained + 1;
+    END LOOP;
+
+    RETURN drained;
+END;
+$$;
+-- +goose StatementEnd
+
+ALTER TABLE giki_wallet.ledger RENAME TO ledger_unpartitioned;
+ALTER TABLE giki_wallet.ledger_unpartitioned RENAME CONSTRAINT ledger_pkey TO ledger_unpartitioned_pkey;
+
+-- same columns in the same order, so SELECT * and RETURNING * are unchanged
+CREATE TABLE giki_wallet.ledger (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+
+    wallet_id uuid NOT NULL,
+
+    amount BIGINT NOT NULL,
+    balance_after BIGINT NOT NULL,
+
+    transaction_id uuid NOT NULL,
+
+    row_hash VARCHAR(255) NOT NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+
+    CONSTRAINT ledger_pkey PRIMARY KEY (id, created_at),
+    CONSTRAINT ledger_amount_check CHECK (amount <> 0),
+    CONSTRAINT ledger_wallet_id_fkey FOREIGN KEY (wallet_id)
+        REFERENCES giki_wallet.wallets(id),
+    CONSTRAINT ledger_transaction_id_fkey FOREIGN KEY (transaction_id)
+        REFERENCES giki_wallet.transactions(id) ON DELETE RESTRICT
+) PARTITION BY RANGE (created_at);
+
+CREATE TABLE giki_wallet.ledger_default PARTITION OF giki_wallet.ledger DEFAULT;
+
+-- one partition per month from the oldest entry up to three months ahead
+SELECT giki_wallet.ensure_ledger_partition(month::date)
+FROM generate_series(
+    date_trunc('month', COALESCE((SELECT MIN(created_at) FROM giki_wallet.ledger_unpartitioned), NOW()) AT TIME ZONE 'UTC'),
+    date_trunc('month', NOW() AT TIME ZONE 'UTC') + INTERVAL '3 months',
+    INTERVAL '1 month'
+) AS month;
+
+INSERT INTO giki_wallet.ledger (id, wallet_id, amount, balance_after, transaction_id, row_hash, created_at)
+SELECT id, wallet_id, amount, balance_after, transaction_id, row_hash, created_at
+FROM giki_wallet.ledger_unpartitioned;
+
+DROP TABLE giki_wallet.ledger_unpartitioned;
+
+-- indexes on the parent are created on every partition, present and future
+CREATE INDEX IF NOT EXISTS idx_ledger_wallet_created_id
+    ON giki_wallet.ledger(wallet_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_ledger_txn_fk
+    ON giki_wallet.ledger(transaction_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_amount_balance
+    ON giki_wallet.ledger(amount, balance_after);
+
+-- Compressed exports of cold partitions. The partitions stay attached, since
+-- balances, hash chains and the trial balance are computed from the first
+-- entry; an archive is a verified copy that can be kept off the database host.
+CREATE TABLE giki_wallet.ledger_archives (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    partition_name TEXT NOT NULL UNIQUE,
+    period_start TIMESTAMPTZ NOT NULL,
+    period_end TIMESTAMPTZ NOT NULL,
+
+    file_name TEXT NOT NULL,
+    row_count BIGINT NOT NULL,
+    amount_sum BIGINT NOT NULL,
+
+    -- SHA-256 of the uncompressed entries and of the compressed file
+    content_sha256 VARCHAR(64) NOT NULL,
+    file_sha256 VARCHAR(64) NOT NULL,
+
+    -- NULL when archived by the maintenance job
+    archived_by uuid REFERENCES giki_wallet.users(id),
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    verified_at TIMESTAMPTZ
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS giki_wallet.ledger_archives;
+
+CREATE TABLE giki_wallet.ledger_unpartitioned (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+
+    wallet_id uuid NOT NULL,
+
+    amount BIGINT NOT NULL,
+    balance_after BIGINT NOT NULL,
+
+    transaction_id uuid NOT NULL,
+
+    row_hash VARCHAR(255) NOT NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+
+    CONSTRAINT ledger_unpartitioned_pkey PRIMARY KEY (id),
+    CONSTRAINT ledger_amount_check CHECK (amount <> 0),
+    CONSTRAINT ledger_wallet_id_fkey FOREIGN KEY (wallet_id)
+        REFERENCES giki_wallet.wallets(id),
+    CONSTRAINT ledger_transaction_id_fkey FOREIGN KEY (transaction_id)
+        REFERENCES giki_wallet.transactions(id) ON DELETE RESTRICT
+);
+
+INSERT INTO giki_wallet.ledger_unpartitioned (id, wallet_id, amount, balance_after, transaction_id, row_hash, created_at)
+SELECT id, wallet_id, amount, balance_after, transaction_id, row_hash, created_at
+FROM giki_wallet.ledger;
+
+DROP TABLE giki_wallet.ledger;
+
+ALTER TABLE giki_wallet.ledger_unpartitioned RENAME TO ledger;
+ALTER TABLE giki_wallet.ledger RENAME CONSTRAINT ledger_unpartitioned_pkey TO ledger_pkey;
+
+CREATE INDEX IF NOT EXISTS idx_ledger_wallet_created_id
+    ON giki_wallet.ledger(wallet_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_ledger_txn_fk
+    ON giki_wallet.ledger(transaction_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_amount_balance
+    ON giki_wallet.ledger(amount, balance_after);
+
+DROP FUNCTION IF EXISTS giki_wallet.drain_ledger_default_partition();
+DROP FUNCTION IF EXISTS giki_wallet.ensure_ledger_partition(DATE);
@@ -0,0 +1,10 @@
+-- +goose Up
+
+-- Which payment gateway a top-up went through. Inquiries, polling and
+-- callbacks are routed back to the same provider, whichever one is currently
+-- selected for new top-ups.
+ALTER TABLE giki_wallet.gateway_transactions
+    ADD COLUMN gateway_provider VARCHAR(20) NOT NULL DEFAULT 'JAZZCASH';
+
+-- +goose Down
+ALTER TABLE giki_wallet.gateway_transactions DROP COLUMN IF EXISTS gateway_provider;
@@ -0,0 +1,15 @@
+-- +goose Up
+
+-- When the current reconciliation round took the is_polling lock, so the
+-- reconciliation sweep can tell a live round from one lost in a crash.
+ALTER TABLE giki_wallet.gateway_transactions
+    ADD COLUMN polling_started_at TIMESTAMPTZ;
+
+-- The reconciliation sweep looks for old transactions that never settled
+CREATE INDEX IF NOT EXISTS idx_gateway_txn_unsettled
+    ON giki_wallet.gateway_transactions(created_at)
+    WHERE status IN ('PENDING', 'UNKNOWN');
+
+-- +goose Down
+DROP INDEX IF EXISTS giki_wallet.idx_gateway_txn_unsettled;
+ALTER TABLE giki_wallet.gateway_transactions DROP COLUMN IF EXISTS polling_started_at;
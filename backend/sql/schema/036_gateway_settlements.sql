@@ -0,0 +1,64 @@
+-- +goose Up
+-- +goose NO TRANSACTION
+
+-- Daily settlement files from the gateways, matched three ways against
+-- gateway_transactions and the deposit entries in the ledger. Every line that
+-- does not match opens a SETTLEMENT_DISCREPANCY entry in payment_audit_log,
+-- which stays unprocessed until finance records a SETTLEMENT_RESOLUTION.
+
+ALTER TYPE giki_wallet.audit_event_type ADD VALUE IF NOT EXISTS 'SETTLEMENT_DISCREPANCY';
+ALTER TYPE giki_wallet.audit_event_type ADD VALUE IF NOT EXISTS 'SETTLEMENT_RESOLUTION';
+
+CREATE TABLE IF NOT EXISTS giki_wallet.gateway_settlements (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    gateway_provider VARCHAR(20) NOT NULL,
+    settlement_date DATE NOT NULL,
+
+    file_name VARCHAR(255) NOT NULL,
+    -- the same file cannot be imported twice
+    file_sha256 VARCHAR(64) NOT NULL,
+
+    row_count INT NOT NULL DEFAULT 0,
+    matched_count INT NOT NULL DEFAULT 0,
+    missing_ours_count INT NOT NULL DEFAULT 0,
+    missing_gateway_count INT NOT NULL DEFAULT 0,
+    mismatch_count INT NOT NULL DEFAULT 0,
+
+    uploaded_by uuid NOT NULL REFERENCES giki_wallet.users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_gateway_settlements_file UNIQUE (gateway_provider, file_sha256)
+);
+
+CREATE TABLE IF NOT EXISTS giki_wallet.gateway_settlement_items (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    settlement_id uuid NOT NULL REFERENCES giki_wallet.gateway_settlements(id) ON DELETE CASCADE,
+    txn_ref_no VARCHAR(100) NOT NULL,
+
+    category VARCHAR(30) NOT NULL
+        CHECK (category IN ('MATCHED', 'MISSING_ON_OUR_SIDE', 'MISSING_AT_GATEWAY', 'AMOUNT_MISMATCH')),
+
+    -- paisa as reported by the gateway, on the gateway transaction, and
+    -- credited to the user in the ledger; NULL where that side has nothing
+    gateway_amount BIGINT,
+    our_amount BIGINT,
+    ledger_amount BIGINT,
+    our_status VARCHAR(20),
+
+    -- the SETTLEMENT_DISCREPANCY entry, for anything not matched
+    audit_id uuid REFERENCES giki_wallet.payment_audit_log(id),
+
+    CONSTRAINT uq_gateway_settlement_items_txn UNIQUE (settlement_id, txn_ref_no)
+);
+
+CREATE INDEX IF NOT EXISTS idx_gateway_settlements_date ON giki_wallet.gateway_settlements(settlement_date DESC, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_gateway_settlement_items_category ON giki_wallet.gateway_settlement_items(settlement_id, category);
+-- what we settled with a gateway on a given day, by settle time
+CREATE INDEX IF NOT EXISTS idx_gateway_txn_settled ON giki_wallet.gateway_transactions(gateway_provider, updated_at)
+    WHERE kind = 'TOPUP' AND status = 'SUCCESS';
+
+-- +goose Down
+DROP INDEX IF EXISTS giki_wallet.idx_gateway_txn_settled;
+DROP TABLE IF EXISTS giki_wallet.gateway_settlement_items;
+DROP TABLE IF EXISTS giki_wallet.gateway_settlements;
+-- enum values cannot be dropped; SETTLEMENT_DISCREPANCY and SETTLEMENT_RESOLUTION stay
@@ -0,0 +1,54 @@
+-- +goose Up
+
+-- A refund goes back out through the gateway the top-up came in on. It is a
+-- gateway transaction of its own (kind REFUND) pointing at the top-up, and
+-- when the gateway confirms it the amount is debited from the user's wallet
+-- back to the liability wallet.
+ALTER TABLE giki_wallet.gateway_transactions
+    ADD COLUMN kind VARCHAR(20) NOT NULL DEFAULT 'TOPUP'
+        CHECK (kind IN ('TOPUP', 'REFUND')),
+    ADD COLUMN refund_of_id uuid REFERENCES giki_wallet.gateway_transactions(id),
+    ADD CONSTRAINT chk_gateway_txn_refund_of CHECK ((kind = 'REFUND') = (refund_of_id IS NOT NULL));
+
+-- Refunds are maker-checker: one finance admin requests, another approves.
+-- The user's funds are held from approval until the gateway answers.
+CREATE TABLE IF NOT EXISTS giki_wallet.gateway_refunds (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    topup_id uuid NOT NULL REFERENCES giki_wallet.gateway_transactions(id),
+    user_id uuid NOT NULL REFERENCES giki_wallet.users(id),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    reason TEXT NOT NULL,
+
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING'
+        CHECK (status IN ('PENDING', 'REJECTED', 'PROCESSING', 'COMPLETED', 'FAILED')),
+
+    requested_by uuid NOT NULL REFERENCES giki_wallet.users(id),
+    reviewed_by uuid REFERENCES giki_wallet.users(id),
+    review_note TEXT,
+
+    -- the REFUND gateway transaction, created on approval, and the ledger
+    -- debit posted once the gateway confirms
+    gateway_txn_id uuid REFERENCES giki_wallet.gateway_transactions(id),
+    transaction_id uuid REFERENCES giki_wallet.transactions(id),
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    reviewed_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+
+    CONSTRAINT chk_gateway_refund_four_eyes CHECK (reviewed_by IS NULL OR reviewed_by <> requested_by)
+);
+
+-- one refund in flight per top-up, so the refundable amount cannot be overdrawn
+CREATE UNIQUE INDEX IF NOT EXISTS idx_gateway_refunds_one_open
+ON giki_wallet.gateway_refunds (topup_id)
+WHERE status IN ('PENDING', 'PROCESSING');
+
+CREATE INDEX IF NOT EXISTS idx_gateway_refunds_status_created ON giki_wallet.gateway_refunds(status, created_at DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_gateway_refunds_gateway_txn ON giki_wallet.gateway_refunds(gateway_txn_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS giki_wallet.gateway_refunds;
+ALTER TABLE giki_wallet.gateway_transactions
+    DROP CONSTRAINT IF EXISTS chk_gateway_txn_refund_of,
+    DROP COLUMN IF EXISTS refund_of_id,
+    DROP COLUMN IF EXISTS kind;
@@ -0,0 +1,51 @@
+-- +goose Up
+-- +goose NO TRANSACTION
+
+-- A gateway transaction the gateway never settled can be marked SUCCESS or
+-- FAILED by hand once support has confirmed the outcome with the bank. One
+-- admin records the evidence and reason, a different admin approves, and the
+-- change is written to payment_audit_log as ADMIN_FORCE_RESOLUTION.
+
+ALTER TYPE giki_wallet.audit_event_type ADD VALUE IF NOT EXISTS 'ADMIN_FORCE_RESOLUTION';
+
+CREATE TABLE IF NOT EXISTS giki_wallet.gateway_force_resolutions (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    gateway_txn_id uuid NOT NULL REFERENCES giki_wallet.gateway_transactions(id),
+
+    -- the transaction's status when the resolution was requested, and the
+    -- status the bank confirmed
+    from_status VARCHAR(20) NOT NULL,
+    target_status VARCHAR(20) NOT NULL CHECK (target_status IN ('SUCCESS', 'FAILED')),
+
+    reason TEXT NOT NULL,
+    -- what was checked with the bank: statement line, call reference, ticket
+    evidence TEXT NOT NULL,
+    -- the bank's reference for the payment, where it gave one
+    bank_reference VARCHAR(100),
+
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING'
+        CHECK (status IN ('PENDING', 'APPROVED', 'REJECTED')),
+
+    requested_by uuid NOT NULL REFERENCES giki_wallet.users(id),
+    reviewed_by uuid REFERENCES giki_wallet.users(id),
+    review_note TEXT,
+
+    -- the ADMIN_FORCE_RESOLUTION entry written on approval
+    audit_id uuid REFERENCES giki_wallet.payment_audit_log(id),
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    reviewed_at TIMESTAMPTZ,
+
+    CONSTRAINT chk_gateway_force_resolution_four_eyes CHECK (reviewed_by IS NULL OR reviewed_by <> requested_by)
+);
+
+-- one resolution awaiting approval per transaction
+CREATE UNIQUE INDEX IF NOT EXISTS idx_gateway_force_resolutions_one_pending
+ON giki_wallet.gateway_force_resolutions (gateway_txn_id)
+WHERE status = 'PENDING';
+
+CREATE INDEX IF NOT EXISTS idx_gateway_force_resolutions_status_created ON giki_wallet.gateway_force_resolutions(status, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS giki_wallet.gateway_force_resolutions;
+-- enum values cannot be dropped; ADMIN_FORCE_RESOLUTION stays
@@ -0,0 +1,35 @@
+-- +goose Up
+
+-- One full verification of the ledger's hash and balance chains. The worker
+-- runs one nightly and whenever a finance admin asks for one; the admin
+-- endpoint reads the stored result instead of walking the ledger itself.
+CREATE TABLE IF NOT EXISTS giki_wallet.ledger_integrity_runs (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    status VARCHAR(20) NOT NULL DEFAULT 'QUEUED'
+        CHECK (status IN ('QUEUED', 'RUNNING', 'COMPLETED', 'FAILED')),
+
+    -- NULL for the nightly run
+    requested_by uuid REFERENCES giki_wallet.users(id),
+
+    wallets_checked INT NOT NULL DEFAULT 0,
+    entries_checked BIGINT NOT NULL DEFAULT 0,
+    transactions_checked BIGINT NOT NULL DEFAULT 0,
+    findings_count INT NOT NULL DEFAULT 0,
+    -- the first findings only, so a badly broken ledger cannot produce an
+    -- unbounded row; findings_count has the full number
+    findings JSONB NOT NULL DEFAULT '[]',
+    healthy BOOLEAN,
+
+    -- why the last attempt failed; the worker retries FAILED runs
+    last_error TEXT,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_integrity_runs_created ON giki_wallet.ledger_integrity_runs(created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS giki_wallet.ledger_integrity_runs;
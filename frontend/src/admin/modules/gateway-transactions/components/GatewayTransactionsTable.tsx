@@ -3,6 +3,7 @@ import { Table } from '../../../shared';
 import { Button } from '@/shared/components/ui/button';
 import { CheckCircle, RotateCw, Eye } from 'lucide-react';
 import { formatInTimeZone } from 'date-fns-tz';
+import { toRupees } from '@/lib/money';
 
 interface GatewayTransactionsTableProps {
     transactions: GatewayTransaction[];
@@ -37,7 +38,7 @@ export const GatewayTransactionsTable = ({
             </div>,
             <span key="method" className="text-sm capitalize">{txn.payment_method}</span>,
             <span key="amount" className="text-sm font-medium">
-                {(parseInt(txn.amount) / 100.0).toLocaleString(undefined, { minimumFractionDigits: 2, maximumFractionDigits: 2 })} PKR
+                {toRupees(txn.amount).toLocaleString(undefined, { minimumFractionDigits: 2, maximumFractionDigits: 2 })} PKR
             </span>,
 
             <div key="status" className="flex flex-col">
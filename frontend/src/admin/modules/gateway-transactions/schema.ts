@@ -1,11 +1,12 @@
 import { z } from 'zod';
+import { moneySchema, type Money } from '@/lib/money';
 
 export const gatewayTransactionSchema = z.object({
     txn_ref_no: z.string(),
     user_id: z.string().uuid(),
     user_name: z.string(),
     user_email: z.string().email(),
-    amount: z.string(), // BigInt returned as string
+    amount: moneySchema,
     status: z.string(),
     payment_method: z.string(),
     created_at: z.string(),
@@ -24,7 +25,7 @@ export type GatewayTransaction = z.infer<typeof gatewayTransactionSchema>;
 export type UpdateGatewayTransactionStatus = z.infer<typeof updateGatewayTransactionStatusSchema>;
 
 export interface GatewayTransactionListParams {
-    page: number;
+    cursor?: string;
     page_size: number;
     start_date?: string;
     end_date?: string;
@@ -48,8 +49,10 @@ export interface PaymentAuditLog {
 
 export interface GatewayTransactionResponse {
     data: GatewayTransaction[];
-    total_count: number;
-    total_amount: string;
-    page: number;
     page_size: number;
+    next_cursor: string;
+    has_more: boolean;
+    // only sent with the first page
+    total_count?: number;
+    total_amount?: Money;
 }
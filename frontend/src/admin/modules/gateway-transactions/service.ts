@@ -1,4 +1,5 @@
 import { apiClient } from '@/lib/axios';
+import type { Money } from '@/lib/money';
 import { GatewayTransaction, GatewayTransactionListParams, GatewayTransactionResponse, PaymentAuditLog } from './schema';
 
 export const GatewayTransactionService = {
@@ -12,17 +13,17 @@ export const GatewayTransactionService = {
         return response.data;
     },
 
-    getLiabilityBalance: async (): Promise<{ balance: number, currency: string }> => {
+    getLiabilityBalance: async (): Promise<{ balance: Money, currency: string }> => {
         const response = await apiClient.get('/admin/finance/liability');
         return response.data;
     },
 
-    getRevenueBalance: async (): Promise<{ balance: number, currency: string }> => {
+    getRevenueBalance: async (): Promise<{ balance: Money, currency: string }> => {
         const response = await apiClient.get('/admin/finance/revenue');
         return response.data;
     },
 
-    getPeriodRevenue: async (params: GatewayTransactionListParams): Promise<{ volume: number, currency: string }> => {
+    getPeriodRevenue: async (params: GatewayTransactionListParams): Promise<{ volume: Money, currency: string }> => {
         const response = await apiClient.get('/admin/finance/revenue/period', {
             params: {
                 start_date: params.start_date,
@@ -4,6 +4,7 @@ import { GatewayTransactionService } from './service';
 import { toast } from '@/lib/toast';
 import { getWeekStart, getWeekEnd } from '../../shared';
 import { apiClient } from '@/lib/axios';
+import { toRupees } from '@/lib/money';
 
 interface GatewayTransactionState {
     transactions: GatewayTransaction[];
@@ -18,6 +19,9 @@ interface GatewayTransactionState {
     // Filters & Pagination
     page: number;
     pageSize: number;
+    // cursors[i] fetches page i + 1; the first page has no cursor
+    cursors: string[];
+    nextCursor: string;
     search: string;
     status: string;
     paymentMethod: string;
@@ -49,6 +53,8 @@ export const useGatewayTransactionStore = create<GatewayTransactionState>((set,
 
     page: 1,
     pageSize: 100,
+    cursors: [''],
+    nextCursor: '',
     search: '',
     status: 'ALL',
     paymentMethod: 'ALL',
@@ -56,33 +62,40 @@ export const useGatewayTransactionStore = create<GatewayTransactionState>((set,
     endDate: getWeekEnd(new Date()),
 
     setPage: (page) => {
-        set({ page });
+        // Pages are walked one at a time along the cursors the list hands back
+        const { cursors, nextCursor } = get();
+        if (page > cursors.length) {
+            if (!nextCursor) return;
+            set({ page: cursors.length + 1, cursors: [...cursors, nextCursor] });
+        } else {
+            set({ page, cursors: cursors.slice(0, page) });
+        }
         get().fetchTransactions();
     },
     setSearch: (search) => {
-        set({ search, page: 1 });
+        set({ search, page: 1, cursors: [''] });
         get().fetchTransactions();
     },
     setStatus: (status) => {
-        set({ status, page: 1 });
+        set({ status, page: 1, cursors: [''] });
         get().fetchTransactions();
     },
     setPaymentMethod: (paymentMethod) => {
-        set({ paymentMethod, page: 1 });
+        set({ paymentMethod, page: 1, cursors: [''] });
         get().fetchTransactions();
     },
     setDateRange: (startDate, endDate) => {
-        set({ startDate, endDate, page: 1 });
+        set({ startDate, endDate, page: 1, cursors: [''] });
         get().fetchTransactions();
         get().fetchFinanceStats();
     },
 
     fetchTransactions: async () => {
         set({ isLoading: true });
-        const { page, pageSize, search, status, paymentMethod, startDate, endDate } = get();
+        const { page, cursors, pageSize, search, status, paymentMethod, startDate, endDate } = get();
         try {
             const data = await GatewayTransactionService.listGatewayTransactions({
-                page,
+                cursor: cursors[page - 1] || undefined,
                 page_size: pageSize,
                 search,
                 status: status === 'ALL' ? undefined : status,
@@ -90,11 +103,10 @@ export const useGatewayTransactionStore = create<GatewayTransactionState>((set,
                 start_date: startDate.toISOString(),
                 end_date: endDate.toISOString(),
             });
-            set({
-                transactions: data.data,
-                totalCount: data.total_count,
-                periodVolume: parseFloat(data.total_amount) / 100.0
-            });
+            set({ transactions: data.data, nextCursor: data.next_cursor });
+            if (data.total_count !== undefined && data.total_amount) {
+                set({ totalCount: data.total_count, periodVolume: toRupees(data.total_amount) });
+            }
         } catch (error) {
             console.error(error);
             toast.error('Failed to fetch gateway transactions');
@@ -128,15 +140,15 @@ export const useGatewayTransactionStore = create<GatewayTransactionState>((set,
                 GatewayTransactionService.getLiabilityBalance(),
                 GatewayTransactionService.getRevenueBalance(),
                 GatewayTransactionService.getPeriodRevenue({
-                    page: 1, page_size: 1,
+                    page_size: 1,
                     start_date: startDate.toISOString(),
                     end_date: endDate.toISOString()
                 })
             ]);
             set({
-                totalLiability: liability.balance,
-                totalRevenue: revenue.balance,
-                periodRevenue: periodRev.volume
+                totalLiability: toRupees(liability.balance),
+                totalRevenue: toRupees(revenue.balance),
+                periodRevenue: toRupees(periodRev.volume)
             });
         } catch (error) {
             console.error(error);
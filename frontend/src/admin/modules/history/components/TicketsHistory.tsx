@@ -4,6 +4,7 @@ import { Input } from '@/shared/components/ui/Input';
 import { Select } from '@/shared/components/ui/Select';
 import { Badge } from './HistoryBadge';
 import { formatDate, formatCurrency, formatTime } from '../utils/formatting';
+import { toRupees } from '@/lib/money';
 import { AdminTicket } from '../../tickets/types';
 import { getAdminTickets } from '../../tickets/service';
 import { useDebounce } from '@/shared/hooks/useDebounce';
@@ -92,7 +93,7 @@ export const TicketsHistory = ({ selectedWeek }: TicketsHistoryProps) => {
             <Badge key="status" type="ticketStatus" value={ticket.status} />,
             <Badge key="bus_type" type="busType" value={ticket.bus_type} />,
             <div key="price" className="text-right">
-                <div className="text-sm font-semibold text-gray-900">{formatCurrency(ticket.price)}</div>
+                <div className="text-sm font-semibold text-gray-900">{formatCurrency(toRupees(ticket.price))}</div>
                 {ticket.refund_amount && (
                     <div className="text-xs text-red-600">Refund: {formatCurrency(ticket.refund_amount)}</div>
                 )}
@@ -30,11 +30,15 @@ export const TransactionsHistory = ({ selectedWeek }: TransactionsHistoryProps)
         const fetch = async () => {
             setIsLoading(true);
             try {
-                // Fetch ALL transactions for the week (high page size)
-                // In production, you would implement proper server-side pagination for the table.
-                const resp = await getTransportTransactions(1, 1000, weekStart, weekEnd);
-                setTransactions(resp.data);
+                // Fetch ALL transactions for the week, following the cursor page by page
+                let resp = await getTransportTransactions('', 1000, weekStart, weekEnd);
+                const all = [...resp.data];
                 setStats(resp.weekly_stats);
+                while (resp.has_more) {
+                    resp = await getTransportTransactions(resp.next_cursor, 1000, weekStart, weekEnd);
+                    all.push(...resp.data);
+                }
+                setTransactions(all);
             } catch (error) {
                 console.error("Failed to fetch transactions", error);
             } finally {
@@ -49,7 +49,7 @@ export const TicketsTable = ({ tickets = [] }: TicketsTableProps) => {
             <Badge key="status" status={ticket.status as any} />,
             <Badge key="bus_type" status={ticket.bus_type as any} />,
             <div key="price" className="text-right">
-                <div className="text-sm font-semibold text-gray-900">{formatCurrency(ticket.price)}</div>
+                <div className="text-sm font-semibold text-gray-900">{formatCurrency(ticket.price.paisa)}</div>
                 {(ticket.status === 'CANCELLED' || ticket.status === 'CANCELLED_BY_ADMIN') && (
                     <div className="text-xs text-red-600">Refunded</div>
                 )}
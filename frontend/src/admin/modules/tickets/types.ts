@@ -1,3 +1,5 @@
+import type { Money } from '@/lib/money';
+
 // Ticket types for admin module
 export interface AdminTicket {
     ticket_id: string;
@@ -16,7 +18,7 @@ export interface AdminTicket {
     passenger_relation: string;
     booking_time: string; // ISO datetime
     status_updated_at?: string; // ISO datetime
-    price: number;
+    price: Money;
     refund_amount?: number;
 }
 
@@ -15,9 +15,12 @@ export const TransactionsPage = () => {
     const { user } = useAuthStore();
     const [transactions, setTransactions] = useState<Transaction[]>([]);
     const [isLoading, setIsLoading] = useState(false);
-    const [page, setPage] = useState(1);
+    // cursors[i] fetches page i + 1; the first page has no cursor
+    const [cursors, setCursors] = useState<string[]>(['']);
+    const [nextCursor, setNextCursor] = useState('');
     const [pageSize] = useState(100);
-    const [totalCount, setTotalCount] = useState(0);
+    const page = cursors.length;
+    const cursor = cursors[cursors.length - 1];
     const [stats, setStats] = useState<WeeklyStats>({ total_income: 0, total_refunds: 0, transaction_count: 0 });
 
     const [currentWeek, setCurrentWeek] = useState(getCurrentWeek());
@@ -36,14 +39,14 @@ export const TransactionsPage = () => {
             setIsLoading(true);
             try {
                 const response = await getTransportTransactions(
-                    page,
+                    cursor,
                     pageSize,
                     weekStart,
                     weekEnd,
                     debouncedSearch
                 );
                 setTransactions(response.data);
-                setTotalCount(response.total_count);
+                setNextCursor(response.next_cursor);
                 if (response.stats) {
                     setStats(response.stats);
                 } else if (response.weekly_stats) {
@@ -56,7 +59,7 @@ export const TransactionsPage = () => {
                 setIsLoading(false);
             }
         }
-    }, [user, page, pageSize, weekStart, weekEnd, debouncedSearch]);
+    }, [user, cursor, pageSize, weekStart, weekEnd, debouncedSearch]);
 
     useEffect(() => {
         loadTransactions();
@@ -64,9 +67,18 @@ export const TransactionsPage = () => {
 
     // Reset page on filter change
     useEffect(() => {
-        setPage(1);
+        setCursors(['']);
     }, [currentWeek, debouncedSearch]);
 
+    // Pages are walked one at a time along the cursors the list hands back
+    const handlePageChange = (newPage: number) => {
+        if (newPage > page) {
+            if (nextCursor) setCursors([...cursors, nextCursor]);
+        } else {
+            setCursors(cursors.slice(0, newPage));
+        }
+    };
+
 
     const handleExport = () => {
         toast.info('Export functionality coming soon');
@@ -139,18 +151,16 @@ export const TransactionsPage = () => {
 
             {/* Transactions Table */}
             <TableWrapper
-                count={totalCount}
+                count={transactions.length}
                 itemName="transaction"
                 isLoading={isLoading}
-                page={page}
-                pageSize={pageSize}
             >
                 <div className="space-y-4">
                     <div className="flex justify-end">
                         <PaginationControl
                             currentPage={page}
-                            totalPages={Math.ceil(totalCount / pageSize)}
-                            onPageChange={setPage}
+                            totalPages={nextCursor ? page + 1 : page}
+                            onPageChange={handlePageChange}
                         />
                     </div>
 
@@ -44,14 +44,15 @@ export interface FinanceResponse extends TransactionsPaginationResponse {
 }
 
 export const getTransportTransactions = async (
-    page = 1,
+    cursor = '',
     pageSize = 20,
     startDate?: Date,
     endDate?: Date,
     search?: string
 ): Promise<FinanceResponse> => {
 
-    const params: any = { page, page_size: pageSize };
+    const params: any = { page_size: pageSize };
+    if (cursor) params.cursor = cursor;
     if (startDate) params.start_date = startDate.toISOString();
     if (endDate) params.end_date = endDate.toISOString();
     if (search) params.search = search;
@@ -60,9 +61,9 @@ export const getTransportTransactions = async (
 
     return {
         data: (data.data || []).map((item: any) => adaptTransaction(item)),
-        total_count: data.meta.total_records,
-        page: data.meta.page,
         page_size: data.meta.page_size,
+        next_cursor: data.meta.next_cursor || '',
+        has_more: data.meta.has_more,
         weekly_stats: data.meta.weekly_stats ? {
             total_income: data.meta.weekly_stats.total_income / 100,
             total_refunds: data.meta.weekly_stats.total_refunds / 100,
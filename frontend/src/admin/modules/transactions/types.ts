@@ -72,9 +72,10 @@ export type Transaction = WalletTransaction | TicketTransaction;
 
 export interface TransactionsPaginationResponse {
     data: Transaction[];
-    total_count: number;
-    page: number;
     page_size: number;
+    // empty on the last page
+    next_cursor: string;
+    has_more: boolean;
     stats?: WeeklyStats;
 }
 
@@ -3,6 +3,7 @@ import { useForm, FormProvider, Controller, SubmitHandler } from 'react-hook-for
 import { zodResolver } from '@hookform/resolvers/zod';
 import { addMinutes, parse, format, differenceInMinutes } from 'date-fns';
 import { fromZonedTime, formatInTimeZone } from 'date-fns-tz';
+import { toRupees } from '@/lib/money';
 import { useTripCreateStore } from '../store';
 import { createTripSchema, CreateTripFormValues } from '../schema';
 import { Button } from '@/shared/components/ui/button';
@@ -109,7 +110,7 @@ export const TripCreateForm = ({ onSuccess }: { onSuccess?: () => void }) => {
         // Pre-fill from existing trip
         setValue('routeId', sourceTrip.route_id);
         setValue('busType', sourceTrip.bus_type as any);
-        setValue('basePrice', sourceTrip.base_price);
+        setValue('basePrice', toRupees(sourceTrip.base_price));
         setValue('totalCapacity', sourceTrip.total_capacity);
         setValue('direction', sourceTrip.direction as any);
 
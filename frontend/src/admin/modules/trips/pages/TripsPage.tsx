@@ -26,6 +26,7 @@ import { Button } from '@/shared/components/ui/button';
 import { WeekSelector } from '@/admin/shared/components/WeekSelector';
 import { useTripCreateStore } from '../store';
 import { cn } from '@/lib/utils';
+import { toRupees } from '@/lib/money';
 import { TripResponse, Route } from '../types';
 import { DeleteTripModal } from '../components/DeleteTripModal';
 import { EditTripModal } from '../components/EditTripModal';
@@ -500,7 +501,7 @@ export const TripsPage = () => {
                                             </td>
                                             <td className="px-6 py-4 text-gray-700 font-bold">
                                                 <span className="text-[10px] text-gray-400 mr-1 font-normal underline decoration-primary/30">G-BUX</span>
-                                                {trip.base_price.toLocaleString()}
+                                                {toRupees(trip.base_price).toLocaleString()}
                                             </td>
                                             <td className="px-6 py-4 text-gray-600 text-xs">
                                                 <div className="flex flex-col gap-1">
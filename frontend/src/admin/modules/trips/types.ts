@@ -1,3 +1,5 @@
+import type { Money } from '@/lib/money';
+
 
 export interface Route {
     route_id: string; // UUID
@@ -59,7 +61,7 @@ export interface TripResponse {
     opens_at: string;
     available_seats: number;
     total_capacity: number;
-    base_price: number; // Changed from price to match backend
+    base_price: Money;
     bus_type: string;
     direction: string;
     manual_status?: string | null;
@@ -1,13 +1,15 @@
+import type { z } from 'zod';
 import { apiClient } from '@/lib/axios';
+import { toRupees } from '@/lib/money';
 import type {
-    Trip,
+    tripSchema,
+    myTicketSchema,
     HoldSeatsRequest,
     HoldSeatsResponse,
     ConfirmBatchRequest,
     ConfirmBatchResponse,
     QuotaResponse,
     ActiveHold,
-    MyTicket
 } from './validators';
 
 
@@ -15,8 +17,8 @@ import type {
  * Get weekly summary of all trips
  */
 export async function getWeeklySummary() {
-    const res = await apiClient.get<Trip[]>('/transport/weekly-summary');
-    return res.data;
+    const res = await apiClient.get<z.input<typeof tripSchema>[]>('/transport/weekly-summary');
+    return res.data.map((trip) => ({ ...trip, base_price: toRupees(trip.base_price) }));
 }
 
 /**
@@ -62,8 +64,8 @@ export async function confirmBatch(payload: ConfirmBatchRequest) {
  * Get user's tickets
  */
 export async function getUserTickets() {
-    const res = await apiClient.get<MyTicket[]>('/transport/tickets');
-    return res.data;
+    const res = await apiClient.get<z.input<typeof myTicketSchema>[]>('/transport/tickets');
+    return res.data.map((ticket) => ({ ...ticket, price: toRupees(ticket.price) }));
 }
 
 /**
@@ -1,4 +1,5 @@
 import { z } from 'zod';
+import { rupeesSchema } from '@/lib/money';
 
 // ============================================================================
 // RESPONSE TYPES 
@@ -22,7 +23,7 @@ export const tripSchema = z.object({
     status: z.string(),
     available_seats: z.number(),
     total_capacity: z.number(),
-    base_price: z.number(),
+    base_price: rupeesSchema,
     stops: z.array(tripStopSchema),
 });
 
@@ -160,7 +161,7 @@ export const myTicketSchema = z.object({
 
     departure_time: z.string(), // ISO date string
     bus_type: z.string(),
-    price: z.number(),
+    price: rupeesSchema,
     is_cancellable: z.boolean(),
 });
 
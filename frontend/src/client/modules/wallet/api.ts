@@ -10,7 +10,8 @@ import { TopUpRequest } from './types';
 
 // Infer types from schemas
 export type BalanceResponse = z.infer<typeof balanceSchema>;
-export type ApiTransaction = z.infer<typeof historyResponseSchema>[number];
+export type HistoryResponse = z.infer<typeof historyResponseSchema>;
+export type ApiTransaction = HistoryResponse['data'][number];
 // Re-export TopUp result type
 export type TopUpResult = z.infer<typeof topUpResultSchema>;
 
@@ -19,8 +20,10 @@ export async function getBalance() {
     return balanceSchema.parse(res.data);
 }
 
-export async function getHistory() {
-    const res = await apiClient.get<ApiTransaction[]>('/wallet/history');
+export async function getHistory(cursor?: string) {
+    const res = await apiClient.get('/wallet/history', {
+        params: { page_size: 100, cursor }
+    });
     return historyResponseSchema.parse(res.data);
 }
 
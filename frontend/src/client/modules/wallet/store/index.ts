@@ -92,8 +92,8 @@ export const useWalletModuleStore = create<WalletModuleStore>((set, get) => ({
     fetchHistory: async () => {
         set({ isDataLoading: true, dataError: null });
         try {
-            const data = await getHistory();
-            set({ transactions: data, isDataLoading: false });
+            const history = await getHistory();
+            set({ transactions: history.data, isDataLoading: false });
         } catch (error: any) {
             set({ dataError: error.message || 'Failed to fetch history', isDataLoading: false });
         }
@@ -1,4 +1,5 @@
 import { z } from 'zod';
+import { rupeesSchema } from '@/lib/money';
 
 export const paymentStatusSchema = z.enum(['PENDING', 'SUCCESS', 'FAILED', 'UNKNOWN']);
 
@@ -27,23 +28,28 @@ export const topUpResultSchema = z.object({
     message: z.string().optional(),
     paymentMethod: paymentMethodSchema,
     redirect: z.string().optional(),
-    amount: z.number().optional()
+    amount: rupeesSchema.optional()
 });
 
 export const balanceSchema = z.object({
-    balance: z.number(),
+    balance: rupeesSchema,
     currency: z.string()
 });
 
 export const transactionSchema = z.object({
     id: z.string(),
-    amount: z.number(),
-    balance_after: z.number(),
+    amount: rupeesSchema,
+    balance_after: rupeesSchema,
     type: z.string(),
     reference_id: z.string(),
     description: z.string(),
     created_at: z.string()
 });
 
-export const historyResponseSchema = z.array(transactionSchema);
+export const historyResponseSchema = z.object({
+    data: z.array(transactionSchema),
+    page_size: z.number(),
+    next_cursor: z.string().optional(),
+    has_more: z.boolean()
+});
 
@@ -0,0 +1,16 @@
+import { z } from 'zod';
+
+// Amounts come from the API as paisa plus a display string,
+// e.g. { paisa: 12345, formatted: "Rs 123.45" }
+export const moneySchema = z.object({
+    paisa: z.number().int(),
+    formatted: z.string(),
+});
+
+export type Money = z.infer<typeof moneySchema>;
+
+// For views that work in rupees; compare and add in paisa where exactness matters
+export const toRupees = (money: Money): number => money.paisa / 100;
+
+// Parses a Money field straight into rupees
+export const rupeesSchema = moneySchema.transform(toRupees);